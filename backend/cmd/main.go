@@ -16,8 +16,10 @@ import (
 	"skeleton-internship-backend/database"
 	_ "skeleton-internship-backend/docs" // This will be created by swag
 	"skeleton-internship-backend/internal/controller"
+	"skeleton-internship-backend/internal/job"
 	"skeleton-internship-backend/internal/logger"
 	"skeleton-internship-backend/internal/repository"
+	"skeleton-internship-backend/internal/scheduler"
 	"skeleton-internship-backend/internal/service"
 )
 
@@ -56,8 +58,11 @@ func main() {
 			NewConfig,
 			database.NewDB,
 			NewGinEngine,
+			job.NewManager,
 			repository.NewRepository,
+			service.NewLabeler,
 			service.NewService,
+			scheduler.NewScheduler,
 			controller.NewController,
 		),
 		fx.Invoke(RegisterRoutes),
@@ -1,6 +1,14 @@
 package config
 
 import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"skeleton-internship-backend/internal/constant"
+
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/viper"
 )
@@ -8,10 +16,112 @@ import (
 type Config struct {
 	Server   ServerConfig
 	Database DatabaseConfig
+	Admin    AdminConfig
+	Rating   RatingConfig
+	Labeler  LabelerConfig
+	Schedule ScheduleConfig
 }
 
 type ServerConfig struct {
 	Port string
+	// Proxies (IPs or CIDRs) whose X-Forwarded-For and X-Real-IP headers are trusted for the
+	// client IP. When empty no proxy is trusted and the client IP is the remote address.
+	TrustedProxies []string
+}
+
+type AdminConfig struct {
+	// API key expected in the Authorization header of admin endpoints,
+	// admin endpoints are disabled when it is empty
+	APIKey string
+}
+
+type RatingConfig struct {
+	// How reviews flagged as suspicious count toward ratings: none, downweight or ignore
+	SuspiciousPolicy string
+	// Weight of a flagged review when the policy is downweight
+	SuspiciousWeight float64
+	// How reviews labeled unknown count: spread (toward every aspect), overall (toward the
+	// overall rating only) or ignore
+	UnknownPolicy string
+	// How label averages become the overall rating: mean, bayesian or decay
+	Strategy string
+	// Number of reviews at the prior rating the bayesian strategy adds to every restaurant
+	PriorWeight float64
+	// Prior of the bayesian strategy: global (every restaurant) or cuisine (same food type)
+	PriorScope string
+	// Days after which a review weighs half as much with the decay strategy
+	DecayHalfLifeDays float64
+	// Weight of each label average in the overall rating, labels left out weigh 1.
+	// Food types can override them in Food_type_aspect_weight.
+	AspectWeights map[string]float64
+}
+
+// AspectWeight returns the configured weight of a label in the overall rating
+func (c RatingConfig) AspectWeight(label string) float64 {
+	if weight, ok := c.AspectWeights[label]; ok {
+		return weight
+	}
+	return 1
+}
+
+// WeightedLabels lists the labels whose averages make up the overall rating: the aspects,
+// and unknown when it counts as an aspect of its own
+func (c RatingConfig) WeightedLabels() []string {
+	labels := append([]string{}, constant.AspectLabels...)
+	if c.UnknownInOverall() {
+		labels = append(labels, constant.LabelUnknown)
+	}
+	return labels
+}
+
+// weighsLabel reports whether the label can weigh in the overall rating
+func (c RatingConfig) weighsLabel(label string) bool {
+	for _, weighted := range c.WeightedLabels() {
+		if label == weighted {
+			return true
+		}
+	}
+	return false
+}
+
+// FlaggedReviewWeight returns the weight a suspicious review gets in rating averages
+func (c RatingConfig) FlaggedReviewWeight() float64 {
+	switch c.SuspiciousPolicy {
+	case "none":
+		return 1
+	case "ignore":
+		return 0
+	}
+	return c.SuspiciousWeight
+}
+
+type LabelerConfig struct {
+	// Labeler used for reviews without labels: lexicon, http or stub
+	Provider string
+	// Endpoint of the external labeling model when the provider is http
+	URL     string
+	Timeout time.Duration
+	// Number of reviews labeled per batch
+	BatchSize int
+}
+
+// SpreadsUnknown reports whether unknown label ratings count toward every aspect
+func (c RatingConfig) SpreadsUnknown() bool {
+	return c.UnknownPolicy == "spread"
+}
+
+// UnknownInOverall reports whether unknown label ratings count as an aspect of their own in the overall rating
+func (c RatingConfig) UnknownInOverall() bool {
+	return c.UnknownPolicy == "overall"
+}
+
+type ScheduleConfig struct {
+	// Cron expressions of the scheduled jobs, a job is not scheduled when empty
+	Recalculate      string
+	RecalculateDirty string
+	Export           string
+	// Scheduled jobs start after a random delay up to Jitter, so replicas do not all hit the database at once
+	Jitter time.Duration
 }
 
 type DatabaseConfig struct {
@@ -38,12 +148,142 @@ func NewConfig() (*Config, error) {
 
 	var config Config
 	config.Server.Port = viper.GetString("SERVER_PORT")
+	trustedProxies, err := parseTrustedProxies(viper.GetString("SERVER_TRUSTED_PROXIES"))
+	if err != nil {
+		return nil, err
+	}
+	config.Server.TrustedProxies = trustedProxies
 	config.Database.Host = viper.GetString("DATABASE_HOST")
 	config.Database.Port = viper.GetString("DATABASE_PORT")
 	config.Database.User = viper.GetString("DATABASE_USER")
 	config.Database.Password = viper.GetString("DATABASE_PASSWORD")
 	config.Database.Name = viper.GetString("DATABASE_NAME")
+	config.Admin.APIKey = viper.GetString("ADMIN_API_KEY")
+
+	viper.SetDefault("RATING_SUSPICIOUS_POLICY", "downweight")
+	viper.SetDefault("RATING_SUSPICIOUS_WEIGHT", 0.2)
+	config.Rating.SuspiciousPolicy = viper.GetString("RATING_SUSPICIOUS_POLICY")
+	config.Rating.SuspiciousWeight = viper.GetFloat64("RATING_SUSPICIOUS_WEIGHT")
+	viper.SetDefault("RATING_UNKNOWN_POLICY", "spread")
+	config.Rating.UnknownPolicy = viper.GetString("RATING_UNKNOWN_POLICY")
+	viper.SetDefault("RATING_STRATEGY", "mean")
+	viper.SetDefault("RATING_PRIOR_WEIGHT", 10)
+	viper.SetDefault("RATING_PRIOR_SCOPE", "cuisine")
+	viper.SetDefault("RATING_DECAY_HALF_LIFE_DAYS", 365)
+	config.Rating.Strategy = viper.GetString("RATING_STRATEGY")
+	config.Rating.PriorWeight = viper.GetFloat64("RATING_PRIOR_WEIGHT")
+	config.Rating.PriorScope = viper.GetString("RATING_PRIOR_SCOPE")
+	config.Rating.DecayHalfLifeDays = viper.GetFloat64("RATING_DECAY_HALF_LIFE_DAYS")
+	aspectWeights, err := parseAspectWeights(viper.GetString("RATING_ASPECT_WEIGHTS"))
+	if err != nil {
+		return nil, err
+	}
+	config.Rating.AspectWeights = aspectWeights
+
+	viper.SetDefault("LABELER_PROVIDER", "lexicon")
+	viper.SetDefault("LABELER_TIMEOUT", "10s")
+	viper.SetDefault("LABELER_BATCH_SIZE", 200)
+	config.Labeler.Provider = viper.GetString("LABELER_PROVIDER")
+	config.Labeler.URL = viper.GetString("LABELER_URL")
+	config.Labeler.Timeout = viper.GetDuration("LABELER_TIMEOUT")
+	config.Labeler.BatchSize = viper.GetInt("LABELER_BATCH_SIZE")
+
+	viper.SetDefault("SCHEDULE_JITTER", "1m")
+	config.Schedule.Recalculate = viper.GetString("SCHEDULE_RECALCULATE")
+	config.Schedule.RecalculateDirty = viper.GetString("SCHEDULE_RECALCULATE_DIRTY")
+	config.Schedule.Export = viper.GetString("SCHEDULE_EXPORT")
+	config.Schedule.Jitter = viper.GetDuration("SCHEDULE_JITTER")
+
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
 
-	log.Info().Interface("config", config).Msg("Config loaded")
+	// Do not log the admin key
+	logged := config
+	logged.Admin.APIKey = ""
+	log.Info().Interface("config", logged).Msg("Config loaded")
 	return &config, nil
 }
+
+// validate rejects settings that would otherwise silently fall back to a default
+func (c *Config) validate() error {
+	switch c.Rating.UnknownPolicy {
+	case "spread", "overall", "ignore":
+	default:
+		return fmt.Errorf("invalid RATING_UNKNOWN_POLICY %q, must be spread, overall or ignore", c.Rating.UnknownPolicy)
+	}
+	switch c.Rating.SuspiciousPolicy {
+	case "none", "downweight", "ignore":
+	default:
+		return fmt.Errorf("invalid RATING_SUSPICIOUS_POLICY %q, must be none, downweight or ignore", c.Rating.SuspiciousPolicy)
+	}
+	if c.Rating.SuspiciousWeight < 0 || c.Rating.SuspiciousWeight > 1 {
+		return fmt.Errorf("invalid RATING_SUSPICIOUS_WEIGHT %v, must be between 0 and 1", c.Rating.SuspiciousWeight)
+	}
+	switch c.Rating.Strategy {
+	case "mean", "bayesian", "decay":
+	default:
+		return fmt.Errorf("invalid RATING_STRATEGY %q, must be mean, bayesian or decay", c.Rating.Strategy)
+	}
+	if c.Rating.PriorWeight < 0 {
+		return fmt.Errorf("invalid RATING_PRIOR_WEIGHT %v, must not be negative", c.Rating.PriorWeight)
+	}
+	if c.Rating.PriorScope != "global" && c.Rating.PriorScope != "cuisine" {
+		return fmt.Errorf("invalid RATING_PRIOR_SCOPE %q, must be global or cuisine", c.Rating.PriorScope)
+	}
+	if c.Rating.DecayHalfLifeDays <= 0 {
+		return fmt.Errorf("invalid RATING_DECAY_HALF_LIFE_DAYS %v, must be positive", c.Rating.DecayHalfLifeDays)
+	}
+	switch c.Labeler.Provider {
+	case "lexicon", "stub":
+	case "http":
+		if c.Labeler.URL == "" {
+			return fmt.Errorf("LABELER_URL is required with the http labeler")
+		}
+	default:
+		return fmt.Errorf("invalid LABELER_PROVIDER %q, must be lexicon, http or stub", c.Labeler.Provider)
+	}
+	if c.Labeler.BatchSize <= 0 {
+		return fmt.Errorf("invalid LABELER_BATCH_SIZE %d, must be positive", c.Labeler.BatchSize)
+	}
+	for label := range c.Rating.AspectWeights {
+		if !c.Rating.weighsLabel(label) {
+			return fmt.Errorf("invalid RATING_ASPECT_WEIGHTS label %q, must be one of %s", label, strings.Join(c.Rating.WeightedLabels(), ", "))
+		}
+	}
+	return nil
+}
+
+// parseTrustedProxies reads IPs or CIDRs separated by commas
+func parseTrustedProxies(value string) ([]string, error) {
+	var proxies []string
+	for _, proxy := range strings.Split(value, ",") {
+		proxy = strings.TrimSpace(proxy)
+		if proxy == "" {
+			continue
+		}
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q in SERVER_TRUSTED_PROXIES", proxy)
+		}
+		proxies = append(proxies, proxy)
+	}
+	return proxies, nil
+}
+
+// parseAspectWeights reads label weights written as label=weight pairs separated by commas,
+// e.g. food=2,delivery=0.5. Malformed or negative weights are rejected.
+func parseAspectWeights(value string) (map[string]float64, error) {
+	weights := map[string]float64{}
+	for _, pair := range strings.Split(value, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		label, weight, found := strings.Cut(pair, "=")
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(weight), 64)
+		if !found || err != nil || parsed < 0 {
+			return nil, fmt.Errorf("invalid RATING_ASPECT_WEIGHTS pair %q, must be label=weight with a weight of at least 0", pair)
+		}
+		weights[strings.ToLower(strings.TrimSpace(label))] = parsed
+	}
+	return weights, nil
+}
@@ -0,0 +1,160 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// validConfig returns the default configuration
+func validConfig() Config {
+	var c Config
+	c.Rating.UnknownPolicy = "spread"
+	c.Rating.SuspiciousPolicy = "downweight"
+	c.Rating.SuspiciousWeight = 0.2
+	c.Rating.Strategy = "mean"
+	c.Rating.PriorWeight = 10
+	c.Rating.PriorScope = "cuisine"
+	c.Rating.DecayHalfLifeDays = 365
+	c.Labeler.Provider = "lexicon"
+	c.Labeler.BatchSize = 200
+	return c
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		change  func(c *Config)
+		wantErr string
+	}{
+		{name: "defaults", change: func(c *Config) {}},
+		{name: "bayesian", change: func(c *Config) { c.Rating.Strategy = "bayesian" }},
+		{name: "decay", change: func(c *Config) { c.Rating.Strategy = "decay" }},
+		{name: "unknown ratings in the overall rating", change: func(c *Config) { c.Rating.UnknownPolicy = "overall" }},
+		{name: "unknown ratings ignored", change: func(c *Config) { c.Rating.UnknownPolicy = "ignore" }},
+		{name: "misspelled unknown policy", change: func(c *Config) { c.Rating.UnknownPolicy = "spead" }, wantErr: "RATING_UNKNOWN_POLICY"},
+		{name: "empty unknown policy", change: func(c *Config) { c.Rating.UnknownPolicy = "" }, wantErr: "RATING_UNKNOWN_POLICY"},
+		{name: "suspicious reviews counted in full", change: func(c *Config) { c.Rating.SuspiciousPolicy = "none" }},
+		{name: "suspicious reviews ignored", change: func(c *Config) { c.Rating.SuspiciousPolicy = "ignore" }},
+		{name: "suspicious reviews without weight", change: func(c *Config) { c.Rating.SuspiciousWeight = 0 }},
+		{name: "suspicious reviews with full weight", change: func(c *Config) { c.Rating.SuspiciousWeight = 1 }},
+		{name: "misspelled suspicious policy", change: func(c *Config) { c.Rating.SuspiciousPolicy = "downweigh" }, wantErr: "RATING_SUSPICIOUS_POLICY"},
+		{name: "empty suspicious policy", change: func(c *Config) { c.Rating.SuspiciousPolicy = "" }, wantErr: "RATING_SUSPICIOUS_POLICY"},
+		{name: "negative suspicious weight", change: func(c *Config) { c.Rating.SuspiciousWeight = -0.5 }, wantErr: "RATING_SUSPICIOUS_WEIGHT"},
+		{name: "suspicious weight above one", change: func(c *Config) { c.Rating.SuspiciousWeight = 2 }, wantErr: "RATING_SUSPICIOUS_WEIGHT"},
+		{name: "stub labeler", change: func(c *Config) { c.Labeler.Provider = "stub" }},
+		{name: "http labeler", change: func(c *Config) {
+			c.Labeler.Provider = "http"
+			c.Labeler.URL = "http://labeler:8000/label"
+		}},
+		{name: "http labeler without URL", change: func(c *Config) { c.Labeler.Provider = "http" }, wantErr: "LABELER_URL"},
+		{name: "unknown labeler", change: func(c *Config) { c.Labeler.Provider = "openai" }, wantErr: "LABELER_PROVIDER"},
+		{name: "empty labeler", change: func(c *Config) { c.Labeler.Provider = "" }, wantErr: "LABELER_PROVIDER"},
+		{name: "zero batch size", change: func(c *Config) { c.Labeler.BatchSize = 0 }, wantErr: "LABELER_BATCH_SIZE"},
+		{name: "negative batch size", change: func(c *Config) { c.Labeler.BatchSize = -1 }, wantErr: "LABELER_BATCH_SIZE"},
+		{name: "aspect weights", change: func(c *Config) { c.Rating.AspectWeights = map[string]float64{"food": 2, "delivery": 0} }},
+		{name: "unknown weight when averaged on its own", change: func(c *Config) {
+			c.Rating.UnknownPolicy = "overall"
+			c.Rating.AspectWeights = map[string]float64{"unknown": 0.5}
+		}},
+		{name: "unknown weight when spread", change: func(c *Config) { c.Rating.AspectWeights = map[string]float64{"unknown": 0.5} }, wantErr: "RATING_ASPECT_WEIGHTS"},
+		{name: "misspelled aspect", change: func(c *Config) { c.Rating.AspectWeights = map[string]float64{"fod": 2} }, wantErr: "RATING_ASPECT_WEIGHTS"},
+		{name: "no prior weight", change: func(c *Config) { c.Rating.PriorWeight = 0 }},
+		{name: "unknown strategy", change: func(c *Config) { c.Rating.Strategy = "median" }, wantErr: "RATING_STRATEGY"},
+		{name: "empty strategy", change: func(c *Config) { c.Rating.Strategy = "" }, wantErr: "RATING_STRATEGY"},
+		{name: "negative prior weight", change: func(c *Config) { c.Rating.PriorWeight = -1 }, wantErr: "RATING_PRIOR_WEIGHT"},
+		{name: "unknown prior scope", change: func(c *Config) { c.Rating.PriorScope = "city" }, wantErr: "RATING_PRIOR_SCOPE"},
+		{name: "zero half-life", change: func(c *Config) { c.Rating.DecayHalfLifeDays = 0 }, wantErr: "RATING_DECAY_HALF_LIFE_DAYS"},
+		{name: "negative half-life", change: func(c *Config) { c.Rating.DecayHalfLifeDays = -30 }, wantErr: "RATING_DECAY_HALF_LIFE_DAYS"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := validConfig()
+			tt.change(&c)
+			err := c.validate()
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("validate() error = %v, want none", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("validate() error = %v, want one about %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	proxies, err := parseTrustedProxies(" 10.0.0.0/8, 192.168.1.1 ,,")
+	if err != nil || len(proxies) != 2 || proxies[0] != "10.0.0.0/8" || proxies[1] != "192.168.1.1" {
+		t.Errorf("parseTrustedProxies() = %v, %v, want [10.0.0.0/8 192.168.1.1]", proxies, err)
+	}
+	if _, err := parseTrustedProxies("10.0.0.0/8,proxy.local"); err == nil {
+		t.Error("parseTrustedProxies() accepted a host name")
+	}
+}
+
+func TestUnknownPolicy(t *testing.T) {
+	tests := []struct {
+		policy           string
+		spreads, overall bool
+	}{
+		{policy: "spread", spreads: true},
+		{policy: "overall", overall: true},
+		{policy: "ignore"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			c := RatingConfig{UnknownPolicy: tt.policy}
+			if c.SpreadsUnknown() != tt.spreads || c.UnknownInOverall() != tt.overall {
+				t.Errorf("SpreadsUnknown() = %v, UnknownInOverall() = %v, want %v, %v",
+					c.SpreadsUnknown(), c.UnknownInOverall(), tt.spreads, tt.overall)
+			}
+		})
+	}
+}
+
+func TestFlaggedReviewWeight(t *testing.T) {
+	tests := []struct {
+		policy string
+		want   float64
+	}{
+		{policy: "none", want: 1},
+		{policy: "downweight", want: 0.2},
+		{policy: "ignore", want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			c := RatingConfig{SuspiciousPolicy: tt.policy, SuspiciousWeight: 0.2}
+			if got := c.FlaggedReviewWeight(); got != tt.want {
+				t.Errorf("FlaggedReviewWeight() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseAspectWeights(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    map[string]float64
+		wantErr bool
+	}{
+		{name: "empty", value: "", want: map[string]float64{}},
+		{name: "pairs", value: " Food=2, delivery = 0.5,,service=0", want: map[string]float64{"food": 2, "delivery": 0.5, "service": 0}},
+		{name: "missing weight", value: "food", wantErr: true},
+		{name: "not a number", value: "food=high", wantErr: true},
+		{name: "negative weight", value: "food=2,delivery=-1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseAspectWeights(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseAspectWeights(%q) error = %v, want error %v", tt.value, err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseAspectWeights(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
@@ -24,9 +24,14 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/api/v1/export": {
-            "post": {
-                "description": "Exports restaurant ratings and review counts to a CSV file",
+        "/api/v1/admin/duplicates": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "get the clusters found by the last duplicate detection, largest first. Each cluster holds the kept review and its duplicates.",
                 "consumes": [
                     "application/json"
                 ],
@@ -34,12 +39,51 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "restaurants"
+                    "admin"
+                ],
+                "summary": "Get duplicate review clusters",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Restaurant ID",
+                        "name": "restaurant_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Export restaurant ratings to CSV",
                 "responses": {
-                    "202": {
-                        "description": "Accepted",
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.DuplicateReport"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
@@ -53,9 +97,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/foodtypes": {
-            "get": {
-                "description": "get all food types",
+        "/api/v1/admin/duplicates/detect": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Fingerprints review texts to mark exact and near duplicates, and reviews posted by the same user on the same restaurant and day.\nDuplicates are excluded from review counts and ratings, which are recalculated once detection is done.",
                 "consumes": [
                     "application/json"
                 ],
@@ -63,12 +112,12 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "foodtypes"
+                    "admin"
                 ],
-                "summary": "Get all food types",
+                "summary": "Detect duplicate reviews",
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "202": {
+                        "description": "Accepted",
                         "schema": {
                             "allOf": [
                                 {
@@ -78,18 +127,15 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "type": "string"
-                                            }
+                                            "$ref": "#/definitions/model.Job"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
@@ -97,9 +143,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/recalculate": {
+        "/api/v1/admin/labels/run": {
             "post": {
-                "description": "Recalculates ratings and review counts for all restaurants based on reviews and feedback labels",
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Runs the configured labeler on the reviews without labels, in batches, so they count toward label ratings.\nReviews no aspect is found in are labeled unknown with their own rating. Ratings are recalculated once labeling is done.",
                 "consumes": [
                     "application/json"
                 ],
@@ -107,18 +158,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "restaurants"
+                    "admin"
                 ],
-                "summary": "Recalculate restaurant ratings",
+                "summary": "Label unlabeled reviews",
                 "responses": {
                     "202": {
                         "description": "Accepted",
                         "schema": {
-                            "$ref": "#/definitions/model.Response"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Job"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
@@ -126,9 +189,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/restaurants": {
+        "/api/v1/admin/moderation": {
             "get": {
-                "description": "get restaurants with various filter options including location, food type, city, district, etc. Accepct limit or page (if page is specified, limit will be ignored)\nIf lat and lng are provided, it will return nearby restaurants sorted by distance.\nIf lat and lng are not provided, it will sort by rating only.\nIf count is true, it will return the total count of restaurants matching the filter criteria.\nIf neither page nor limit is specified, it will return the first 30 restaurants.\nMultiple districts can be provided as comma-separated values.",
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "get reviews by moderation status, most reported first, with their unresolved reports per reason. Flagged reviews are returned by default.",
                 "consumes": [
                     "application/json"
                 ],
@@ -136,58 +204,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "restaurants"
+                    "admin"
                 ],
-                "summary": "Get restaurants by filter",
+                "summary": "Get the review moderation queue",
                 "parameters": [
-                    {
-                        "type": "number",
-                        "description": "Latitude",
-                        "name": "lat",
-                        "in": "query"
-                    },
-                    {
-                        "type": "number",
-                        "description": "Longitude",
-                        "name": "lng",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Food type",
-                        "name": "foodtype",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "City ID",
-                        "name": "city",
-                        "in": "query"
-                    },
                     {
                         "type": "string",
-                        "description": "District IDs (comma-separated)",
-                        "name": "district",
+                        "default": "flagged",
+                        "description": "Moderation status (flagged, hidden, visible)",
+                        "name": "status",
                         "in": "query"
                     },
                     {
                         "type": "integer",
+                        "default": 1,
                         "description": "Page number",
                         "name": "page",
                         "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Limit results",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "type": "boolean",
-                        "default": false,
-                        "description": "Return total count",
-                        "name": "count",
-                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -202,10 +235,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/model.Restaurant"
-                                            }
+                                            "$ref": "#/definitions/model.ModerationQueue"
                                         }
                                     }
                                 }
@@ -218,6 +248,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -227,9 +263,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/restaurants/search": {
-            "get": {
-                "description": "get restaurant name suggestions based on search query",
+        "/api/v1/admin/moderation/{id}/approve": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "make a reported or hidden review visible again and resolve its reports. Review counts and ratings are refreshed when a hidden review comes back.",
                 "consumes": [
                     "application/json"
                 ],
@@ -237,45 +278,35 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "restaurants"
+                    "admin"
                 ],
-                "summary": "Get autocomplete suggestions for restaurants",
+                "summary": "Approve a review",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Search query",
-                        "name": "query",
-                        "in": "query",
+                        "description": "Review (rating) ID",
+                        "name": "id",
+                        "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "default": 10,
-                        "description": "Limit results",
-                        "name": "limit",
-                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/model.Response"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/model.Restaurant"
-                                            }
-                                        }
-                                    }
-                                }
-                            ]
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
                         }
                     },
                     "500": {
@@ -287,9 +318,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/restaurants/{id}": {
-            "get": {
-                "description": "get restaurant by ID",
+        "/api/v1/admin/moderation/{id}/hide": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "hide a review from listings, review counts and ratings and resolve its reports. The restaurant's review count and rating are refreshed right away.",
                 "consumes": [
                     "application/json"
                 ],
@@ -297,51 +333,27 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "restaurants"
+                    "admin"
                 ],
-                "summary": "Get a restaurant",
+                "summary": "Hide a review",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Restaurant ID",
+                        "description": "Review (rating) ID",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "number",
-                        "description": "Latitude",
-                        "name": "lat",
-                        "in": "query"
-                    },
-                    {
-                        "type": "number",
-                        "description": "Longitude",
-                        "name": "lng",
-                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/model.Response"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "$ref": "#/definitions/model.RestaurantDetail"
-                                        }
-                                    }
-                                }
-                            ]
+                            "$ref": "#/definitions/model.Response"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
@@ -351,13 +363,24 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
                     }
                 }
             }
         },
-        "/api/v1/restaurants/{id}/menu": {
-            "get": {
-                "description": "get restaurant menu by ID",
+        "/api/v1/admin/recalculate": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Starts a job recalculating ratings and review counts for all restaurants based on reviews and feedback labels.\nIf a recalculation is already running, that job is returned instead of starting another one.\nThe job takes the replica lock of the jobs writing ratings and fails when another job holds it.",
                 "consumes": [
                     "application/json"
                 ],
@@ -365,21 +388,12 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "restaurants"
-                ],
-                "summary": "Get restaurant menu",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Restaurant ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
+                    "admin"
                 ],
+                "summary": "Recalculate restaurant ratings",
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "202": {
+                        "description": "Accepted",
                         "schema": {
                             "allOf": [
                                 {
@@ -389,16 +403,71 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/model.Dish"
-                                            }
+                                            "$ref": "#/definitions/model.Job"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/recalculate/dry-run": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "get what applying the staged recalculation would change: restaurants changed, largest rating movers and rank changes in each district",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get the dry-run summary",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.DryRunSummary"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -412,11 +481,161 @@ const docTemplate = `{
                         }
                     }
                 }
+            },
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Starts a job computing new ratings and review counts of all restaurants into a staging table, replacing the previous dry run. Restaurants are not changed until the dry run is applied.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Dry-run a recalculation",
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Job"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
             }
         },
-        "/api/v1/restaurants/{id}/reviews": {
+        "/api/v1/admin/recalculate/dry-run/apply": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Starts a job swapping the staged ratings, review counts and label stats in, in a single transaction, and clearing the staging table,\nthen refreshing the rankings, platform calibration and keywords like a full recalculation.\nRestaurants that changed since the dry run keep their values and are left to the next incremental recalculation.\nThe job result holds the applied and skipped restaurants (model.DryRunApplyResult). It takes the replica lock of the jobs writing ratings and fails when nothing is staged.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Apply the dry run",
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Job"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/recalculate/dry-run/diff": {
             "get": {
-                "description": "get restaurant reviews by ID and label",
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "download, as CSV, every restaurant whose rating, review count or district rank would change with the staged recalculation",
+                "produces": [
+                    "text/csv"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Download the dry-run diff",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/restaurants/{id}/recalculate": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Recalculates the review count, label ratings, rating and keywords of one restaurant right away and returns the updated restaurant",
                 "consumes": [
                     "application/json"
                 ],
@@ -424,9 +643,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "restaurants"
+                    "admin"
                 ],
-                "summary": "Get restaurant reviews by label",
+                "summary": "Recalculate one restaurant",
                 "parameters": [
                     {
                         "type": "string",
@@ -434,35 +653,6 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Label type (ambience, delivery, food, price, service)",
-                        "name": "label",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "default": 1,
-                        "description": "Page number",
-                        "name": "page",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "boolean",
-                        "default": true,
-                        "description": "Whether to count total reviews",
-                        "name": "count",
-                        "in": "query"
-                    },
-                    {
-                        "type": "boolean",
-                        "default": false,
-                        "description": "If textonly = true, we get text only (ignore null reviews)",
-                        "name": "textonly",
-                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -477,15 +667,15 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/model.ReviewResponse"
+                                            "$ref": "#/definitions/model.Restaurant"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
@@ -495,77 +685,2472 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
                     }
                 }
             }
         },
-        "/health": {
+        "/api/v1/admin/suspicious": {
             "get": {
-                "description": "get the status of server.",
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "get the review bursts found by the last detection, largest first, with their flagged reviews",
                 "consumes": [
-                    "*/*"
+                    "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "health"
+                    "admin"
+                ],
+                "summary": "Get suspicious review windows",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Restaurant ID",
+                        "name": "restaurant_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Show the status of server.",
                 "responses": {
                     "200": {
                         "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.SuspiciousReport"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
+        },
+        "/api/v1/admin/suspicious/detect": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Looks for days where a restaurant received far more reviews than usual with an average far from its usual rating, and flags the reviews driving the burst.\nFlagged reviews are down-weighted or ignored in ratings depending on the configuration, and ratings are recalculated once detection is done.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Detect suspicious reviews",
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Job"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/export": {
+            "post": {
+                "description": "Starts a job exporting restaurant ratings and review counts to a CSV file.\nIf an export is already running, that job is returned instead of starting another one.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "restaurants"
+                ],
+                "summary": "Export restaurant ratings to CSV",
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Job"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/foodtypes": {
+            "get": {
+                "description": "get all food types",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "foodtypes"
+                ],
+                "summary": "Get all food types",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "type": "string"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/jobs/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "get the status and timestamps of a recalculation, export, detection or labeling job. Finished jobs are kept for 24 hours.\nThe progress, error and result are only returned with the admin API key.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Get a background job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Job"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/rankings": {
+            "get": {
+                "description": "get the top restaurants of a city, a district or the whole catalog, optionally of one food type,\nby overall rating or by a label rating. Rankings are rebuilt by every recalculation, and each\nrestaurant comes with its rank movement since the previous one. When a district is given the city is ignored.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rankings"
+                ],
+                "summary": "Get restaurant rankings",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "City ID",
+                        "name": "city",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "District ID",
+                        "name": "district",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Food type name",
+                        "name": "foodtype",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "overall",
+                        "description": "Rating to rank by: overall, ambience, delivery, food, price, service",
+                        "name": "label",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Number of restaurants (max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Ranking"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/restaurants": {
+            "get": {
+                "description": "get restaurants with various filter options including location, food type, city, district, etc. Accepct limit or page (if page is specified, limit will be ignored)\nIf lat and lng are provided, it will return nearby restaurants sorted by distance.\nIf lat and lng are not provided, it will sort by rating only.\nIf count is true, it will return the total count of restaurants matching the filter criteria.\nIf neither page nor limit is specified, it will return the first 30 restaurants.\nMultiple districts can be provided as comma-separated values.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "restaurants"
+                ],
+                "summary": "Get restaurants by filter",
+                "parameters": [
+                    {
+                        "type": "number",
+                        "description": "Latitude",
+                        "name": "lat",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Longitude",
+                        "name": "lng",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Food type",
+                        "name": "foodtype",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "City ID",
+                        "name": "city",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "District IDs (comma-separated)",
+                        "name": "district",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit results",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Return total count",
+                        "name": "count",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.Restaurant"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/restaurants/movers": {
+            "get": {
+                "description": "get the restaurants whose rating changed the most between the snapshots of two recalculation versions.\nBy default the latest recalculation is compared with the one before it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "restaurants"
+                ],
+                "summary": "Get the biggest rating movers",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Recalculation version to compare from",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Recalculation version to compare to",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Number of restaurants (max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.RatingMovers"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/restaurants/search": {
+            "get": {
+                "description": "get restaurant name suggestions based on search query",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "restaurants"
+                ],
+                "summary": "Get autocomplete suggestions for restaurants",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search query",
+                        "name": "query",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Limit results",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.Restaurant"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/restaurants/{id}": {
+            "get": {
+                "description": "get restaurant by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "restaurants"
+                ],
+                "summary": "Get a restaurant",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Restaurant ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "Latitude",
+                        "name": "lat",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Longitude",
+                        "name": "lng",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Break label ratings down by review platform (platform)",
+                        "name": "breakdown",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.RestaurantDetail"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/restaurants/{id}/history": {
+            "get": {
+                "description": "get the rating, review count and label ratings of a restaurant after each full recalculation, newest first,\nwith the rating settings they were computed with. Single-restaurant, incremental and moderation\nrecalculations are not snapshotted, so the current rating may differ from the latest snapshot.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "restaurants"
+                ],
+                "summary": "Get restaurant rating history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Restaurant ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.RatingHistory"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/restaurants/{id}/keywords": {
+            "get": {
+                "description": "get the phrases most used by reviewers for each label, split by positive and negative label ratings. Keywords are refreshed on recalculation.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "restaurants"
+                ],
+                "summary": "Get restaurant keywords",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Restaurant ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.RestaurantKeywords"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/restaurants/{id}/labels": {
+            "get": {
+                "description": "get the rating and review count of each label. With breakdown=platform, the label ratings are also computed from the reviews of each platform separately, with their review counts, to show where each score comes from.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "restaurants"
+                ],
+                "summary": "Get restaurant label ratings",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Restaurant ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Break label ratings down by review platform (platform)",
+                        "name": "breakdown",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.RestaurantLabels"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/restaurants/{id}/menu": {
+            "get": {
+                "description": "get restaurant menu by ID, grouped by category in menu order with the price range of each category\nIf q is provided, only dishes whose name or category contains every word of q are returned.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "restaurants"
+                ],
+                "summary": "Get restaurant menu",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Restaurant ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Search within the menu",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort dishes inside each category (price_asc, price_desc, name)",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Menu"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/restaurants/{id}/ratings/distribution": {
+            "get": {
+                "description": "get the number of reviews per star bucket overall, per label and per platform, with the mean and standard deviation of each",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "restaurants"
+                ],
+                "summary": "Get restaurant rating distribution",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Restaurant ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.RatingDistribution"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/restaurants/{id}/ratings/trend": {
+            "get": {
+                "description": "get the review volume and average rating, overall and per label, for each month or week",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "restaurants"
+                ],
+                "summary": "Get restaurant rating trend",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Restaurant ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "month",
+                        "description": "Bucket size (month, week)",
+                        "name": "granularity",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.RatingTrend"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/restaurants/{id}/reviews": {
+            "get": {
+                "description": "get restaurant reviews by ID, each review once with all of its labels\nIf label is provided, only reviews rating that label (or unknown) are returned.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "restaurants"
+                ],
+                "summary": "Get restaurant reviews",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Restaurant ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Label type (ambience, delivery, food, price, service)",
+                        "name": "label",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "newest",
+                        "description": "Sort order (newest, oldest, highest, lowest, helpful)",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Minimum overall rating",
+                        "name": "min_rating",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Maximum overall rating",
+                        "name": "max_rating",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Platform name (e.g. Foody, BeFood)",
+                        "name": "platform",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "default": true,
+                        "description": "Whether to count total reviews",
+                        "name": "count",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "If textonly = true, we get text only (ignore null reviews)",
+                        "name": "textonly",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.ReviewResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "create a review for a restaurant together with its aspect ratings\nA user can only review a restaurant once. The restaurant review count and rating are updated immediately.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reviews"
+                ],
+                "summary": "Create a review",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Restaurant ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Review to create",
+                        "name": "review",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ReviewCreate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Review"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/reviews/{id}/report": {
+            "post": {
+                "description": "report a review as spam, offensive, fake or off topic. Reported reviews are queued for moderation and stay visible until a moderator hides them.\nEach client can report a review once. Clients are identified by their IP address.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reviews"
+                ],
+                "summary": "Report a review",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Review (rating) ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Report",
+                        "name": "report",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ReviewReport"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/reviews/{id}/vote": {
+            "post": {
+                "description": "mark a review as helpful or unhelpful. Each client has one vote per review, voting again replaces it.\nClients are identified by their IP address. Hidden reviews cannot be voted on.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reviews"
+                ],
+                "summary": "Vote on a review",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Review (rating) ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Vote",
+                        "name": "vote",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ReviewVote"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.ReviewVotes"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "remove the helpful or unhelpful vote of the current client on a review",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reviews"
+                ],
+                "summary": "Remove a vote on a review",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Review (rating) ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.ReviewVotes"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/schedules": {
+            "get": {
+                "description": "get the cron schedules of the recalculation and export jobs, their next run and the outcome of their last run on this instance",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Get scheduled jobs",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.ScheduleStatus"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/{id}": {
+            "get": {
+                "description": "get a user with their platform, review count, average rating given and label breakdown",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get a reviewer profile",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.UserProfile"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/{id}/reviews": {
+            "get": {
+                "description": "get the reviews written by a user, newest first, with a summary of each reviewed restaurant",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get reviews of a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": true,
+                        "description": "Whether to count total reviews",
+                        "name": "count",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.UserReviewResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/health": {
+            "get": {
+                "description": "get the status of server.",
+                "consumes": [
+                    "*/*"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Show the status of server.",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "dto.ReviewCreate": {
+            "description": "Review creation request body",
+            "type": "object",
+            "required": [
+                "rating",
+                "user_id"
+            ],
+            "properties": {
+                "feedback": {
+                    "description": "Text of the review",
+                    "type": "string",
+                    "example": "Nước dùng đậm đà, phục vụ nhanh"
+                },
+                "labels": {
+                    "description": "Ratings given to individual aspects of the restaurant",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.ReviewLabelCreate"
+                    }
+                },
+                "rating": {
+                    "description": "Overall rating of the review (1 to 5)",
+                    "type": "number",
+                    "example": 4.5
+                },
+                "user_id": {
+                    "description": "ID of the user writing the review",
+                    "type": "string",
+                    "example": "15"
+                }
+            }
+        },
+        "dto.ReviewLabelCreate": {
+            "type": "object",
+            "required": [
+                "label",
+                "rating_label"
+            ],
+            "properties": {
+                "label": {
+                    "description": "Aspect of the restaurant (ambience, delivery, food, price, service, unknown)",
+                    "type": "string",
+                    "example": "food"
+                },
+                "rating_label": {
+                    "description": "Rating given to the aspect (1 to 5)",
+                    "type": "number",
+                    "example": 4.5
+                }
+            }
+        },
+        "dto.ReviewReport": {
+            "description": "Review report request body",
+            "type": "object",
+            "required": [
+                "reason"
+            ],
+            "properties": {
+                "details": {
+                    "description": "Optional explanation of the report",
+                    "type": "string",
+                    "example": "Contains insults toward the staff"
+                },
+                "reason": {
+                    "description": "Reason of the report (spam, offensive, fake, off_topic, other)",
+                    "type": "string",
+                    "example": "offensive"
+                }
+            }
+        },
+        "dto.ReviewVote": {
+            "description": "Review vote request body",
+            "type": "object",
+            "required": [
+                "helpful"
+            ],
+            "properties": {
+                "helpful": {
+                    "description": "Whether the review was helpful",
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
         "model.Dish": {
             "description": "This struct is used to represent a dish in the system",
             "type": "object",
             "properties": {
-                "name": {
-                    "description": "Name of the dish",
+                "category_id": {
+                    "description": "Category the dish belongs to",
+                    "type": "integer"
+                },
+                "category_name": {
+                    "description": "Name of the category the dish belongs to",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "Name of the dish",
+                    "type": "string"
+                },
+                "price": {
+                    "description": "Price of the dish",
+                    "type": "number"
+                }
+            }
+        },
+        "model.DistrictRankChanges": {
+            "type": "object",
+            "properties": {
+                "changed_count": {
+                    "description": "Number of restaurants of the district whose rank would change",
+                    "type": "integer"
+                },
+                "district_id": {
+                    "type": "string"
+                },
+                "restaurants": {
+                    "description": "Restaurants moving the most places, up to 5",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.StagedRating"
+                    }
+                }
+            }
+        },
+        "model.DryRunSummary": {
+            "description": "Restaurants changed, largest rating movers and rank changes per district of the staged recalculation",
+            "type": "object",
+            "properties": {
+                "changed_count": {
+                    "description": "Number of restaurants whose rating or review count would change",
+                    "type": "integer"
+                },
+                "districts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.DistrictRankChanges"
+                    }
+                },
+                "largest_movers": {
+                    "description": "Restaurants whose rating would move the most, up to 20",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.StagedRating"
+                    }
+                },
+                "restaurant_count": {
+                    "type": "integer"
+                },
+                "staged_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.DuplicateCluster": {
+            "description": "A group of duplicate reviews, only the canonical review counts toward ratings",
+            "type": "object",
+            "properties": {
+                "canonical": {
+                    "description": "Earliest review of the cluster, which is kept",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.Review"
+                        }
+                    ]
+                },
+                "duplicates": {
+                    "description": "Reviews excluded from the restaurant rating",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.DuplicateReview"
+                    }
+                },
+                "restaurant_id": {
+                    "description": "Restaurant the reviews belong to",
+                    "type": "string"
+                }
+            }
+        },
+        "model.DuplicateReport": {
+            "type": "object",
+            "properties": {
+                "clusters": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.DuplicateCluster"
+                    }
+                },
+                "total_clusters": {
+                    "type": "integer"
+                },
+                "total_duplicates": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.DuplicateReview": {
+            "type": "object",
+            "properties": {
+                "feedback": {
+                    "type": "string"
+                },
+                "helpful_count": {
+                    "description": "Number of readers who found the review helpful",
+                    "type": "integer"
+                },
+                "label": {
+                    "type": "string"
+                },
+                "labels": {
+                    "description": "All labels attached to the review",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ReviewLabel"
+                    }
+                },
+                "platform": {
+                    "description": "Platform the review was collected from",
+                    "type": "string"
+                },
+                "rating": {
+                    "type": "number"
+                },
+                "rating_id": {
+                    "type": "string"
+                },
+                "rating_label": {
+                    "type": "number"
+                },
+                "reason": {
+                    "description": "Why the review was marked (same_user_day, exact, near)",
+                    "type": "string"
+                },
+                "review_time": {
+                    "type": "string"
+                },
+                "similarity": {
+                    "description": "Jaccard similarity with the kept review",
+                    "type": "number"
+                },
+                "unhelpful_count": {
+                    "description": "Number of readers who found the review unhelpful",
+                    "type": "integer"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.Job": {
+            "description": "Status of a background job such as a recalculation or an export",
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "description": "Error message when the job failed",
+                    "type": "string"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "progress": {
+                    "description": "Progress, error and result are only returned to admin callers",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.JobProgress"
+                        }
+                    ]
+                },
+                "result": {
+                    "description": "Outcome of the job when it has one, e.g. the restaurants applied by a dry-run apply"
+                },
+                "status": {
+                    "description": "Status of the job (running, succeeded, failed, cancelled)",
+                    "type": "string"
+                }
+            }
+        },
+        "model.JobProgress": {
+            "type": "object",
+            "properties": {
+                "done": {
+                    "type": "integer"
+                },
+                "step": {
+                    "description": "Step the job is running, e.g. recalculate or keywords",
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.Keyword": {
+            "type": "object",
+            "properties": {
+                "phrase": {
+                    "type": "string"
+                },
+                "review_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.KeywordGroup": {
+            "type": "object",
+            "properties": {
+                "negative": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Keyword"
+                    }
+                },
+                "positive": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Keyword"
+                    }
+                }
+            }
+        },
+        "model.LabelRating": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "rating": {
+                    "type": "number"
+                },
+                "unknown_count": {
+                    "description": "Number of unknown-labeled reviews included in the rating",
+                    "type": "integer"
+                },
+                "unknown_share": {
+                    "description": "Share of the rating weight coming from unknown-labeled reviews, from 0 to 1",
+                    "type": "number"
+                }
+            }
+        },
+        "model.LabelsRating": {
+            "type": "object",
+            "properties": {
+                "ambience": {
+                    "$ref": "#/definitions/model.LabelRating"
+                },
+                "delivery": {
+                    "$ref": "#/definitions/model.LabelRating"
+                },
+                "food": {
+                    "$ref": "#/definitions/model.LabelRating"
+                },
+                "price": {
+                    "$ref": "#/definitions/model.LabelRating"
+                },
+                "service": {
+                    "$ref": "#/definitions/model.LabelRating"
+                },
+                "unknown": {
+                    "description": "Rating of the reviews labeled unknown, which no aspect could be found in",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.LabelRating"
+                        }
+                    ]
+                },
+                "unknown_policy": {
+                    "description": "How unknown ratings are counted: spread, overall or ignore",
+                    "type": "string"
+                }
+            }
+        },
+        "model.Menu": {
+            "type": "object",
+            "properties": {
+                "categories": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.MenuCategory"
+                    }
+                },
+                "total_dishes": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.MenuCategory": {
+            "description": "This struct is used to represent a category of a restaurant menu",
+            "type": "object",
+            "properties": {
+                "category_id": {
+                    "description": "Unique identifier of the category (0 for uncategorized dishes)",
+                    "type": "integer"
+                },
+                "category_name": {
+                    "description": "Name of the category",
+                    "type": "string"
+                },
+                "dishes": {
+                    "description": "Dishes in the category",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Dish"
+                    }
+                },
+                "max_price": {
+                    "description": "Highest dish price in the category",
+                    "type": "number"
+                },
+                "min_price": {
+                    "description": "Lowest dish price in the category",
+                    "type": "number"
+                }
+            }
+        },
+        "model.ModerationItem": {
+            "description": "A reported review waiting for a moderator decision",
+            "type": "object",
+            "properties": {
+                "feedback": {
+                    "type": "string"
+                },
+                "helpful_count": {
+                    "description": "Number of readers who found the review helpful",
+                    "type": "integer"
+                },
+                "label": {
+                    "type": "string"
+                },
+                "labels": {
+                    "description": "All labels attached to the review",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ReviewLabel"
+                    }
+                },
+                "last_reported_at": {
+                    "description": "Time of the latest report",
+                    "type": "string"
+                },
+                "platform": {
+                    "description": "Platform the review was collected from",
+                    "type": "string"
+                },
+                "rating": {
+                    "type": "number"
+                },
+                "rating_id": {
+                    "type": "string"
+                },
+                "rating_label": {
+                    "type": "number"
+                },
+                "reasons": {
+                    "description": "Number of unresolved reports per reason",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "report_count": {
+                    "description": "Number of unresolved reports",
+                    "type": "integer"
+                },
+                "restaurant_id": {
+                    "description": "Restaurant the review belongs to",
+                    "type": "string"
+                },
+                "review_time": {
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Moderation status (visible, flagged, hidden)",
+                    "type": "string"
+                },
+                "unhelpful_count": {
+                    "description": "Number of readers who found the review unhelpful",
+                    "type": "integer"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.ModerationQueue": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ModerationItem"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.PlatformLabelsRating": {
+            "type": "object",
+            "properties": {
+                "labels": {
+                    "$ref": "#/definitions/model.LabelsRating"
+                },
+                "platform": {
                     "type": "string"
                 },
-                "price": {
-                    "description": "Price of the dish",
+                "review_count": {
+                    "description": "Number of counted reviews from the platform",
+                    "type": "integer"
+                }
+            }
+        },
+        "model.PlatformRating": {
+            "type": "object",
+            "properties": {
+                "normalization": {
+                    "description": "How the rating was normalized: linear, distribution, linear_fallback for a distribution\nplatform rating not calibrated yet, or none when the platform scale is invalid",
+                    "type": "string"
+                },
+                "normalized_rating": {
+                    "description": "Rating brought to our 1 to 5 scale, 0 when it could not be normalized",
+                    "type": "number"
+                },
+                "platform": {
+                    "type": "string"
+                },
+                "rating": {
+                    "description": "Rating as shown on the platform, on its own scale",
+                    "type": "number"
+                },
+                "scale_max": {
+                    "type": "number"
+                },
+                "scale_min": {
                     "type": "number"
                 }
             }
         },
-        "model.LabelRating": {
+        "model.RankedRestaurant": {
+            "type": "object",
+            "properties": {
+                "movement": {
+                    "description": "Places gained since the last recalculation, negative when the restaurant dropped",
+                    "type": "integer"
+                },
+                "previous_rank": {
+                    "description": "Rank before the last recalculation, absent when the restaurant was not ranked",
+                    "type": "integer"
+                },
+                "rank": {
+                    "type": "integer"
+                },
+                "restaurant_id": {
+                    "type": "string"
+                },
+                "restaurant_name": {
+                    "type": "string"
+                },
+                "review_count": {
+                    "type": "integer"
+                },
+                "score": {
+                    "description": "Overall or label rating the restaurant is ranked by",
+                    "type": "number"
+                }
+            }
+        },
+        "model.Ranking": {
+            "description": "Ranked restaurants by overall or label rating, with their movement since the last recalculation",
+            "type": "object",
+            "properties": {
+                "city_id": {
+                    "type": "string"
+                },
+                "district_id": {
+                    "type": "string"
+                },
+                "food_type": {
+                    "type": "string"
+                },
+                "label": {
+                    "description": "Rating the restaurants are ranked by: overall or a label",
+                    "type": "string"
+                },
+                "restaurants": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.RankedRestaurant"
+                    }
+                },
+                "version": {
+                    "description": "Recalculation version the ranking was built at",
+                    "type": "integer"
+                }
+            }
+        },
+        "model.RatingBucket": {
             "type": "object",
             "properties": {
                 "count": {
+                    "description": "Number of ratings in the bucket",
+                    "type": "integer"
+                },
+                "stars": {
+                    "description": "Star value of the bucket (1 to 5)",
+                    "type": "integer"
+                }
+            }
+        },
+        "model.RatingDistribution": {
+            "type": "object",
+            "properties": {
+                "labels": {
+                    "description": "Histograms of the label ratings, keyed by label",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/model.RatingHistogram"
+                    }
+                },
+                "overall": {
+                    "description": "Histogram of the overall review ratings",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.RatingHistogram"
+                        }
+                    ]
+                },
+                "platforms": {
+                    "description": "Histograms of the overall review ratings, keyed by platform name",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/model.RatingHistogram"
+                    }
+                }
+            }
+        },
+        "model.RatingHistogram": {
+            "description": "Histogram of ratings with the mean and standard deviation, a high deviation means a polarizing restaurant",
+            "type": "object",
+            "properties": {
+                "average": {
+                    "description": "Mean of the ratings",
+                    "type": "number"
+                },
+                "buckets": {
+                    "description": "One bucket per star value, from 1 to 5",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.RatingBucket"
+                    }
+                },
+                "std_dev": {
+                    "description": "Population standard deviation of the ratings",
+                    "type": "number"
+                },
+                "total": {
+                    "description": "Total number of ratings",
+                    "type": "integer"
+                }
+            }
+        },
+        "model.RatingHistory": {
+            "description": "Paginated rating snapshots of a restaurant, one per full recalculation. Single-restaurant, incremental and moderation recalculations are not snapshotted.",
+            "type": "object",
+            "properties": {
+                "restaurant_id": {
+                    "type": "string"
+                },
+                "snapshots": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.RatingSnapshot"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.RatingMover": {
+            "type": "object",
+            "properties": {
+                "change": {
+                    "description": "Rating difference, positive when the restaurant went up",
+                    "type": "number"
+                },
+                "old_rating": {
+                    "type": "number"
+                },
+                "old_review_count": {
                     "type": "integer"
                 },
                 "rating": {
                     "type": "number"
+                },
+                "restaurant_id": {
+                    "type": "string"
+                },
+                "restaurant_name": {
+                    "type": "string"
+                },
+                "review_count": {
+                    "type": "integer"
                 }
             }
         },
-        "model.LabelsRating": {
+        "model.RatingMovers": {
+            "description": "Restaurants with the largest rating change between two recalculation versions",
             "type": "object",
             "properties": {
-                "ambience": {
-                    "$ref": "#/definitions/model.LabelRating"
+                "from_version": {
+                    "type": "integer"
                 },
-                "delivery": {
-                    "$ref": "#/definitions/model.LabelRating"
+                "movers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.RatingMover"
+                    }
                 },
-                "food": {
-                    "$ref": "#/definitions/model.LabelRating"
+                "to_version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.RatingSettings": {
+            "type": "object",
+            "properties": {
+                "aspect_weights": {
+                    "description": "Configured weight of each label in the overall rating, labels left out weigh 1",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number"
+                    }
+                },
+                "decay_half_life_days": {
+                    "type": "number"
+                },
+                "prior_scope": {
+                    "type": "string"
+                },
+                "prior_weight": {
+                    "type": "number"
+                },
+                "strategy": {
+                    "type": "string"
+                },
+                "suspicious_policy": {
+                    "type": "string"
+                },
+                "suspicious_weight": {
+                    "type": "number"
+                },
+                "unknown_policy": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.RatingSnapshot": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "labels": {
+                    "description": "Label ratings keyed by label, unknown ratings counted as by the unknown policy at the time",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number"
+                    }
+                },
+                "rating": {
+                    "type": "number"
+                },
+                "review_count": {
+                    "type": "integer"
+                },
+                "settings": {
+                    "description": "Rating settings the rating was computed with",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.RatingSettings"
+                        }
+                    ]
+                },
+                "strategy": {
+                    "description": "Rating strategy the rating was computed with (mean, bayesian, decay)",
+                    "type": "string"
+                },
+                "version": {
+                    "description": "Recalculation version the snapshot was taken at",
+                    "type": "integer"
+                }
+            }
+        },
+        "model.RatingTrend": {
+            "type": "object",
+            "properties": {
+                "granularity": {
+                    "description": "Size of each bucket (month or week)",
+                    "type": "string"
+                },
+                "points": {
+                    "description": "Buckets in chronological order, buckets without reviews are omitted",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.RatingTrendPoint"
+                    }
+                }
+            }
+        },
+        "model.RatingTrendPoint": {
+            "type": "object",
+            "properties": {
+                "average_rating": {
+                    "description": "Average overall rating of the reviews in the bucket",
+                    "type": "number"
+                },
+                "labels": {
+                    "description": "Average rating and count of each label in the bucket, keyed by label",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/model.LabelRating"
+                    }
                 },
-                "price": {
-                    "$ref": "#/definitions/model.LabelRating"
+                "period": {
+                    "description": "First day of the bucket (YYYY-MM-DD)",
+                    "type": "string"
                 },
-                "service": {
-                    "$ref": "#/definitions/model.LabelRating"
+                "review_count": {
+                    "description": "Number of reviews in the bucket",
+                    "type": "integer"
                 }
             }
         },
@@ -622,6 +3207,14 @@ const docTemplate = `{
                     "description": "Overall rating of the restaurant",
                     "type": "number"
                 },
+                "rating_version": {
+                    "description": "Full recalculation the rating and review count come from",
+                    "type": "integer"
+                },
+                "recalculated_at": {
+                    "description": "When the rating and review count were last recalculated, in full or for this restaurant only",
+                    "type": "string"
+                },
                 "review_count": {
                     "description": "Number of reviews for the restaurant",
                     "type": "integer"
@@ -631,6 +3224,13 @@ const docTemplate = `{
         "model.RestaurantDetail": {
             "type": "object",
             "properties": {
+                "aspect_weights": {
+                    "description": "Weight of each label in the overall rating currently configured for the food type of\nthe restaurant. Changes apply on the next recalculation, so the rating may still use\nolder weights. When all its rated labels weigh 0 the rating is their plain mean.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number"
+                    }
+                },
                 "labels": {
                     "description": "Ratings for different aspects of the restaurant\nambience, delivery, food, price, and service ratings",
                     "allOf": [
@@ -639,6 +3239,24 @@ const docTemplate = `{
                         }
                     ]
                 },
+                "labels_by_platform": {
+                    "description": "Label ratings per source platform of the reviews, only when a breakdown is requested",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.PlatformLabelsRating"
+                    }
+                },
+                "platform_disagreement": {
+                    "description": "Spread of the normalized ratings of the platforms and ours, from 0 (all agree) to 1",
+                    "type": "number"
+                },
+                "platform_ratings": {
+                    "description": "Raw and normalized ratings of the restaurant on each platform",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.PlatformRating"
+                    }
+                },
                 "platforms": {
                     "description": "List of platforms where the restaurant is available",
                     "type": "array",
@@ -663,6 +3281,41 @@ const docTemplate = `{
                 }
             }
         },
+        "model.RestaurantKeywords": {
+            "description": "Most frequent phrases per label, split by positive and negative label ratings",
+            "type": "object",
+            "properties": {
+                "labels": {
+                    "description": "Keyword groups keyed by label",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/model.KeywordGroup"
+                    }
+                },
+                "restaurant_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.RestaurantLabels": {
+            "description": "Label ratings of a restaurant, optionally broken down by the platform reviews come from",
+            "type": "object",
+            "properties": {
+                "by_platform": {
+                    "description": "Label ratings per source platform, only when a breakdown is requested",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.PlatformLabelsRating"
+                    }
+                },
+                "labels": {
+                    "$ref": "#/definitions/model.LabelsRating"
+                },
+                "restaurant_id": {
+                    "type": "string"
+                }
+            }
+        },
         "model.Review": {
             "description": "This struct is used to represent a review in the system",
             "type": "object",
@@ -670,9 +3323,24 @@ const docTemplate = `{
                 "feedback": {
                     "type": "string"
                 },
+                "helpful_count": {
+                    "description": "Number of readers who found the review helpful",
+                    "type": "integer"
+                },
                 "label": {
                     "type": "string"
                 },
+                "labels": {
+                    "description": "All labels attached to the review",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ReviewLabel"
+                    }
+                },
+                "platform": {
+                    "description": "Platform the review was collected from",
+                    "type": "string"
+                },
                 "rating": {
                     "type": "number"
                 },
@@ -685,11 +3353,44 @@ const docTemplate = `{
                 "review_time": {
                     "type": "string"
                 },
+                "unhelpful_count": {
+                    "description": "Number of readers who found the review unhelpful",
+                    "type": "integer"
+                },
                 "username": {
                     "type": "string"
                 }
             }
         },
+        "model.ReviewFlag": {
+            "type": "object",
+            "properties": {
+                "rating_id": {
+                    "type": "string"
+                },
+                "reasons": {
+                    "description": "Comma-separated reasons (burst, new_reviewer, one_sided_reviewer)",
+                    "type": "string"
+                },
+                "score": {
+                    "description": "Suspicion score between 0 and 1",
+                    "type": "number"
+                }
+            }
+        },
+        "model.ReviewLabel": {
+            "type": "object",
+            "properties": {
+                "label": {
+                    "description": "Aspect of the restaurant (ambience, delivery, food, price, service, unknown)",
+                    "type": "string"
+                },
+                "rating_label": {
+                    "description": "Rating given to the aspect",
+                    "type": "number"
+                }
+            }
+        },
         "model.ReviewResponse": {
             "type": "object",
             "properties": {
@@ -703,6 +3404,270 @@ const docTemplate = `{
                     "type": "integer"
                 }
             }
+        },
+        "model.ReviewVotes": {
+            "type": "object",
+            "properties": {
+                "helpful_count": {
+                    "type": "integer"
+                },
+                "rating_id": {
+                    "type": "string"
+                },
+                "unhelpful_count": {
+                    "type": "integer"
+                },
+                "vote": {
+                    "description": "Vote of the current client: helpful, unhelpful or none",
+                    "type": "string"
+                }
+            }
+        },
+        "model.ScheduleStatus": {
+            "description": "Cron schedule of a job with its next run and last run on this instance",
+            "type": "object",
+            "properties": {
+                "kind": {
+                    "type": "string"
+                },
+                "last_error": {
+                    "description": "Error of the last run when it could not start",
+                    "type": "string"
+                },
+                "last_job": {
+                    "description": "Job started by the last run, with its current status",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.Job"
+                        }
+                    ]
+                },
+                "last_outcome": {
+                    "description": "Outcome of the last run: started, skipped_running, skipped_locked or error",
+                    "type": "string"
+                },
+                "last_run": {
+                    "description": "Time of the last scheduled run on this instance",
+                    "type": "string"
+                },
+                "next_run": {
+                    "type": "string"
+                },
+                "schedule": {
+                    "description": "Cron expression of the schedule",
+                    "type": "string"
+                }
+            }
+        },
+        "model.StagedRating": {
+            "type": "object",
+            "properties": {
+                "district_id": {
+                    "description": "District the restaurant is ranked in, empty when it has none",
+                    "type": "string"
+                },
+                "district_rank": {
+                    "type": "integer"
+                },
+                "old_district_rank": {
+                    "description": "Rank of the restaurant in its district by rating, 1 being the best",
+                    "type": "integer"
+                },
+                "old_rating": {
+                    "type": "number"
+                },
+                "old_review_count": {
+                    "type": "integer"
+                },
+                "rating": {
+                    "type": "number"
+                },
+                "restaurant_id": {
+                    "type": "string"
+                },
+                "restaurant_name": {
+                    "type": "string"
+                },
+                "review_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.SuspiciousReport": {
+            "type": "object",
+            "properties": {
+                "total_windows": {
+                    "type": "integer"
+                },
+                "windows": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.SuspiciousWindow"
+                    }
+                }
+            }
+        },
+        "model.SuspiciousWindow": {
+            "description": "A time window where a restaurant received a burst of reviews far from its usual rating",
+            "type": "object",
+            "properties": {
+                "average_rating": {
+                    "description": "Average rating of the reviews in the window",
+                    "type": "number"
+                },
+                "baseline_daily_reviews": {
+                    "description": "Usual number of reviews per day outside the window",
+                    "type": "number"
+                },
+                "baseline_rating": {
+                    "description": "Average rating of the restaurant outside the window",
+                    "type": "number"
+                },
+                "detected_at": {
+                    "type": "string"
+                },
+                "direction": {
+                    "description": "positive for a burst of high ratings, negative for a burst of low ratings",
+                    "type": "string"
+                },
+                "flagged_reviews": {
+                    "description": "Reviews of the window flagged as suspicious",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ReviewFlag"
+                    }
+                },
+                "restaurant_id": {
+                    "type": "string"
+                },
+                "review_count": {
+                    "description": "Number of reviews in the window",
+                    "type": "integer"
+                },
+                "window_end": {
+                    "description": "Last day of the window (YYYY-MM-DD)",
+                    "type": "string"
+                },
+                "window_id": {
+                    "type": "integer"
+                },
+                "window_start": {
+                    "description": "First day of the window (YYYY-MM-DD)",
+                    "type": "string"
+                }
+            }
+        },
+        "model.UserProfile": {
+            "description": "This struct is used to judge how credible a reviewer is",
+            "type": "object",
+            "properties": {
+                "average_rating": {
+                    "description": "Average overall rating given by the user",
+                    "type": "number"
+                },
+                "first_review_time": {
+                    "description": "Time of the first review of the user",
+                    "type": "string"
+                },
+                "labels": {
+                    "description": "Average rating and count the user gives to each label, keyed by label",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/model.LabelRating"
+                    }
+                },
+                "last_review_time": {
+                    "description": "Time of the latest review of the user",
+                    "type": "string"
+                },
+                "platform": {
+                    "description": "Platform the user reviews on",
+                    "type": "string"
+                },
+                "restaurant_count": {
+                    "description": "Number of distinct restaurants reviewed by the user",
+                    "type": "integer"
+                },
+                "review_count": {
+                    "description": "Number of reviews written by the user",
+                    "type": "integer"
+                },
+                "user_id": {
+                    "description": "Unique identifier of the user",
+                    "type": "string"
+                },
+                "username": {
+                    "description": "Name of the user",
+                    "type": "string"
+                }
+            }
+        },
+        "model.UserReview": {
+            "type": "object",
+            "properties": {
+                "feedback": {
+                    "type": "string"
+                },
+                "helpful_count": {
+                    "description": "Number of readers who found the review helpful",
+                    "type": "integer"
+                },
+                "label": {
+                    "type": "string"
+                },
+                "labels": {
+                    "description": "All labels attached to the review",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ReviewLabel"
+                    }
+                },
+                "platform": {
+                    "description": "Platform the review was collected from",
+                    "type": "string"
+                },
+                "rating": {
+                    "type": "number"
+                },
+                "rating_id": {
+                    "type": "string"
+                },
+                "rating_label": {
+                    "type": "number"
+                },
+                "restaurant": {
+                    "description": "Summary of the reviewed restaurant",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.Restaurant"
+                        }
+                    ]
+                },
+                "review_time": {
+                    "type": "string"
+                },
+                "unhelpful_count": {
+                    "description": "Number of readers who found the review unhelpful",
+                    "type": "integer"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.UserReviewResponse": {
+            "type": "object",
+            "properties": {
+                "reviews": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.UserReview"
+                    }
+                },
+                "total_reviews": {
+                    "type": "integer"
+                }
+            }
         }
     },
     "securityDefinitions": {
@@ -64,6 +64,8 @@ def csv_to_sql_inserts(csv_file, table_name, batch_size=100):
         values_list = []
         row_count = 0
         batch_number = 1
+        # Feedback_label allows one rating per label and review, later repeats are skipped
+        insert_into = "INSERT IGNORE INTO" if table_name == 'Feedback_label' else "INSERT INTO"
         
         # Find index of review_time column if this is the Review table
         review_time_index = -1
@@ -100,7 +102,7 @@ def csv_to_sql_inserts(csv_file, table_name, batch_size=100):
             # If we've reached the batch size, add the INSERT statement
             if row_count >= batch_size:
                 inserts.append(f"-- Batch {batch_number}")
-                inserts.append(f"INSERT INTO {table_name} ({', '.join(headers)}) VALUES")
+                inserts.append(f"{insert_into} {table_name} ({', '.join(headers)}) VALUES")
                 inserts.append(',\n'.join(values_list) + ';')
                 inserts.append("")  # Add empty line between batches
                 values_list = []
@@ -110,7 +112,7 @@ def csv_to_sql_inserts(csv_file, table_name, batch_size=100):
         # Add any remaining rows
         if values_list:
             inserts.append(f"-- Batch {batch_number}")
-            inserts.append(f"INSERT INTO {table_name} ({', '.join(headers)}) VALUES")
+            inserts.append(f"{insert_into} {table_name} ({', '.join(headers)}) VALUES")
             inserts.append(',\n'.join(values_list) + ';')
     print(f"Processed {total_rows} rows from {csv_file}.")
     return inserts
@@ -135,7 +137,7 @@ def save_sql_file(file_path, lines):
     
     for line in lines:
         # Start of a new INSERT statement
-        if line.startswith('INSERT INTO'):
+        if line.startswith('INSERT '):
             if in_insert and current_insert:
                 # Save the previous insert statement
                 processed_lines.append(current_insert)
@@ -183,7 +185,7 @@ def save_sql_file(file_path, lines):
         # Find all the complete INSERT statements
         insert_statements = []
         for lines_group in processed_lines:
-            if len(lines_group) > 1 and lines_group[0].startswith('INSERT INTO'):
+            if len(lines_group) > 1 and lines_group[0].startswith('INSERT '):
                 insert_statements.append(lines_group)
         
         # Calculate size per part (approximately)
@@ -136,8 +136,7 @@ lines.append("INSERT INTO Feedback_label (feedback_label_id, label, rating_label
 labels = ["food", "service", "delivery", "price", "ambience"]
 label_id = 1
 for rid in range(1, review_id):
-    for _ in range(random.randint(1, 2)):  # about 300 total
-        label = random.choice(labels)
+    for label in random.sample(labels, random.randint(1, 2)):  # about 300 total, one rating per label
         rating_val = round(random.uniform(1.0, 5.0), 2)
         lines.append(f"({label_id}, '{label}', {rating_val}, {rid}),")
         label_id += 1
@@ -5,7 +5,13 @@ SET GLOBAL max_allowed_packet = 67108864;
 -- Platform table
 CREATE TABLE Platform (
     platform_id INT AUTO_INCREMENT PRIMARY KEY,
-    platform_name VARCHAR(100) NOT NULL UNIQUE
+    platform_name VARCHAR(100) NOT NULL UNIQUE,
+    -- Rating scale of the platform, and how its ratings are brought to ours: linear or distribution
+    scale_min DECIMAL(4, 2) NOT NULL DEFAULT 1,
+    scale_max DECIMAL(4, 2) NOT NULL DEFAULT 5,
+    normalization VARCHAR(20) NOT NULL DEFAULT 'linear',
+    CHECK (scale_min < scale_max),
+    CHECK (normalization IN ('linear', 'distribution'))
 );
 
 -- User table
@@ -46,6 +52,9 @@ CREATE TABLE Restaurant (
     city_id INT,
     district_id INT,
     food_type_id INT,
+    -- Full recalculation the review count and rating come from, and when they were last recalculated
+    rating_version INT DEFAULT 0,
+    recalculated_at TIMESTAMP NULL,
     FOREIGN KEY (city_id) REFERENCES City(city_id) ON DELETE SET NULL ON UPDATE CASCADE,
     FOREIGN KEY (district_id) REFERENCES District(district_id) ON DELETE SET NULL ON UPDATE CASCADE,
     FOREIGN KEY (food_type_id) REFERENCES Food_type(food_type_id) ON DELETE SET NULL ON UPDATE CASCADE
@@ -71,6 +80,15 @@ CREATE TABLE Review (
     rating DECIMAL(2, 1) NOT NULL,
     feedback TEXT,
     review_time TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    -- Set by the duplicate detection pass, NULL when the review is not a duplicate
+    duplicate_of VARCHAR(100),
+    duplicate_reason VARCHAR(20),
+    duplicate_similarity DECIMAL(4, 3),
+    -- Tallies of the votes in Review_vote
+    helpful_count INT NOT NULL DEFAULT 0,
+    unhelpful_count INT NOT NULL DEFAULT 0,
+    -- visible, flagged (reported, waiting for moderation) or hidden
+    moderation_status VARCHAR(10) NOT NULL DEFAULT 'visible',
     FOREIGN KEY (restaurant_id) REFERENCES Restaurant(restaurant_id) ON DELETE CASCADE ON UPDATE CASCADE,
     FOREIGN KEY (user_id) REFERENCES User(user_id) ON DELETE CASCADE ON UPDATE CASCADE
 );
@@ -81,9 +99,199 @@ CREATE TABLE Feedback_label (
     label VARCHAR(100) NOT NULL,
     rating_label DECIMAL(3, 2) NOT NULL,
     rating_id VARCHAR(100) NOT NULL,
+    -- One rating per label and review
+    UNIQUE (rating_id, label),
     FOREIGN KEY (rating_id) REFERENCES Review(rating_id) ON DELETE CASCADE ON UPDATE CASCADE
 );
 
+-- Helpful votes on reviews, one per client
+CREATE TABLE Review_vote (
+    rating_id VARCHAR(100) NOT NULL,
+    voter_hash CHAR(64) NOT NULL,
+    helpful BOOLEAN NOT NULL,
+    voted_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (rating_id, voter_hash),
+    FOREIGN KEY (rating_id) REFERENCES Review(rating_id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+-- Reports of abusive reviews, one per client and review
+CREATE TABLE Review_report (
+    report_id INT AUTO_INCREMENT PRIMARY KEY,
+    rating_id VARCHAR(100) NOT NULL,
+    reporter_hash CHAR(64) NOT NULL,
+    reason VARCHAR(20) NOT NULL,
+    details TEXT,
+    resolved BOOLEAN NOT NULL DEFAULT FALSE,
+    reported_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (rating_id, reporter_hash),
+    FOREIGN KEY (rating_id) REFERENCES Review(rating_id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+-- Suspicious time windows found by the review-bombing detector
+CREATE TABLE Suspicious_window (
+    window_id INT AUTO_INCREMENT PRIMARY KEY,
+    restaurant_id VARCHAR(100) NOT NULL,
+    window_start DATE NOT NULL,
+    window_end DATE NOT NULL,
+    direction VARCHAR(10) NOT NULL,
+    review_count INT NOT NULL,
+    average_rating DECIMAL(3, 2) NOT NULL,
+    baseline_rating DECIMAL(3, 2) NOT NULL,
+    baseline_daily_reviews DECIMAL(10, 4) NOT NULL,
+    detected_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (restaurant_id) REFERENCES Restaurant(restaurant_id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+-- Reviews flagged by the review-bombing detector
+CREATE TABLE Review_flag (
+    rating_id VARCHAR(100) PRIMARY KEY,
+    window_id INT NOT NULL,
+    score DECIMAL(4, 3) NOT NULL,
+    reasons VARCHAR(255) NOT NULL,
+    FOREIGN KEY (rating_id) REFERENCES Review(rating_id) ON DELETE CASCADE ON UPDATE CASCADE,
+    FOREIGN KEY (window_id) REFERENCES Suspicious_window(window_id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+-- Weighted label ratings of the counted reviews of each restaurant, maintained by
+-- recalculation and review writes so restaurant details do not scan reviews
+CREATE TABLE Restaurant_label_stats (
+    restaurant_id VARCHAR(100) NOT NULL,
+    label VARCHAR(100) NOT NULL,
+    rating_sum DECIMAL(14, 4) NOT NULL,
+    weight DECIMAL(14, 4) NOT NULL,
+    review_count INT NOT NULL,
+    average_rating DECIMAL(3, 2),
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (restaurant_id, label),
+    FOREIGN KEY (restaurant_id) REFERENCES Restaurant(restaurant_id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+-- Restaurants whose reviews changed since their last recalculation
+CREATE TABLE Restaurant_dirty (
+    restaurant_id VARCHAR(100) PRIMARY KEY,
+    marked_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (restaurant_id) REFERENCES Restaurant(restaurant_id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+-- Frequent phrases of the labeled reviews of each restaurant, rebuilt on recalculation
+CREATE TABLE Restaurant_keyword (
+    restaurant_id VARCHAR(100) NOT NULL,
+    label VARCHAR(100) NOT NULL,
+    sentiment VARCHAR(10) NOT NULL,
+    phrase VARCHAR(255) NOT NULL,
+    review_count INT NOT NULL,
+    PRIMARY KEY (restaurant_id, label, sentiment, phrase),
+    FOREIGN KEY (restaurant_id) REFERENCES Restaurant(restaurant_id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+-- Review counts and ratings computed by a dry-run recalculation, next to the values
+-- they would replace, until they are applied
+CREATE TABLE Restaurant_rating_staging (
+    restaurant_id VARCHAR(100) PRIMARY KEY,
+    district_id INT,
+    food_type_id INT,
+    old_review_count INT,
+    old_rating DECIMAL(3, 2),
+    review_count INT DEFAULT 0,
+    restaurant_rating DECIMAL(3, 2),
+    staged_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (restaurant_id) REFERENCES Restaurant(restaurant_id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+-- Label stats computed along with the staged and the shadow ratings, swapped in with them
+CREATE TABLE Restaurant_label_stats_staging (
+    restaurant_id VARCHAR(100) NOT NULL,
+    label VARCHAR(100) NOT NULL,
+    rating_sum DECIMAL(14, 4) NOT NULL,
+    weight DECIMAL(14, 4) NOT NULL,
+    review_count INT NOT NULL,
+    average_rating DECIMAL(3, 2),
+    PRIMARY KEY (restaurant_id, label),
+    FOREIGN KEY (restaurant_id) REFERENCES Restaurant(restaurant_id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE TABLE Restaurant_label_stats_shadow (
+    restaurant_id VARCHAR(100) NOT NULL,
+    label VARCHAR(100) NOT NULL,
+    rating_sum DECIMAL(14, 4) NOT NULL,
+    weight DECIMAL(14, 4) NOT NULL,
+    review_count INT NOT NULL,
+    average_rating DECIMAL(3, 2),
+    PRIMARY KEY (restaurant_id, label),
+    FOREIGN KEY (restaurant_id) REFERENCES Restaurant(restaurant_id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+-- Review counts and ratings computed by a full recalculation before they are swapped in
+CREATE TABLE Restaurant_rating_shadow (
+    restaurant_id VARCHAR(100) PRIMARY KEY,
+    district_id INT,
+    food_type_id INT,
+    old_review_count INT,
+    old_rating DECIMAL(3, 2),
+    review_count INT DEFAULT 0,
+    restaurant_rating DECIMAL(3, 2),
+    staged_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (restaurant_id) REFERENCES Restaurant(restaurant_id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+-- Review count, rating and label ratings of every restaurant after each full recalculation,
+-- with the rating settings they were computed with
+CREATE TABLE Restaurant_rating_snapshot (
+    restaurant_id VARCHAR(100) NOT NULL,
+    rating_version INT NOT NULL,
+    strategy VARCHAR(20) NOT NULL,
+    settings JSON NOT NULL,
+    restaurant_rating DECIMAL(3, 2),
+    review_count INT DEFAULT 0,
+    ambience_rating DECIMAL(3, 2),
+    delivery_rating DECIMAL(3, 2),
+    food_rating DECIMAL(3, 2),
+    price_rating DECIMAL(3, 2),
+    service_rating DECIMAL(3, 2),
+    unknown_rating DECIMAL(3, 2),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (restaurant_id, rating_version),
+    FOREIGN KEY (restaurant_id) REFERENCES Restaurant(restaurant_id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+-- Normalized value of each rating of a platform, calibrated on our rating distribution on recalculation
+CREATE TABLE Platform_rating_calibration (
+    platform_id INT NOT NULL,
+    raw_rating DECIMAL(4, 2) NOT NULL,
+    normalized_rating DECIMAL(3, 2) NOT NULL,
+    PRIMARY KEY (platform_id, raw_rating),
+    FOREIGN KEY (platform_id) REFERENCES Platform(platform_id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+-- Weight of each label in the overall rating of restaurants of a food type,
+-- overriding RATING_ASPECT_WEIGHTS. Applied on recalculation.
+CREATE TABLE Food_type_aspect_weight (
+    food_type_id INT NOT NULL,
+    label VARCHAR(100) NOT NULL,
+    weight DECIMAL(5, 2) NOT NULL,
+    PRIMARY KEY (food_type_id, label),
+    CHECK (weight >= 0),
+    FOREIGN KEY (food_type_id) REFERENCES Food_type(food_type_id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+-- Top restaurants per label in every city, district and food type combination, rebuilt on recalculation.
+-- A zero city, district or food type means any.
+CREATE TABLE Restaurant_ranking (
+    rating_version INT NOT NULL,
+    city_id INT NOT NULL,
+    district_id INT NOT NULL,
+    food_type_id INT NOT NULL,
+    label VARCHAR(20) NOT NULL,
+    rank_position INT NOT NULL,
+    restaurant_id VARCHAR(100) NOT NULL,
+    score DECIMAL(3, 2) NOT NULL,
+    review_count INT NOT NULL,
+    -- Rank before the recalculation, NULL when the restaurant was not ranked
+    previous_rank INT,
+    PRIMARY KEY (rating_version, city_id, district_id, food_type_id, label, rank_position),
+    FOREIGN KEY (restaurant_id) REFERENCES Restaurant(restaurant_id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
 -- Temp table
 CREATE TABLE Temp (
     UniqueID INT AUTO_INCREMENT PRIMARY KEY,
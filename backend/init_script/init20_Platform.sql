@@ -2,6 +2,8 @@ USE angi_db;
 
 -- Insert data into Platform
 -- Batch 1
-INSERT INTO Platform (platform_id, platform_name) VALUES
-(1, 'befood'),
-(2, 'foody');
\ No newline at end of file
+-- BeFood rates to one decimal on our 1 to 5 scale, Foody only in whole stars that are
+-- mostly 4, so its ratings are calibrated on the distribution of ours
+INSERT INTO Platform (platform_id, platform_name, scale_min, scale_max, normalization) VALUES
+(1, 'befood', 1, 5, 'linear'),
+(2, 'foody', 1, 5, 'distribution');
\ No newline at end of file
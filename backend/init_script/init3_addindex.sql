@@ -18,6 +18,9 @@ CREATE INDEX idx_restaurant_name ON Restaurant(restaurant_name);
 -- Improves FindDishesByRestaurantID
 CREATE INDEX idx_dish_restaurant ON Dish(restaurant_id);
 
+-- Improves grouping the menu by category
+CREATE INDEX idx_dish_restaurant_category ON Dish(restaurant_id, category_id);
+
 -- Improves review lookup by restaurant
 CREATE INDEX idx_review_restaurant ON Review(restaurant_id);
 
@@ -26,6 +29,10 @@ CREATE INDEX idx_review_restaurant_time ON Review(restaurant_id, review_time DES
 
 CREATE INDEX idx_review_user ON Review(user_id);
 
+-- Improves the one review per user check of CreateReview. Not unique: imported reviews
+-- can repeat a user, the check is serialized by the restaurant row lock instead
+CREATE INDEX idx_review_restaurant_user ON Review(restaurant_id, user_id);
+
 -- Improves CalculateLabelsRating
 CREATE INDEX idx_feedback_label_rating ON Feedback_label(rating_id);
 
@@ -44,5 +51,26 @@ CREATE INDEX idx_feedback_label_rating_value ON Feedback_label(rating_label);
 -- Improves joins between Review and Feedback_label tables
 CREATE INDEX idx_review_rating_id ON Review(rating_id);
 
--- Improves FindPlatformsAndRatingsByRestaurantID
-CREATE INDEX idx_temp_restaurant_platform ON Temp(restaurant_id, platform_id);
\ No newline at end of file
+-- Improves sorting reviews by helpfulness
+CREATE INDEX idx_review_restaurant_helpful ON Review(restaurant_id, helpful_count);
+
+-- Improves listing the moderation queue
+CREATE INDEX idx_review_moderation_status ON Review(moderation_status);
+
+-- Improves the duplicate cluster report
+CREATE INDEX idx_review_duplicate_of ON Review(duplicate_of);
+
+-- Improves listing suspicious windows by restaurant
+CREATE INDEX idx_suspicious_window_restaurant ON Suspicious_window(restaurant_id, window_start);
+
+-- Improves listing the reviews flagged in a window
+CREATE INDEX idx_review_flag_window ON Review_flag(window_id);
+
+-- Improves FindPlatformRatingsByRestaurantID
+CREATE INDEX idx_temp_restaurant_platform ON Temp(restaurant_id, platform_id);
+
+-- Improves comparing the snapshots of two recalculations
+CREATE INDEX idx_snapshot_version ON Restaurant_rating_snapshot(rating_version);
+
+-- Improves finding the previous rank of a restaurant when rankings are rebuilt
+CREATE INDEX idx_ranking_restaurant ON Restaurant_ranking(city_id, district_id, food_type_id, label, restaurant_id);
\ No newline at end of file
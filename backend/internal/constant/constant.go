@@ -1,5 +1,203 @@
 package constant
 
+import "time"
+
 const (
 	NumberofRestaurantsperPage = 24
 )
+
+// Menu sort options
+const (
+	MenuSortPriceAsc  = "price_asc"
+	MenuSortPriceDesc = "price_desc"
+	MenuSortName      = "name"
+)
+
+// Review submission limits
+const (
+	ReviewMinRating         = 1
+	ReviewMaxRating         = 5
+	ReviewMaxFeedbackLength = 2000
+)
+
+// Review labels
+const (
+	LabelAmbience = "ambience"
+	LabelDelivery = "delivery"
+	LabelFood     = "food"
+	LabelPrice    = "price"
+	LabelService  = "service"
+	LabelUnknown  = "unknown"
+)
+
+// AspectLabels lists the labels shown as restaurant aspects, in display order
+var AspectLabels = []string{LabelAmbience, LabelDelivery, LabelFood, LabelPrice, LabelService}
+
+// Review sort options
+const (
+	ReviewSortNewest  = "newest"
+	ReviewSortOldest  = "oldest"
+	ReviewSortHighest = "highest"
+	ReviewSortLowest  = "lowest"
+	ReviewSortHelpful = "helpful"
+)
+
+// Helpful votes
+const (
+	VoteHelpful   = "helpful"
+	VoteUnhelpful = "unhelpful"
+	VoteNone      = "none"
+)
+
+// Rating trend granularities
+const (
+	TrendGranularityMonth = "month"
+	TrendGranularityWeek  = "week"
+)
+
+// Duplicate review detection
+const (
+	// Reasons a review is marked as duplicate
+	DuplicateReasonSameUserDay = "same_user_day"
+	DuplicateReasonExact       = "exact"
+	DuplicateReasonNear        = "near"
+
+	// Words per shingle used to fingerprint review text
+	DuplicateShingleSize = 3
+	// Reviews with fewer words are not compared by text, short texts like "ngon" are too common
+	DuplicateMinWords = 6
+	// Number of MinHash functions, split into bands of rows for locality-sensitive hashing
+	DuplicateMinHashCount = 64
+	DuplicateLSHBands     = 16
+	// Minimum Jaccard similarity of shingles for two texts to be near duplicates
+	DuplicateSimilarityThreshold = 0.8
+	// Duplicate clusters per page in the admin report
+	DuplicateClustersPerPage = 20
+)
+
+// Review-bombing detection
+const (
+	// Directions of a suspicious window
+	SuspiciousDirectionPositive = "positive"
+	SuspiciousDirectionNegative = "negative"
+
+	// Reasons a review is flagged
+	SuspiciousReasonBurst            = "burst"
+	SuspiciousReasonNewReviewer      = "new_reviewer"
+	SuspiciousReasonOneSidedReviewer = "one_sided_reviewer"
+
+	// A day is a burst when it has at least this many reviews...
+	SuspiciousMinBurstReviews = 5
+	// ...at least this many times the usual daily volume...
+	SuspiciousVelocityFactor = 5.0
+	// ...and an average this many stars away from the usual rating
+	SuspiciousRatingDeviation = 1.5
+	// Restaurants need this many reviews outside a burst to have a baseline
+	SuspiciousMinBaselineReviews = 10
+	// Reviewers whose average is this close to 1 or 5 stars only give extreme ratings
+	SuspiciousOneSidedMargin = 0.25
+	// Suspicious windows per page in the admin report
+	SuspiciousWindowsPerPage = 20
+)
+
+// Review moderation
+const (
+	ModerationVisible = "visible"
+	ModerationFlagged = "flagged"
+	ModerationHidden  = "hidden"
+
+	ReportMaxDetailsLength = 500
+	ModerationItemsPerPage = 20
+)
+
+// Keyword extraction
+const (
+	KeywordSentimentPositive = "positive"
+	KeywordSentimentNegative = "negative"
+
+	// Label ratings at or above this are positive, at or below the negative one are negative
+	KeywordPositiveMinRating = 4
+	KeywordNegativeMaxRating = 2
+	// Phrases are n-grams of this many words (Vietnamese words are single syllables)
+	KeywordMinWords = 2
+	KeywordMaxWords = 5
+	// A phrase must appear in at least this many reviews to be kept
+	KeywordMinReviews = 2
+	// Phrases kept per label and sentiment
+	KeywordsPerGroup = 10
+)
+
+// Background jobs
+const (
+	JobKindRecalculate      = "recalculate"
+	JobKindRecalculateDirty = "recalculate_dirty"
+	JobKindExport           = "export"
+	JobKindDetectDuplicates = "detect_duplicates"
+	JobKindDetectSuspicious = "detect_suspicious"
+	JobKindLabelReviews     = "label_reviews"
+	JobKindDryRun           = "recalculate_dry_run"
+	JobKindApplyDryRun      = "recalculate_dry_run_apply"
+
+	JobStatusRunning   = "running"
+	JobStatusSucceeded = "succeeded"
+	JobStatusFailed    = "failed"
+	JobStatusCancelled = "cancelled"
+
+	// Finished jobs are forgotten after this long
+	JobRetention = 24 * time.Hour
+
+	// Outcomes of a scheduled run
+	ScheduleOutcomeStarted        = "started"
+	ScheduleOutcomeSkippedRunning = "skipped_running"
+	ScheduleOutcomeSkippedLocked  = "skipped_locked"
+	ScheduleOutcomeError          = "error"
+
+	// Prefix of the MySQL lock names guarding jobs across replicas
+	ScheduleLockPrefix = "angi_schedule_"
+	// Lock shared by the jobs writing ratings (RatingJobKinds)
+	RatingsLock = "ratings"
+
+	// Breakdown of label ratings by the platform reviews come from
+	LabelBreakdownPlatform = "platform"
+
+	// Rankings cover the overall rating and every aspect label
+	RankingLabelOverall = "overall"
+	// Number of restaurants kept per ranking, and returned by default
+	RankingSize         = 100
+	RankingDefaultLimit = 10
+	// Restaurants need this many counted reviews to be ranked
+	RankingMinReviews = 10
+
+	// How the ratings of another platform are brought to our scale
+	PlatformNormalizationLinear       = "linear"
+	PlatformNormalizationDistribution = "distribution"
+	// Ratings of distribution platforms not calibrated yet are mapped linearly
+	PlatformNormalizationLinearFallback = "linear_fallback"
+	// Ratings of platforms with an invalid scale are not normalized
+	PlatformNormalizationNone = "none"
+
+	// Number of rating snapshots per page of a restaurant history
+	RatingHistoryPerPage = 30
+	// Number of restaurants returned by the biggest movers endpoint, by default and at most
+	RatingMoversDefaultLimit = 20
+	RatingMoversMaxLimit     = 100
+
+	// Number of restaurants listed in a dry-run summary, overall and per district
+	DryRunLargestMovers  = 20
+	DryRunDistrictMovers = 5
+)
+
+// RatingJobKinds lists the jobs writing ratings, review counts or what they are computed
+// from. They share one replica lock.
+var RatingJobKinds = []string{
+	JobKindRecalculate,
+	JobKindRecalculateDirty,
+	JobKindDryRun,
+	JobKindApplyDryRun,
+	JobKindDetectDuplicates,
+	JobKindDetectSuspicious,
+	JobKindLabelReviews,
+}
+
+// ReportReasons lists the reasons a reader can give when reporting a review
+var ReportReasons = []string{"spam", "offensive", "fake", "off_topic", "other"}
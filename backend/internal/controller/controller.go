@@ -5,7 +5,11 @@ import (
 	"strconv"
 	"strings"
 
+	"skeleton-internship-backend/config"
+	"skeleton-internship-backend/internal/constant"
+	"skeleton-internship-backend/internal/job"
 	"skeleton-internship-backend/internal/model"
+	"skeleton-internship-backend/internal/scheduler"
 	"skeleton-internship-backend/internal/service"
 
 	"github.com/gin-gonic/gin"
@@ -13,17 +17,28 @@ import (
 )
 
 type Controller struct {
-	service service.Service
+	service   service.Service
+	cfg       *config.Config
+	jobs      *job.Manager
+	scheduler *scheduler.Scheduler
 }
 
-func NewController(service service.Service) *Controller {
+func NewController(service service.Service, cfg *config.Config, jobs *job.Manager, scheduler *scheduler.Scheduler) *Controller {
 	return &Controller{
-		service: service,
+		service:   service,
+		cfg:       cfg,
+		jobs:      jobs,
+		scheduler: scheduler,
 	}
 }
 
 func (c *Controller) RegisterRoutes(router *gin.Engine) {
 	log.Info().Msg("Registering routes")
+	// Clients are identified by their IP, so forwarding headers are only believed from
+	// configured proxies. Without any, gin would take them from every client.
+	if err := router.SetTrustedProxies(c.cfg.Server.TrustedProxies); err != nil {
+		log.Error().Err(err).Msg("Invalid trusted proxies")
+	}
 	router.GET("/health", c.HealthCheck)
 	v1 := router.Group("/api/v1")
 	{
@@ -32,12 +47,50 @@ func (c *Controller) RegisterRoutes(router *gin.Engine) {
 			restaurants.GET("/:id", c.GetRestaurantDetailByID)
 			restaurants.GET("/search", c.AutocompleteRestaurants)
 			restaurants.GET("/:id/menu", c.GetRestaurantMenuByID)
-			restaurants.GET("/:id/reviews", c.GetRestaurantReviewsByLabel)
+			restaurants.GET("/:id/reviews", c.GetRestaurantReviews)
+			restaurants.POST("/:id/reviews", c.CreateRestaurantReview)
+			restaurants.GET("/:id/ratings/distribution", c.GetRatingDistribution)
+			restaurants.GET("/:id/ratings/trend", c.GetRatingTrend)
+			restaurants.GET("/:id/keywords", c.GetRestaurantKeywords)
+			restaurants.GET("/:id/labels", c.GetRestaurantLabels)
+			restaurants.GET("/:id/history", c.GetRatingHistory)
+			restaurants.GET("/movers", c.GetRatingMovers)
+		}
+		reviews := v1.Group("/reviews")
+		{
+			reviews.POST("/:id/vote", c.VoteReview)
+			reviews.DELETE("/:id/vote", c.DeleteReviewVote)
+			reviews.POST("/:id/report", c.ReportReview)
+		}
+		users := v1.Group("/users")
+		{
+			users.GET("/:id", c.GetUserProfile)
+			users.GET("/:id/reviews", c.GetUserReviews)
 		}
 		v1.GET("/restaurants", c.GetRestaurantsByFilter)
 		v1.GET("/foodtypes", c.GetAllFoodTypes)
-		v1.POST("/recalculate", c.RecalculateRestaurants)
+		v1.GET("/rankings", c.GetRankings)
 		v1.POST("/export", c.ExportRestaurantsToCSV)
+		v1.GET("/jobs/:id", c.GetJob)
+		v1.GET("/schedules", c.GetSchedules)
+
+		admin := v1.Group("/admin", c.AdminAuth())
+		{
+			admin.POST("/duplicates/detect", c.DetectDuplicateReviews)
+			admin.GET("/duplicates", c.GetDuplicateClusters)
+			admin.POST("/suspicious/detect", c.DetectSuspiciousReviews)
+			admin.GET("/suspicious", c.GetSuspiciousWindows)
+			admin.POST("/labels/run", c.LabelUnlabeledReviews)
+			admin.GET("/moderation", c.GetModerationQueue)
+			admin.POST("/moderation/:id/approve", c.ApproveReview)
+			admin.POST("/moderation/:id/hide", c.HideReview)
+			admin.POST("/recalculate", c.RecalculateRestaurants)
+			admin.POST("/recalculate/dry-run", c.DryRunRecalculation)
+			admin.GET("/recalculate/dry-run", c.GetDryRunSummary)
+			admin.GET("/recalculate/dry-run/diff", c.GetDryRunDiff)
+			admin.POST("/recalculate/dry-run/apply", c.ApplyDryRun)
+			admin.POST("/restaurants/:id/recalculate", c.RecalculateRestaurant)
+		}
 	}
 }
 
@@ -63,6 +116,7 @@ func (x *Controller) HealthCheck(ctx *gin.Context) {
 // @Param id path string true "Restaurant ID"
 // @Param lat query number false "Latitude" (optional)
 // @Param lng query number false "Longitude" (optional)
+// @Param breakdown query string false "Break label ratings down by review platform (platform)" (optional)
 // @Success 200 {object} model.Response{data=model.RestaurantDetail}
 // @Failure 400 {object} model.Response
 // @Failure 404 {object} model.Response
@@ -95,9 +149,15 @@ func (c *Controller) GetRestaurantDetailByID(ctx *gin.Context) {
 		}
 	}
 
+	breakdown := ctx.Query("breakdown")
+	if breakdown != "" && breakdown != constant.LabelBreakdownPlatform {
+		ctx.JSON(http.StatusBadRequest, model.NewResponse("Invalid breakdown. Must be: platform", nil))
+		return
+	}
+
 	id := ctx.Param("id")
 
-	restaurantDetail, err := c.service.GetRestaurantDetail(id, lat, lng)
+	restaurantDetail, err := c.service.GetRestaurantDetail(id, lat, lng, breakdown == constant.LabelBreakdownPlatform)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to get restaurant detail")
 		if err.Error() == "not found" {
@@ -260,14 +320,19 @@ func (c *Controller) GetRestaurantsByFilter(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, model.NewResponse(message, response))
 }
 
-// GetRestaurantReviewsByLabel godoc
-// @Summary Get restaurant reviews by label
-// @Description get restaurant reviews by ID and label
+// GetRestaurantReviews godoc
+// @Summary Get restaurant reviews
+// @Description get restaurant reviews by ID, each review once with all of its labels
+// @Description If label is provided, only reviews rating that label (or unknown) are returned.
 // @Tags restaurants
 // @Accept json
 // @Produce json
 // @Param id path string true "Restaurant ID"
-// @Param label query string true "Label type (ambience, delivery, food, price, service)"
+// @Param label query string false "Label type (ambience, delivery, food, price, service)" (optional)
+// @Param sort query string false "Sort order (newest, oldest, highest, lowest, helpful)" default(newest)
+// @Param min_rating query number false "Minimum overall rating" (optional)
+// @Param max_rating query number false "Maximum overall rating" (optional)
+// @Param platform query string false "Platform name (e.g. Foody, BeFood)" (optional)
 // @Param page query int true "Page number" default(1)
 // @Param count query boolean false "Whether to count total reviews" default(true)
 // @Param textonly query boolean false "If textonly = true, we get text only (ignore null reviews)" default(false)
@@ -275,18 +340,19 @@ func (c *Controller) GetRestaurantsByFilter(ctx *gin.Context) {
 // @Failure 400 {object} model.Response
 // @Failure 404 {object} model.Response
 // @Router /api/v1/restaurants/{id}/reviews [get]
-func (c *Controller) GetRestaurantReviewsByLabel(ctx *gin.Context) {
-	log.Info().Msg("Fetching restaurant reviews by label")
+func (c *Controller) GetRestaurantReviews(ctx *gin.Context) {
+	log.Info().Msg("Fetching restaurant reviews")
 
 	id := ctx.Param("id")
-	label := ctx.Query("label")
-	if label == "" {
-		ctx.JSON(http.StatusBadRequest, model.NewResponse("Label parameter is required", nil))
-		return
+	filter := model.ReviewFilter{
+		Label:    ctx.Query("label"),
+		Sort:     ctx.DefaultQuery("sort", constant.ReviewSortNewest),
+		Platform: ctx.Query("platform"),
 	}
 
 	// Validate label values
 	validLabels := map[string]bool{
+		"":         true,
 		"ambience": true,
 		"delivery": true,
 		"food":     true,
@@ -294,10 +360,45 @@ func (c *Controller) GetRestaurantReviewsByLabel(ctx *gin.Context) {
 		"service":  true,
 	}
 
-	if !validLabels[label] {
+	if !validLabels[filter.Label] {
 		ctx.JSON(http.StatusBadRequest, model.NewResponse("Invalid label. Must be one of: ambience, delivery, food, price, service", nil))
 		return
 	}
+
+	// Validate sort values
+	validSorts := map[string]bool{
+		constant.ReviewSortNewest:  true,
+		constant.ReviewSortOldest:  true,
+		constant.ReviewSortHighest: true,
+		constant.ReviewSortLowest:  true,
+		constant.ReviewSortHelpful: true,
+	}
+	if !validSorts[filter.Sort] {
+		ctx.JSON(http.StatusBadRequest, model.NewResponse("Invalid sort. Must be one of: newest, oldest, highest, lowest, helpful", nil))
+		return
+	}
+
+	// Parse rating bounds if provided
+	var err error
+	if minStr := ctx.Query("min_rating"); minStr != "" {
+		filter.MinRating, err = strconv.ParseFloat(minStr, 64)
+		if err != nil || filter.MinRating < constant.ReviewMinRating || filter.MinRating > constant.ReviewMaxRating {
+			ctx.JSON(http.StatusBadRequest, model.NewResponse("Invalid min_rating", nil))
+			return
+		}
+	}
+	if maxStr := ctx.Query("max_rating"); maxStr != "" {
+		filter.MaxRating, err = strconv.ParseFloat(maxStr, 64)
+		if err != nil || filter.MaxRating < constant.ReviewMinRating || filter.MaxRating > constant.ReviewMaxRating {
+			ctx.JSON(http.StatusBadRequest, model.NewResponse("Invalid max_rating", nil))
+			return
+		}
+	}
+	if filter.MinRating > 0 && filter.MaxRating > 0 && filter.MinRating > filter.MaxRating {
+		ctx.JSON(http.StatusBadRequest, model.NewResponse("min_rating must not be greater than max_rating", nil))
+		return
+	}
+
 	// Extract and validate page parameter
 	pageStr := ctx.DefaultQuery("page", "1")
 	page, err := strconv.Atoi(pageStr)
@@ -311,10 +412,10 @@ func (c *Controller) GetRestaurantReviewsByLabel(ctx *gin.Context) {
 
 	// Check if textonly parameter is provided
 	textOnlyStr := ctx.DefaultQuery("textonly", "false")
-	textOnly := textOnlyStr == "true"
+	filter.TextOnly = textOnlyStr == "true"
 
 	// Get reviews from service
-	reviewResponse, err := c.service.GetRestaurantReviewsByLabel(id, label, page, isCount, textOnly)
+	reviewResponse, err := c.service.GetRestaurantReviews(id, filter, page, isCount)
 	if err != nil {
 		if err.Error() == "not found" {
 			ctx.JSON(http.StatusNotFound, model.NewResponse("Restaurant not found", nil))
@@ -324,19 +425,23 @@ func (c *Controller) GetRestaurantReviewsByLabel(ctx *gin.Context) {
 		return
 	}
 
-	log.Info().Msgf("Fetching successful: Fetched %d reviews for restaurant ID: %s with label: %s", len(reviewResponse.Reviews), id, label)
+	log.Info().Msgf("Fetching successful: Fetched %d reviews for restaurant ID: %s with filter: %+v", len(reviewResponse.Reviews), id, filter)
 
 	ctx.JSON(http.StatusOK, model.NewResponse("Reviews fetched successfully", reviewResponse))
 }
 
 // GetRestaurantMenuByID godoc
 // @Summary Get restaurant menu
-// @Description get restaurant menu by ID
+// @Description get restaurant menu by ID, grouped by category in menu order with the price range of each category
+// @Description If q is provided, only dishes whose name or category contains every word of q are returned.
 // @Tags restaurants
 // @Accept json
 // @Produce json
 // @Param id path string true "Restaurant ID"
-// @Success 200 {object} model.Response{data=[]model.Dish}
+// @Param q query string false "Search within the menu" (optional)
+// @Param sort query string false "Sort dishes inside each category (price_asc, price_desc, name)" (optional)
+// @Success 200 {object} model.Response{data=model.Menu}
+// @Failure 400 {object} model.Response
 // @Failure 404 {object} model.Response
 // @Failure 500 {object} model.Response
 // @Router /api/v1/restaurants/{id}/menu [get]
@@ -344,9 +449,23 @@ func (c *Controller) GetRestaurantMenuByID(ctx *gin.Context) {
 	log.Info().Msg("Fetching restaurant menu by ID")
 
 	id := ctx.Param("id")
+	searchWords := strings.Fields(ctx.Query("q"))
+
+	// Validate sort values
+	sortBy := ctx.Query("sort")
+	validSorts := map[string]bool{
+		"":                         true,
+		constant.MenuSortPriceAsc:  true,
+		constant.MenuSortPriceDesc: true,
+		constant.MenuSortName:      true,
+	}
+	if !validSorts[sortBy] {
+		ctx.JSON(http.StatusBadRequest, model.NewResponse("Invalid sort. Must be one of: price_asc, price_desc, name", nil))
+		return
+	}
 
 	// Fetch the menu using the service layer
-	menu, err := c.service.GetDishesByRestaurantID(id)
+	menu, err := c.service.GetRestaurantMenu(id, searchWords, sortBy)
 	if err != nil {
 		if err.Error() == "not found" {
 			ctx.JSON(http.StatusNotFound, model.NewResponse("Restaurant not found", nil))
@@ -355,7 +474,7 @@ func (c *Controller) GetRestaurantMenuByID(ctx *gin.Context) {
 		}
 		return
 	}
-	log.Info().Msgf("Fetching successful: Fetched %d dishes for restaurant ID: %s", len(menu), id)
+	log.Info().Msgf("Fetching successful: Fetched %d dishes in %d categories for restaurant ID: %s", menu.TotalDishes, len(menu.Categories), id)
 	ctx.JSON(http.StatusOK, model.NewResponse("Restaurant menu fetched successfully", menu))
 }
 
@@ -406,47 +525,67 @@ func (c *Controller) AutocompleteRestaurants(ctx *gin.Context) {
 
 // RecalculateRestaurants godoc
 // @Summary Recalculate restaurant ratings
-// @Description Recalculates ratings and review counts for all restaurants based on reviews and feedback labels
-// @Tags restaurants
+// @Description Starts a job recalculating ratings and review counts for all restaurants based on reviews and feedback labels.
+// @Description If a recalculation is already running, that job is returned instead of starting another one.
+// @Description The job takes the replica lock of the jobs writing ratings and fails when another job holds it.
+// @Tags admin
 // @Accept json
 // @Produce json
-// @Success 202 {object} model.Response
-// @Failure 500 {object} model.Response
-// @Router /api/v1/recalculate [post]
+// @Security Bearer
+// @Success 202 {object} model.Response{data=model.Job}
+// @Failure 401 {object} model.Response
+// @Failure 503 {object} model.Response
+// @Router /api/v1/admin/recalculate [post]
 func (c *Controller) RecalculateRestaurants(ctx *gin.Context) {
 	log.Info().Msg("Starting recalculation of restaurant ratings in background")
 
-	go func() {
-		err := c.service.RecalculateRestaurantsRating()
-		if err != nil {
-			log.Error().Err(err).Msg("Background recalculation process failed")
+	c.startJob(ctx, constant.JobKindRecalculate, c.service.RecalculateRestaurantsRating, "Restaurant ratings recalculation started in background")
+}
+
+// RecalculateRestaurant godoc
+// @Summary Recalculate one restaurant
+// @Description Recalculates the review count, label ratings, rating and keywords of one restaurant right away and returns the updated restaurant
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Restaurant ID"
+// @Success 200 {object} model.Response{data=model.Restaurant}
+// @Failure 401 {object} model.Response
+// @Failure 404 {object} model.Response
+// @Failure 500 {object} model.Response
+// @Router /api/v1/admin/restaurants/{id}/recalculate [post]
+func (c *Controller) RecalculateRestaurant(ctx *gin.Context) {
+	log.Info().Msg("Recalculating restaurant")
+
+	id := ctx.Param("id")
+
+	restaurant, err := c.service.RecalculateRestaurant(id)
+	if err != nil {
+		if err.Error() == "not found" {
+			ctx.JSON(http.StatusNotFound, model.NewResponse("Restaurant not found", nil))
 		} else {
-			log.Info().Msg("Background recalculation process completed successfully")
+			ctx.JSON(http.StatusInternalServerError, model.NewResponse("Failed to recalculate restaurant", nil))
 		}
-	}()
+		return
+	}
 
-	ctx.JSON(http.StatusAccepted, model.NewResponse("Restaurant ratings recalculation started in background", nil))
+	log.Info().Msgf("Recalculation successful: Restaurant %s has rating %.2f from %d reviews", id, restaurant.Rating, restaurant.ReviewCount)
+	ctx.JSON(http.StatusOK, model.NewResponse("Restaurant recalculated successfully", restaurant))
 }
 
 // ExportRestaurantsToCSV godoc
 // @Summary Export restaurant ratings to CSV
-// @Description Exports restaurant ratings and review counts to a CSV file
+// @Description Starts a job exporting restaurant ratings and review counts to a CSV file.
+// @Description If an export is already running, that job is returned instead of starting another one.
 // @Tags restaurants
 // @Accept json
 // @Produce json
-// @Success 202 {object} model.Response
-// @Failure 500 {object} model.Response
+// @Success 202 {object} model.Response{data=model.Job}
+// @Failure 503 {object} model.Response
 // @Router /api/v1/export [post]
 func (c *Controller) ExportRestaurantsToCSV(ctx *gin.Context) {
 	log.Info().Msg("Starting export of restaurant ratings to CSV in background")
 
-	go func() {
-		err := c.service.ExportRestaurantsToCSV()
-		if err != nil {
-			log.Error().Err(err).Msg("Background export process failed")
-		} else {
-			log.Info().Msg("Background export process completed successfully")
-		}
-	}()
-	ctx.JSON(http.StatusAccepted, model.NewResponse("Restaurant ratings export started in background", nil))
+	c.startJob(ctx, constant.JobKindExport, c.service.ExportRestaurantsToCSV, "Restaurant ratings export started in background")
 }
@@ -0,0 +1,252 @@
+package controller
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"skeleton-internship-backend/internal/constant"
+	"skeleton-internship-backend/internal/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// AdminAuth only lets requests carrying the configured admin API key through,
+// as "Authorization: Bearer <key>". Admin endpoints are disabled when no key is configured.
+func (c *Controller) AdminAuth() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if c.cfg.Admin.APIKey == "" {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, model.NewResponse("Admin endpoints are disabled", nil))
+			return
+		}
+
+		if !c.isAdmin(ctx) {
+			log.Warn().Msgf("Rejected admin request to %s", ctx.Request.URL.Path)
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, model.NewResponse("Invalid admin API key", nil))
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// isAdmin reports whether the request carries the configured admin API key
+func (c *Controller) isAdmin(ctx *gin.Context) bool {
+	if c.cfg.Admin.APIKey == "" {
+		return false
+	}
+	token := strings.TrimPrefix(ctx.GetHeader("Authorization"), "Bearer ")
+	return subtle.ConstantTimeCompare([]byte(token), []byte(c.cfg.Admin.APIKey)) == 1
+}
+
+// DetectDuplicateReviews godoc
+// @Summary Detect duplicate reviews
+// @Description Fingerprints review texts to mark exact and near duplicates, and reviews posted by the same user on the same restaurant and day.
+// @Description Duplicates are excluded from review counts and ratings, which are recalculated once detection is done.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 202 {object} model.Response{data=model.Job}
+// @Failure 401 {object} model.Response
+// @Router /api/v1/admin/duplicates/detect [post]
+func (c *Controller) DetectDuplicateReviews(ctx *gin.Context) {
+	log.Info().Msg("Starting duplicate review detection in background")
+
+	c.startJob(ctx, constant.JobKindDetectDuplicates, c.service.DetectDuplicateReviews, "Duplicate review detection started in background")
+}
+
+// GetDuplicateClusters godoc
+// @Summary Get duplicate review clusters
+// @Description get the clusters found by the last duplicate detection, largest first. Each cluster holds the kept review and its duplicates.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param restaurant_id query string false "Restaurant ID" (optional)
+// @Param page query int false "Page number" default(1)
+// @Success 200 {object} model.Response{data=model.DuplicateReport}
+// @Failure 400 {object} model.Response
+// @Failure 401 {object} model.Response
+// @Failure 500 {object} model.Response
+// @Router /api/v1/admin/duplicates [get]
+func (c *Controller) GetDuplicateClusters(ctx *gin.Context) {
+	log.Info().Msg("Fetching duplicate review clusters")
+
+	restaurantID := ctx.Query("restaurant_id")
+	page, err := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		ctx.JSON(http.StatusBadRequest, model.NewResponse("Invalid page number", nil))
+		return
+	}
+
+	report, err := c.service.GetDuplicateClusters(restaurantID, page)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, model.NewResponse("Failed to fetch duplicate clusters", nil))
+		return
+	}
+
+	log.Info().Msgf("Fetching successful: Fetched %d duplicate clusters", len(report.Clusters))
+	ctx.JSON(http.StatusOK, model.NewResponse("Duplicate clusters fetched successfully", report))
+}
+
+// DetectSuspiciousReviews godoc
+// @Summary Detect suspicious reviews
+// @Description Looks for days where a restaurant received far more reviews than usual with an average far from its usual rating, and flags the reviews driving the burst.
+// @Description Flagged reviews are down-weighted or ignored in ratings depending on the configuration, and ratings are recalculated once detection is done.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 202 {object} model.Response{data=model.Job}
+// @Failure 401 {object} model.Response
+// @Router /api/v1/admin/suspicious/detect [post]
+func (c *Controller) DetectSuspiciousReviews(ctx *gin.Context) {
+	log.Info().Msg("Starting suspicious review detection in background")
+
+	c.startJob(ctx, constant.JobKindDetectSuspicious, c.service.DetectSuspiciousReviews, "Suspicious review detection started in background")
+}
+
+// GetSuspiciousWindows godoc
+// @Summary Get suspicious review windows
+// @Description get the review bursts found by the last detection, largest first, with their flagged reviews
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param restaurant_id query string false "Restaurant ID" (optional)
+// @Param page query int false "Page number" default(1)
+// @Success 200 {object} model.Response{data=model.SuspiciousReport}
+// @Failure 400 {object} model.Response
+// @Failure 401 {object} model.Response
+// @Failure 500 {object} model.Response
+// @Router /api/v1/admin/suspicious [get]
+func (c *Controller) GetSuspiciousWindows(ctx *gin.Context) {
+	log.Info().Msg("Fetching suspicious review windows")
+
+	restaurantID := ctx.Query("restaurant_id")
+	page, err := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		ctx.JSON(http.StatusBadRequest, model.NewResponse("Invalid page number", nil))
+		return
+	}
+
+	report, err := c.service.GetSuspiciousWindows(restaurantID, page)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, model.NewResponse("Failed to fetch suspicious windows", nil))
+		return
+	}
+
+	log.Info().Msgf("Fetching successful: Fetched %d suspicious windows", len(report.Windows))
+	ctx.JSON(http.StatusOK, model.NewResponse("Suspicious windows fetched successfully", report))
+}
+
+// LabelUnlabeledReviews godoc
+// @Summary Label unlabeled reviews
+// @Description Runs the configured labeler on the reviews without labels, in batches, so they count toward label ratings.
+// @Description Reviews no aspect is found in are labeled unknown with their own rating. Ratings are recalculated once labeling is done.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 202 {object} model.Response{data=model.Job}
+// @Failure 401 {object} model.Response
+// @Router /api/v1/admin/labels/run [post]
+func (c *Controller) LabelUnlabeledReviews(ctx *gin.Context) {
+	log.Info().Msg("Starting review labeling in background")
+
+	c.startJob(ctx, constant.JobKindLabelReviews, c.service.LabelUnlabeledReviews, "Review labeling started in background")
+}
+
+// GetModerationQueue godoc
+// @Summary Get the review moderation queue
+// @Description get reviews by moderation status, most reported first, with their unresolved reports per reason. Flagged reviews are returned by default.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param status query string false "Moderation status (flagged, hidden, visible)" default(flagged)
+// @Param page query int false "Page number" default(1)
+// @Success 200 {object} model.Response{data=model.ModerationQueue}
+// @Failure 400 {object} model.Response
+// @Failure 401 {object} model.Response
+// @Failure 500 {object} model.Response
+// @Router /api/v1/admin/moderation [get]
+func (c *Controller) GetModerationQueue(ctx *gin.Context) {
+	log.Info().Msg("Fetching moderation queue")
+
+	status := ctx.DefaultQuery("status", constant.ModerationFlagged)
+	if status != constant.ModerationFlagged && status != constant.ModerationHidden && status != constant.ModerationVisible {
+		ctx.JSON(http.StatusBadRequest, model.NewResponse("Invalid status. Must be one of: flagged, hidden, visible", nil))
+		return
+	}
+
+	page, err := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		ctx.JSON(http.StatusBadRequest, model.NewResponse("Invalid page number", nil))
+		return
+	}
+
+	queue, err := c.service.GetModerationQueue(status, page)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, model.NewResponse("Failed to fetch moderation queue", nil))
+		return
+	}
+
+	log.Info().Msgf("Fetching successful: Fetched %d reviews to moderate", len(queue.Items))
+	ctx.JSON(http.StatusOK, model.NewResponse("Moderation queue fetched successfully", queue))
+}
+
+// ApproveReview godoc
+// @Summary Approve a review
+// @Description make a reported or hidden review visible again and resolve its reports. Review counts and ratings are refreshed when a hidden review comes back.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Review (rating) ID"
+// @Success 200 {object} model.Response
+// @Failure 401 {object} model.Response
+// @Failure 404 {object} model.Response
+// @Failure 500 {object} model.Response
+// @Router /api/v1/admin/moderation/{id}/approve [post]
+func (c *Controller) ApproveReview(ctx *gin.Context) {
+	log.Info().Msg("Approving review")
+
+	c.moderateReview(ctx, ctx.Param("id"), c.service.ApproveReview, "approved")
+}
+
+// HideReview godoc
+// @Summary Hide a review
+// @Description hide a review from listings, review counts and ratings and resolve its reports. The restaurant's review count and rating are refreshed right away.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Review (rating) ID"
+// @Success 200 {object} model.Response
+// @Failure 401 {object} model.Response
+// @Failure 404 {object} model.Response
+// @Failure 500 {object} model.Response
+// @Router /api/v1/admin/moderation/{id}/hide [post]
+func (c *Controller) HideReview(ctx *gin.Context) {
+	log.Info().Msg("Hiding review")
+
+	c.moderateReview(ctx, ctx.Param("id"), c.service.HideReview, "hidden")
+}
+
+func (c *Controller) moderateReview(ctx *gin.Context, id string, moderate func(string) error, outcome string) {
+	if err := moderate(id); err != nil {
+		if err.Error() == "not found" {
+			ctx.JSON(http.StatusNotFound, model.NewResponse("Review not found", nil))
+		} else {
+			ctx.JSON(http.StatusInternalServerError, model.NewResponse("Failed to moderate review", nil))
+		}
+		return
+	}
+
+	log.Info().Msgf("Review %s %s", id, outcome)
+	ctx.JSON(http.StatusOK, model.NewResponse("Review "+outcome+" successfully", nil))
+}
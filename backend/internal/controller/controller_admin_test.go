@@ -0,0 +1,104 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"skeleton-internship-backend/config"
+	"skeleton-internship-backend/internal/constant"
+	"skeleton-internship-backend/internal/job"
+	"skeleton-internship-backend/internal/model"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/fx/fxtest"
+)
+
+func newAdminRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{}
+	cfg.Admin.APIKey = "secret"
+	router := gin.New()
+	NewController(&fakeService{}, cfg, nil, nil).RegisterRoutes(router)
+	return router
+}
+
+func TestAdminRoutesRequireKey(t *testing.T) {
+	router := newAdminRouter()
+
+	tests := []struct {
+		method string
+		path   string
+		want   int
+	}{
+		{method: http.MethodPost, path: "/api/v1/admin/recalculate", want: http.StatusUnauthorized},
+		{method: http.MethodPost, path: "/api/v1/admin/restaurants/1/recalculate", want: http.StatusUnauthorized},
+		// Moved under admin
+		{method: http.MethodPost, path: "/api/v1/recalculate", want: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("Authorization", "Bearer wrong")
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
+
+// getJob fetches a job with the given Authorization header
+func getJob(router *gin.Engine, id string, authorization string) (int, model.Job) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/jobs/"+id, nil)
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	var resp struct {
+		Data model.Job `json:"data"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	return rec.Code, resp.Data
+}
+
+func TestGetJobHidesDetailsFromPublicCallers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{}
+	cfg.Admin.APIKey = "secret"
+	jobs := job.NewManager(fxtest.NewLifecycle(t))
+	router := gin.New()
+	NewController(&fakeService{}, cfg, jobs, nil).RegisterRoutes(router)
+
+	started, _, err := jobs.Start(constant.JobKindDetectDuplicates, func(ctx context.Context) error {
+		job.ReportProgress(ctx, "detect", 3, 10)
+		return errors.New("Error 1146: table 'angi.Review_fingerprint' doesn't exist")
+	})
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	for jobs.Running(constant.JobKindDetectDuplicates) {
+		time.Sleep(time.Millisecond)
+	}
+
+	code, public := getJob(router, started.ID, "Bearer wrong")
+	if code != http.StatusOK || public.Status != constant.JobStatusFailed {
+		t.Fatalf("public GET = %d with status %q, want 200 with failed", code, public.Status)
+	}
+	if public.Error != "" || public.Progress != (model.JobProgress{}) {
+		t.Errorf("public job = %+v, want no error or progress", public)
+	}
+
+	_, admin := getJob(router, started.ID, "Bearer secret")
+	if admin.Error == "" || admin.Progress.Total != 10 {
+		t.Errorf("admin job = %+v, want the error and progress", admin)
+	}
+}
@@ -0,0 +1,105 @@
+package controller
+
+import (
+	"net/http"
+
+	"skeleton-internship-backend/internal/constant"
+	"skeleton-internship-backend/internal/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// DryRunRecalculation godoc
+// @Summary Dry-run a recalculation
+// @Description Starts a job computing new ratings and review counts of all restaurants into a staging table, replacing the previous dry run. Restaurants are not changed until the dry run is applied.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 202 {object} model.Response{data=model.Job}
+// @Failure 401 {object} model.Response
+// @Failure 503 {object} model.Response
+// @Router /api/v1/admin/recalculate/dry-run [post]
+func (c *Controller) DryRunRecalculation(ctx *gin.Context) {
+	log.Info().Msg("Starting dry-run recalculation in background")
+
+	c.startJob(ctx, constant.JobKindDryRun, c.service.DryRunRecalculation, "Dry-run recalculation started in background")
+}
+
+// GetDryRunSummary godoc
+// @Summary Get the dry-run summary
+// @Description get what applying the staged recalculation would change: restaurants changed, largest rating movers and rank changes in each district
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} model.Response{data=model.DryRunSummary}
+// @Failure 401 {object} model.Response
+// @Failure 404 {object} model.Response
+// @Failure 500 {object} model.Response
+// @Router /api/v1/admin/recalculate/dry-run [get]
+func (c *Controller) GetDryRunSummary(ctx *gin.Context) {
+	log.Info().Msg("Fetching dry-run summary")
+
+	summary, err := c.service.GetDryRunSummary()
+	if err != nil {
+		if err.Error() == "not found" {
+			ctx.JSON(http.StatusNotFound, model.NewResponse("No dry run staged", nil))
+		} else {
+			ctx.JSON(http.StatusInternalServerError, model.NewResponse("Failed to fetch dry-run summary", nil))
+		}
+		return
+	}
+
+	log.Info().Msgf("Fetching successful: %d of %d restaurants would change", summary.ChangedCount, summary.RestaurantCount)
+	ctx.JSON(http.StatusOK, model.NewResponse("Dry-run summary fetched successfully", summary))
+}
+
+// GetDryRunDiff godoc
+// @Summary Download the dry-run diff
+// @Description download, as CSV, every restaurant whose rating, review count or district rank would change with the staged recalculation
+// @Tags admin
+// @Produce text/csv
+// @Security Bearer
+// @Success 200 {file} file
+// @Failure 401 {object} model.Response
+// @Failure 404 {object} model.Response
+// @Failure 500 {object} model.Response
+// @Router /api/v1/admin/recalculate/dry-run/diff [get]
+func (c *Controller) GetDryRunDiff(ctx *gin.Context) {
+	log.Info().Msg("Building dry-run diff")
+
+	diff, err := c.service.GetDryRunDiff()
+	if err != nil {
+		if err.Error() == "not found" {
+			ctx.JSON(http.StatusNotFound, model.NewResponse("No dry run staged", nil))
+		} else {
+			ctx.JSON(http.StatusInternalServerError, model.NewResponse("Failed to build dry-run diff", nil))
+		}
+		return
+	}
+
+	ctx.Header("Content-Disposition", `attachment; filename="recalculation_diff.csv"`)
+	ctx.Data(http.StatusOK, "text/csv", diff)
+}
+
+// ApplyDryRun godoc
+// @Summary Apply the dry run
+// @Description Starts a job swapping the staged ratings, review counts and label stats in, in a single transaction, and clearing the staging table,
+// @Description then refreshing the rankings, platform calibration and keywords like a full recalculation.
+// @Description Restaurants that changed since the dry run keep their values and are left to the next incremental recalculation.
+// @Description The job result holds the applied and skipped restaurants (model.DryRunApplyResult). It takes the replica lock of the jobs writing ratings and fails when nothing is staged.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 202 {object} model.Response{data=model.Job}
+// @Failure 401 {object} model.Response
+// @Failure 503 {object} model.Response
+// @Router /api/v1/admin/recalculate/dry-run/apply [post]
+func (c *Controller) ApplyDryRun(ctx *gin.Context) {
+	log.Info().Msg("Starting dry-run apply in background")
+
+	c.startJob(ctx, constant.JobKindApplyDryRun, c.service.ApplyDryRun, "Dry-run apply started in background")
+}
@@ -0,0 +1,77 @@
+package controller
+
+import (
+	"net/http"
+
+	"skeleton-internship-backend/internal/job"
+	"skeleton-internship-backend/internal/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// startJob starts a background job and answers with it. When a job of the same kind
+// is already running, the client gets that job so concurrent requests coalesce. The job
+// takes the replica lock of its kind, the one scheduled runs take.
+func (c *Controller) startJob(ctx *gin.Context, kind string, run job.Func, message string) {
+	started, isNew, err := c.jobs.Start(kind, c.scheduler.Locked(kind, run))
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to start %s job", kind)
+		ctx.JSON(http.StatusServiceUnavailable, model.NewResponse("Failed to start job", nil))
+		return
+	}
+
+	if !isNew {
+		log.Info().Msgf("Job %s (%s) already running", started.ID, kind)
+		ctx.JSON(http.StatusAccepted, model.NewResponse("A job of this kind is already running", started))
+		return
+	}
+	ctx.JSON(http.StatusAccepted, model.NewResponse(message, started))
+}
+
+// GetJob godoc
+// @Summary Get a background job
+// @Description get the status and timestamps of a recalculation, export, detection or labeling job. Finished jobs are kept for 24 hours.
+// @Description The progress, error and result are only returned with the admin API key.
+// @Tags jobs
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Job ID"
+// @Success 200 {object} model.Response{data=model.Job}
+// @Failure 404 {object} model.Response
+// @Router /api/v1/jobs/{id} [get]
+func (c *Controller) GetJob(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	found, ok := c.jobs.Get(id)
+	if !ok {
+		ctx.JSON(http.StatusNotFound, model.NewResponse("Job not found", nil))
+		return
+	}
+
+	// Admin job errors and results can reveal internals
+	if !c.isAdmin(ctx) {
+		found = model.Job{
+			ID:         found.ID,
+			Kind:       found.Kind,
+			Status:     found.Status,
+			CreatedAt:  found.CreatedAt,
+			FinishedAt: found.FinishedAt,
+		}
+	}
+
+	ctx.JSON(http.StatusOK, model.NewResponse("Job fetched successfully", found))
+}
+
+// GetSchedules godoc
+// @Summary Get scheduled jobs
+// @Description get the cron schedules of the recalculation and export jobs, their next run and the outcome of their last run on this instance
+// @Tags jobs
+// @Accept json
+// @Produce json
+// @Success 200 {object} model.Response{data=[]model.ScheduleStatus}
+// @Router /api/v1/schedules [get]
+func (c *Controller) GetSchedules(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, model.NewResponse("Schedules fetched successfully", c.scheduler.Statuses()))
+}
@@ -0,0 +1,297 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"skeleton-internship-backend/internal/constant"
+	"skeleton-internship-backend/internal/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// GetRatingDistribution godoc
+// @Summary Get restaurant rating distribution
+// @Description get the number of reviews per star bucket overall, per label and per platform, with the mean and standard deviation of each
+// @Tags restaurants
+// @Accept json
+// @Produce json
+// @Param id path string true "Restaurant ID"
+// @Success 200 {object} model.Response{data=model.RatingDistribution}
+// @Failure 404 {object} model.Response
+// @Failure 500 {object} model.Response
+// @Router /api/v1/restaurants/{id}/ratings/distribution [get]
+func (c *Controller) GetRatingDistribution(ctx *gin.Context) {
+	log.Info().Msg("Fetching restaurant rating distribution")
+
+	id := ctx.Param("id")
+
+	distribution, err := c.service.GetRatingDistribution(id)
+	if err != nil {
+		if err.Error() == "not found" {
+			ctx.JSON(http.StatusNotFound, model.NewResponse("Restaurant not found", nil))
+		} else {
+			ctx.JSON(http.StatusInternalServerError, model.NewResponse("Failed to fetch rating distribution", nil))
+		}
+		return
+	}
+
+	log.Info().Msgf("Fetching successful: Rating distribution of %d reviews for restaurant ID: %s", distribution.Overall.Total, id)
+	ctx.JSON(http.StatusOK, model.NewResponse("Rating distribution fetched successfully", distribution))
+}
+
+// GetRatingTrend godoc
+// @Summary Get restaurant rating trend
+// @Description get the review volume and average rating, overall and per label, for each month or week
+// @Tags restaurants
+// @Accept json
+// @Produce json
+// @Param id path string true "Restaurant ID"
+// @Param granularity query string false "Bucket size (month, week)" default(month)
+// @Success 200 {object} model.Response{data=model.RatingTrend}
+// @Failure 400 {object} model.Response
+// @Failure 404 {object} model.Response
+// @Failure 500 {object} model.Response
+// @Router /api/v1/restaurants/{id}/ratings/trend [get]
+func (c *Controller) GetRatingTrend(ctx *gin.Context) {
+	log.Info().Msg("Fetching restaurant rating trend")
+
+	id := ctx.Param("id")
+	granularity := ctx.DefaultQuery("granularity", constant.TrendGranularityMonth)
+	if granularity != constant.TrendGranularityMonth && granularity != constant.TrendGranularityWeek {
+		ctx.JSON(http.StatusBadRequest, model.NewResponse("Invalid granularity. Must be one of: month, week", nil))
+		return
+	}
+
+	trend, err := c.service.GetRatingTrend(id, granularity)
+	if err != nil {
+		if err.Error() == "not found" {
+			ctx.JSON(http.StatusNotFound, model.NewResponse("Restaurant not found", nil))
+		} else {
+			ctx.JSON(http.StatusInternalServerError, model.NewResponse("Failed to fetch rating trend", nil))
+		}
+		return
+	}
+
+	log.Info().Msgf("Fetching successful: Rating trend with %d buckets for restaurant ID: %s", len(trend.Points), id)
+	ctx.JSON(http.StatusOK, model.NewResponse("Rating trend fetched successfully", trend))
+}
+
+// GetRestaurantLabels godoc
+// @Summary Get restaurant label ratings
+// @Description get the rating and review count of each label. With breakdown=platform, the label ratings are also computed from the reviews of each platform separately, with their review counts, to show where each score comes from.
+// @Tags restaurants
+// @Accept json
+// @Produce json
+// @Param id path string true "Restaurant ID"
+// @Param breakdown query string false "Break label ratings down by review platform (platform)" (optional)
+// @Success 200 {object} model.Response{data=model.RestaurantLabels}
+// @Failure 400 {object} model.Response
+// @Failure 404 {object} model.Response
+// @Failure 500 {object} model.Response
+// @Router /api/v1/restaurants/{id}/labels [get]
+func (c *Controller) GetRestaurantLabels(ctx *gin.Context) {
+	log.Info().Msg("Fetching restaurant label ratings")
+
+	id := ctx.Param("id")
+	breakdown := ctx.Query("breakdown")
+	if breakdown != "" && breakdown != constant.LabelBreakdownPlatform {
+		ctx.JSON(http.StatusBadRequest, model.NewResponse("Invalid breakdown. Must be: platform", nil))
+		return
+	}
+
+	labels, err := c.service.GetRestaurantLabels(id, breakdown == constant.LabelBreakdownPlatform)
+	if err != nil {
+		if err.Error() == "not found" {
+			ctx.JSON(http.StatusNotFound, model.NewResponse("Restaurant not found", nil))
+		} else {
+			ctx.JSON(http.StatusInternalServerError, model.NewResponse("Failed to fetch label ratings", nil))
+		}
+		return
+	}
+
+	log.Info().Msgf("Fetching successful: Label ratings for restaurant ID: %s from %d platforms", id, len(labels.ByPlatform))
+	ctx.JSON(http.StatusOK, model.NewResponse("Label ratings fetched successfully", labels))
+}
+
+// GetRestaurantKeywords godoc
+// @Summary Get restaurant keywords
+// @Description get the phrases most used by reviewers for each label, split by positive and negative label ratings. Keywords are refreshed on recalculation.
+// @Tags restaurants
+// @Accept json
+// @Produce json
+// @Param id path string true "Restaurant ID"
+// @Success 200 {object} model.Response{data=model.RestaurantKeywords}
+// @Failure 404 {object} model.Response
+// @Failure 500 {object} model.Response
+// @Router /api/v1/restaurants/{id}/keywords [get]
+func (c *Controller) GetRestaurantKeywords(ctx *gin.Context) {
+	log.Info().Msg("Fetching restaurant keywords")
+
+	id := ctx.Param("id")
+
+	keywords, err := c.service.GetRestaurantKeywords(id)
+	if err != nil {
+		if err.Error() == "not found" {
+			ctx.JSON(http.StatusNotFound, model.NewResponse("Restaurant not found", nil))
+		} else {
+			ctx.JSON(http.StatusInternalServerError, model.NewResponse("Failed to fetch keywords", nil))
+		}
+		return
+	}
+
+	log.Info().Msgf("Fetching successful: Keywords for %d labels of restaurant ID: %s", len(keywords.Labels), id)
+	ctx.JSON(http.StatusOK, model.NewResponse("Keywords fetched successfully", keywords))
+}
+
+// GetRatingHistory godoc
+// @Summary Get restaurant rating history
+// @Description get the rating, review count and label ratings of a restaurant after each full recalculation, newest first,
+// @Description with the rating settings they were computed with. Single-restaurant, incremental and moderation
+// @Description recalculations are not snapshotted, so the current rating may differ from the latest snapshot.
+// @Tags restaurants
+// @Accept json
+// @Produce json
+// @Param id path string true "Restaurant ID"
+// @Param page query int false "Page number" default(1)
+// @Success 200 {object} model.Response{data=model.RatingHistory}
+// @Failure 400 {object} model.Response
+// @Failure 404 {object} model.Response
+// @Failure 500 {object} model.Response
+// @Router /api/v1/restaurants/{id}/history [get]
+func (c *Controller) GetRatingHistory(ctx *gin.Context) {
+	log.Info().Msg("Fetching restaurant rating history")
+
+	id := ctx.Param("id")
+	page, err := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		ctx.JSON(http.StatusBadRequest, model.NewResponse("Invalid page number", nil))
+		return
+	}
+
+	history, err := c.service.GetRatingHistory(id, page)
+	if err != nil {
+		if err.Error() == "not found" {
+			ctx.JSON(http.StatusNotFound, model.NewResponse("Restaurant not found", nil))
+		} else {
+			ctx.JSON(http.StatusInternalServerError, model.NewResponse("Failed to fetch rating history", nil))
+		}
+		return
+	}
+
+	log.Info().Msgf("Fetching successful: %d rating snapshots for restaurant ID: %s", len(history.Snapshots), id)
+	ctx.JSON(http.StatusOK, model.NewResponse("Rating history fetched successfully", history))
+}
+
+// GetRatingMovers godoc
+// @Summary Get the biggest rating movers
+// @Description get the restaurants whose rating changed the most between the snapshots of two recalculation versions.
+// @Description By default the latest recalculation is compared with the one before it.
+// @Tags restaurants
+// @Accept json
+// @Produce json
+// @Param from query int false "Recalculation version to compare from" (optional)
+// @Param to query int false "Recalculation version to compare to" (optional)
+// @Param limit query int false "Number of restaurants (max 100)" default(20)
+// @Success 200 {object} model.Response{data=model.RatingMovers}
+// @Failure 400 {object} model.Response
+// @Failure 404 {object} model.Response
+// @Failure 500 {object} model.Response
+// @Router /api/v1/restaurants/movers [get]
+func (c *Controller) GetRatingMovers(ctx *gin.Context) {
+	log.Info().Msg("Fetching rating movers")
+
+	fromVersion, err := strconv.Atoi(ctx.DefaultQuery("from", "0"))
+	if err != nil || fromVersion < 0 {
+		ctx.JSON(http.StatusBadRequest, model.NewResponse("Invalid from version", nil))
+		return
+	}
+	toVersion, err := strconv.Atoi(ctx.DefaultQuery("to", "0"))
+	if err != nil || toVersion < 0 {
+		ctx.JSON(http.StatusBadRequest, model.NewResponse("Invalid to version", nil))
+		return
+	}
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", strconv.Itoa(constant.RatingMoversDefaultLimit)))
+	if err != nil || limit < 1 || limit > constant.RatingMoversMaxLimit {
+		ctx.JSON(http.StatusBadRequest, model.NewResponse("Invalid limit. Must be between 1 and 100", nil))
+		return
+	}
+
+	movers, err := c.service.GetRatingMovers(fromVersion, toVersion, limit)
+	if err != nil {
+		if err.Error() == "not found" {
+			ctx.JSON(http.StatusNotFound, model.NewResponse("Snapshots not found", nil))
+		} else {
+			ctx.JSON(http.StatusInternalServerError, model.NewResponse("Failed to fetch rating movers", nil))
+		}
+		return
+	}
+
+	log.Info().Msgf("Fetching successful: %d rating movers from version %d to %d", len(movers.Movers), movers.FromVersion, movers.ToVersion)
+	ctx.JSON(http.StatusOK, model.NewResponse("Rating movers fetched successfully", movers))
+}
+
+// GetRankings godoc
+// @Summary Get restaurant rankings
+// @Description get the top restaurants of a city, a district or the whole catalog, optionally of one food type,
+// @Description by overall rating or by a label rating. Rankings are rebuilt by every recalculation, and each
+// @Description restaurant comes with its rank movement since the previous one. When a district is given the city is ignored.
+// @Tags rankings
+// @Accept json
+// @Produce json
+// @Param city query int false "City ID" (optional)
+// @Param district query int false "District ID" (optional)
+// @Param foodtype query string false "Food type name" (optional)
+// @Param label query string false "Rating to rank by: overall, ambience, delivery, food, price, service" default(overall)
+// @Param limit query int false "Number of restaurants (max 100)" default(10)
+// @Success 200 {object} model.Response{data=model.Ranking}
+// @Failure 400 {object} model.Response
+// @Failure 404 {object} model.Response
+// @Failure 500 {object} model.Response
+// @Router /api/v1/rankings [get]
+func (c *Controller) GetRankings(ctx *gin.Context) {
+	log.Info().Msg("Fetching rankings")
+
+	cityID, err := strconv.Atoi(ctx.DefaultQuery("city", "0"))
+	if err != nil || cityID < 0 {
+		ctx.JSON(http.StatusBadRequest, model.NewResponse("Invalid city ID", nil))
+		return
+	}
+	districtID, err := strconv.Atoi(ctx.DefaultQuery("district", "0"))
+	if err != nil || districtID < 0 {
+		ctx.JSON(http.StatusBadRequest, model.NewResponse("Invalid district ID", nil))
+		return
+	}
+	foodType := ctx.Query("foodtype")
+
+	label := strings.ToLower(ctx.DefaultQuery("label", constant.RankingLabelOverall))
+	validLabels := map[string]bool{constant.RankingLabelOverall: true}
+	for _, aspect := range constant.AspectLabels {
+		validLabels[aspect] = true
+	}
+	if !validLabels[label] {
+		ctx.JSON(http.StatusBadRequest, model.NewResponse("Invalid label. Must be one of: overall, ambience, delivery, food, price, service", nil))
+		return
+	}
+
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", strconv.Itoa(constant.RankingDefaultLimit)))
+	if err != nil || limit < 1 || limit > constant.RankingSize {
+		ctx.JSON(http.StatusBadRequest, model.NewResponse("Invalid limit. Must be between 1 and 100", nil))
+		return
+	}
+
+	ranking, err := c.service.GetRanking(cityID, districtID, foodType, label, limit)
+	if err != nil {
+		if err.Error() == "not found" {
+			ctx.JSON(http.StatusNotFound, model.NewResponse("Food type not found", nil))
+		} else {
+			ctx.JSON(http.StatusInternalServerError, model.NewResponse("Failed to fetch rankings", nil))
+		}
+		return
+	}
+
+	log.Info().Msgf("Fetching successful: %d ranked restaurants by %s", len(ranking.Restaurants), label)
+	ctx.JSON(http.StatusOK, model.NewResponse("Rankings fetched successfully", ranking))
+}
@@ -0,0 +1,228 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"skeleton-internship-backend/internal/constant"
+	"skeleton-internship-backend/internal/dto"
+	"skeleton-internship-backend/internal/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// CreateRestaurantReview godoc
+// @Summary Create a review
+// @Description create a review for a restaurant together with its aspect ratings
+// @Description A user can only review a restaurant once. The restaurant review count and rating are updated immediately.
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Param id path string true "Restaurant ID"
+// @Param review body dto.ReviewCreate true "Review to create"
+// @Success 201 {object} model.Response{data=model.Review}
+// @Failure 400 {object} model.Response
+// @Failure 404 {object} model.Response
+// @Failure 409 {object} model.Response
+// @Failure 500 {object} model.Response
+// @Router /api/v1/restaurants/{id}/reviews [post]
+func (c *Controller) CreateRestaurantReview(ctx *gin.Context) {
+	log.Info().Msg("Creating restaurant review")
+
+	id := ctx.Param("id")
+
+	var review dto.ReviewCreate
+	if err := ctx.ShouldBindJSON(&review); err != nil {
+		ctx.JSON(http.StatusBadRequest, model.NewResponse("Invalid review body", nil))
+		return
+	}
+
+	if message := validateReview(review); message != "" {
+		ctx.JSON(http.StatusBadRequest, model.NewResponse(message, nil))
+		return
+	}
+
+	created, err := c.service.CreateReview(id, review)
+	if err != nil {
+		switch err.Error() {
+		case "not found":
+			ctx.JSON(http.StatusNotFound, model.NewResponse("Restaurant not found", nil))
+		case "user not found":
+			ctx.JSON(http.StatusNotFound, model.NewResponse("User not found", nil))
+		case "already reviewed":
+			ctx.JSON(http.StatusConflict, model.NewResponse("User has already reviewed this restaurant", nil))
+		default:
+			ctx.JSON(http.StatusInternalServerError, model.NewResponse("Failed to create review", nil))
+		}
+		return
+	}
+
+	log.Info().Msgf("Review %s created for restaurant ID: %s", created.RatingID, id)
+	ctx.JSON(http.StatusCreated, model.NewResponse("Review created successfully", created))
+}
+
+// validateReview checks a review body and returns an error message, or an empty string if it is valid
+func validateReview(review dto.ReviewCreate) string {
+	if review.Rating < constant.ReviewMinRating || review.Rating > constant.ReviewMaxRating {
+		return fmt.Sprintf("Rating must be between %d and %d", constant.ReviewMinRating, constant.ReviewMaxRating)
+	}
+
+	if utf8.RuneCountInString(strings.TrimSpace(review.Feedback)) > constant.ReviewMaxFeedbackLength {
+		return fmt.Sprintf("Feedback must be at most %d characters", constant.ReviewMaxFeedbackLength)
+	}
+
+	validLabels := map[string]bool{constant.LabelUnknown: true}
+	for _, label := range constant.AspectLabels {
+		validLabels[label] = true
+	}
+
+	seen := map[string]bool{}
+	for _, label := range review.Labels {
+		name := strings.ToLower(label.Label)
+		if !validLabels[name] {
+			return "Invalid label. Must be one of: ambience, delivery, food, price, service, unknown"
+		}
+		if seen[name] {
+			return "Each label can only be rated once"
+		}
+		seen[name] = true
+
+		if label.RatingLabel < constant.ReviewMinRating || label.RatingLabel > constant.ReviewMaxRating {
+			return fmt.Sprintf("Label rating must be between %d and %d", constant.ReviewMinRating, constant.ReviewMaxRating)
+		}
+	}
+
+	return ""
+}
+
+// clientIdentity identifies the client casting a vote or a report by its IP. Forwarding
+// headers only count when sent by a trusted proxy (SERVER_TRUSTED_PROXIES), a client
+// could change them to vote again.
+func clientIdentity(ctx *gin.Context) string {
+	return "ip:" + ctx.ClientIP()
+}
+
+// VoteReview godoc
+// @Summary Vote on a review
+// @Description mark a review as helpful or unhelpful. Each client has one vote per review, voting again replaces it.
+// @Description Clients are identified by their IP address. Hidden reviews cannot be voted on.
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Param id path string true "Review (rating) ID"
+// @Param vote body dto.ReviewVote true "Vote"
+// @Success 200 {object} model.Response{data=model.ReviewVotes}
+// @Failure 400 {object} model.Response
+// @Failure 404 {object} model.Response
+// @Failure 500 {object} model.Response
+// @Router /api/v1/reviews/{id}/vote [post]
+func (c *Controller) VoteReview(ctx *gin.Context) {
+	log.Info().Msg("Voting on review")
+
+	id := ctx.Param("id")
+
+	var vote dto.ReviewVote
+	if err := ctx.ShouldBindJSON(&vote); err != nil {
+		ctx.JSON(http.StatusBadRequest, model.NewResponse("Invalid vote body", nil))
+		return
+	}
+
+	c.saveReviewVote(ctx, id, vote.Helpful)
+}
+
+// DeleteReviewVote godoc
+// @Summary Remove a vote on a review
+// @Description remove the helpful or unhelpful vote of the current client on a review
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Param id path string true "Review (rating) ID"
+// @Success 200 {object} model.Response{data=model.ReviewVotes}
+// @Failure 404 {object} model.Response
+// @Failure 500 {object} model.Response
+// @Router /api/v1/reviews/{id}/vote [delete]
+func (c *Controller) DeleteReviewVote(ctx *gin.Context) {
+	log.Info().Msg("Removing vote on review")
+
+	c.saveReviewVote(ctx, ctx.Param("id"), nil)
+}
+
+func (c *Controller) saveReviewVote(ctx *gin.Context, id string, helpful *bool) {
+	votes, err := c.service.VoteReview(id, clientIdentity(ctx), helpful)
+	if err != nil {
+		if err.Error() == "not found" {
+			ctx.JSON(http.StatusNotFound, model.NewResponse("Review not found", nil))
+		} else {
+			ctx.JSON(http.StatusInternalServerError, model.NewResponse("Failed to save vote", nil))
+		}
+		return
+	}
+
+	log.Info().Msgf("Vote saved on review %s: %s", id, votes.Vote)
+	ctx.JSON(http.StatusOK, model.NewResponse("Vote saved successfully", votes))
+}
+
+// ReportReview godoc
+// @Summary Report a review
+// @Description report a review as spam, offensive, fake or off topic. Reported reviews are queued for moderation and stay visible until a moderator hides them.
+// @Description Each client can report a review once. Clients are identified by their IP address.
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Param id path string true "Review (rating) ID"
+// @Param report body dto.ReviewReport true "Report"
+// @Success 201 {object} model.Response
+// @Failure 400 {object} model.Response
+// @Failure 404 {object} model.Response
+// @Failure 409 {object} model.Response
+// @Failure 500 {object} model.Response
+// @Router /api/v1/reviews/{id}/report [post]
+func (c *Controller) ReportReview(ctx *gin.Context) {
+	log.Info().Msg("Reporting review")
+
+	id := ctx.Param("id")
+
+	var report dto.ReviewReport
+	if err := ctx.ShouldBindJSON(&report); err != nil {
+		ctx.JSON(http.StatusBadRequest, model.NewResponse("Invalid report body", nil))
+		return
+	}
+
+	report.Reason = strings.ToLower(strings.TrimSpace(report.Reason))
+	validReason := false
+	for _, reason := range constant.ReportReasons {
+		if report.Reason == reason {
+			validReason = true
+			break
+		}
+	}
+	if !validReason {
+		ctx.JSON(http.StatusBadRequest, model.NewResponse("Invalid reason. Must be one of: "+strings.Join(constant.ReportReasons, ", "), nil))
+		return
+	}
+
+	report.Details = strings.TrimSpace(report.Details)
+	if utf8.RuneCountInString(report.Details) > constant.ReportMaxDetailsLength {
+		ctx.JSON(http.StatusBadRequest, model.NewResponse(fmt.Sprintf("Details must be at most %d characters", constant.ReportMaxDetailsLength), nil))
+		return
+	}
+
+	err := c.service.ReportReview(id, clientIdentity(ctx), report)
+	if err != nil {
+		switch err.Error() {
+		case "not found":
+			ctx.JSON(http.StatusNotFound, model.NewResponse("Review not found", nil))
+		case "already reported":
+			ctx.JSON(http.StatusConflict, model.NewResponse("You have already reported this review", nil))
+		default:
+			ctx.JSON(http.StatusInternalServerError, model.NewResponse("Failed to report review", nil))
+		}
+		return
+	}
+
+	log.Info().Msgf("Review %s reported as %s", id, report.Reason)
+	ctx.JSON(http.StatusCreated, model.NewResponse("Review reported successfully", nil))
+}
@@ -0,0 +1,79 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"skeleton-internship-backend/config"
+	"skeleton-internship-backend/internal/model"
+	"skeleton-internship-backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// fakeService records the clients voting. Methods it does not override panic through
+// the nil embedded Service.
+type fakeService struct {
+	service.Service
+
+	voters []string
+}
+
+func (f *fakeService) VoteReview(ratingID string, clientID string, helpful *bool) (*model.ReviewVotes, error) {
+	f.voters = append(f.voters, clientID)
+	return &model.ReviewVotes{RatingID: ratingID}, nil
+}
+
+// vote posts a vote from remoteAddr with the given forwarding header
+func vote(router *gin.Engine, remoteAddr string, forwardedFor string) int {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/reviews/1/vote", strings.NewReader(`{"helpful": true}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = remoteAddr
+	if forwardedFor != "" {
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func newVoteRouter(trustedProxies []string) (*gin.Engine, *fakeService) {
+	gin.SetMode(gin.TestMode)
+	svc := &fakeService{}
+	cfg := &config.Config{}
+	cfg.Server.TrustedProxies = trustedProxies
+	router := gin.New()
+	NewController(svc, cfg, nil, nil).RegisterRoutes(router)
+	return router, svc
+}
+
+func TestVoteIgnoresSpoofedForwardedFor(t *testing.T) {
+	router, svc := newVoteRouter(nil)
+
+	for _, forwardedFor := range []string{"", "1.2.3.4", "5.6.7.8", "9.9.9.9, 1.2.3.4"} {
+		if code := vote(router, "203.0.113.7:4000", forwardedFor); code != http.StatusOK {
+			t.Fatalf("vote with X-Forwarded-For %q: status %d, want 200", forwardedFor, code)
+		}
+	}
+
+	for _, voter := range svc.voters {
+		if voter != "ip:203.0.113.7" {
+			t.Errorf("voter = %q, want ip:203.0.113.7 whatever the X-Forwarded-For", voter)
+		}
+	}
+}
+
+func TestVoteTrustsConfiguredProxy(t *testing.T) {
+	router, svc := newVoteRouter([]string{"10.0.0.0/8"})
+
+	vote(router, "10.0.0.2:4000", "198.51.100.9")
+	// Not a configured proxy
+	vote(router, "203.0.113.7:4000", "198.51.100.9")
+
+	want := []string{"ip:198.51.100.9", "ip:203.0.113.7"}
+	if len(svc.voters) != len(want) || svc.voters[0] != want[0] || svc.voters[1] != want[1] {
+		t.Errorf("voters = %v, want %v", svc.voters, want)
+	}
+}
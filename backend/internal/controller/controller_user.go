@@ -0,0 +1,82 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"skeleton-internship-backend/internal/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// GetUserProfile godoc
+// @Summary Get a reviewer profile
+// @Description get a user with their platform, review count, average rating given and label breakdown
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} model.Response{data=model.UserProfile}
+// @Failure 404 {object} model.Response
+// @Failure 500 {object} model.Response
+// @Router /api/v1/users/{id} [get]
+func (c *Controller) GetUserProfile(ctx *gin.Context) {
+	log.Info().Msg("Fetching user profile by ID")
+
+	id := ctx.Param("id")
+
+	user, err := c.service.GetUserProfile(id)
+	if err != nil {
+		if err.Error() == "not found" {
+			ctx.JSON(http.StatusNotFound, model.NewResponse("User not found", nil))
+		} else {
+			ctx.JSON(http.StatusInternalServerError, model.NewResponse("Failed to fetch user profile", nil))
+		}
+		return
+	}
+
+	log.Info().Msgf("Fetching successful: User found with ID: %s", id)
+	ctx.JSON(http.StatusOK, model.NewResponse("User profile fetched successfully", user))
+}
+
+// GetUserReviews godoc
+// @Summary Get reviews of a user
+// @Description get the reviews written by a user, newest first, with a summary of each reviewed restaurant
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param page query int false "Page number" default(1)
+// @Param count query boolean false "Whether to count total reviews" default(true)
+// @Success 200 {object} model.Response{data=model.UserReviewResponse}
+// @Failure 400 {object} model.Response
+// @Failure 404 {object} model.Response
+// @Failure 500 {object} model.Response
+// @Router /api/v1/users/{id}/reviews [get]
+func (c *Controller) GetUserReviews(ctx *gin.Context) {
+	log.Info().Msg("Fetching user reviews")
+
+	id := ctx.Param("id")
+
+	// Extract and validate page parameter
+	page, err := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		ctx.JSON(http.StatusBadRequest, model.NewResponse("Invalid page number", nil))
+		return
+	}
+	isCount := ctx.DefaultQuery("count", "true") == "true"
+
+	reviewResponse, err := c.service.GetUserReviews(id, page, isCount)
+	if err != nil {
+		if err.Error() == "not found" {
+			ctx.JSON(http.StatusNotFound, model.NewResponse("User not found", nil))
+		} else {
+			ctx.JSON(http.StatusInternalServerError, model.NewResponse("Failed to fetch user reviews", nil))
+		}
+		return
+	}
+
+	log.Info().Msgf("Fetching successful: Fetched %d reviews for user ID: %s", len(reviewResponse.Reviews), id)
+	ctx.JSON(http.StatusOK, model.NewResponse("User reviews fetched successfully", reviewResponse))
+}
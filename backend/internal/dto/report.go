@@ -0,0 +1,11 @@
+package dto
+
+// ReviewReport represents the data structure for reporting a review
+// @Description Review report request body
+type ReviewReport struct {
+	// Reason of the report (spam, offensive, fake, off_topic, other)
+	Reason string `json:"reason" example:"offensive" binding:"required"`
+
+	// Optional explanation of the report
+	Details string `json:"details" example:"Contains insults toward the staff"`
+}
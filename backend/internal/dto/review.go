@@ -0,0 +1,26 @@
+package dto
+
+// ReviewCreate represents the data structure for creating a new review
+// @Description Review creation request body
+type ReviewCreate struct {
+	// ID of the user writing the review
+	UserID string `json:"user_id" example:"15" binding:"required"`
+
+	// Overall rating of the review (1 to 5)
+	Rating float64 `json:"rating" example:"4.5" binding:"required"`
+
+	// Text of the review
+	Feedback string `json:"feedback" example:"Nước dùng đậm đà, phục vụ nhanh"`
+
+	// Ratings given to individual aspects of the restaurant
+	Labels []ReviewLabelCreate `json:"labels"`
+}
+
+// ReviewLabelCreate represents the rating given to one aspect in a new review
+type ReviewLabelCreate struct {
+	// Aspect of the restaurant (ambience, delivery, food, price, service, unknown)
+	Label string `json:"label" example:"food" binding:"required"`
+
+	// Rating given to the aspect (1 to 5)
+	RatingLabel float64 `json:"rating_label" example:"4.5" binding:"required"`
+}
@@ -0,0 +1,8 @@
+package dto
+
+// ReviewVote represents the data structure for voting on a review
+// @Description Review vote request body
+type ReviewVote struct {
+	// Whether the review was helpful
+	Helpful *bool `json:"helpful" example:"true" binding:"required"`
+}
@@ -0,0 +1,207 @@
+package job
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"skeleton-internship-backend/internal/constant"
+	"skeleton-internship-backend/internal/model"
+
+	"github.com/rs/zerolog/log"
+	"go.uber.org/fx"
+)
+
+// Func is the work of a job. It should stop early and return ctx.Err() once ctx is cancelled.
+type Func func(ctx context.Context) error
+
+type entry struct {
+	job      model.Job
+	finished time.Time
+}
+
+// Manager runs background jobs, at most one per kind at a time, and keeps their
+// status in memory so clients can poll them. Running jobs are cancelled and
+// drained when the application stops.
+type Manager struct {
+	mu      sync.Mutex
+	jobs    map[string]*entry
+	running map[string]string
+	closed  bool
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+func NewManager(lifecycle fx.Lifecycle) *Manager {
+	ctx, cancel := context.WithCancel(context.Background())
+	m := &Manager{
+		jobs:    map[string]*entry{},
+		running: map[string]string{},
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+	lifecycle.Append(fx.Hook{
+		OnStop: m.Shutdown,
+	})
+	return m
+}
+
+type progressKey struct{}
+
+type resultKey struct{}
+
+// ReportProgress records the progress of the job running with ctx, if any
+func ReportProgress(ctx context.Context, step string, done, total int) {
+	if report, ok := ctx.Value(progressKey{}).(func(model.JobProgress)); ok {
+		report(model.JobProgress{Step: step, Done: done, Total: total})
+	}
+}
+
+// ReportResult records the outcome of the job running with ctx, if any
+func ReportResult(ctx context.Context, result any) {
+	if report, ok := ctx.Value(resultKey{}).(func(any)); ok {
+		report(result)
+	}
+}
+
+func newJobID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Start runs fn in the background as a job of the given kind. When a job of that kind
+// is already running, that job is returned instead and started is false.
+func (m *Manager) Start(kind string, fn Func) (job model.Job, started bool, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed {
+		return model.Job{}, false, errors.New("shutting down")
+	}
+	if id, ok := m.running[kind]; ok {
+		return m.jobs[id].job, false, nil
+	}
+	m.prune()
+
+	id, err := newJobID()
+	if err != nil {
+		return model.Job{}, false, err
+	}
+	e := &entry{job: model.Job{
+		ID:        id,
+		Kind:      kind,
+		Status:    constant.JobStatusRunning,
+		CreatedAt: time.Now().Format(time.RFC3339),
+	}}
+	m.jobs[id] = e
+	m.running[kind] = id
+
+	ctx := context.WithValue(m.ctx, progressKey{}, func(progress model.JobProgress) {
+		m.mu.Lock()
+		e.job.Progress = progress
+		m.mu.Unlock()
+	})
+	ctx = context.WithValue(ctx, resultKey{}, func(result any) {
+		m.mu.Lock()
+		e.job.Result = result
+		m.mu.Unlock()
+	})
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		log.Info().Msgf("Job %s (%s) started", id, kind)
+		err := run(ctx, fn)
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		delete(m.running, kind)
+		e.finished = time.Now()
+		e.job.FinishedAt = e.finished.Format(time.RFC3339)
+		switch {
+		case err == nil:
+			e.job.Status = constant.JobStatusSucceeded
+			log.Info().Msgf("Job %s (%s) succeeded", id, kind)
+		case errors.Is(err, context.Canceled):
+			e.job.Status = constant.JobStatusCancelled
+			log.Warn().Msgf("Job %s (%s) cancelled", id, kind)
+		default:
+			e.job.Status = constant.JobStatusFailed
+			e.job.Error = err.Error()
+			log.Error().Err(err).Msgf("Job %s (%s) failed", id, kind)
+		}
+	}()
+
+	return e.job, true, nil
+}
+
+// run calls fn, turning a panic into an error so it fails the job rather than the process
+func run(ctx context.Context, fn Func) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return fn(ctx)
+}
+
+// Get returns the job with the given ID
+func (m *Manager) Get(id string) (model.Job, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, ok := m.jobs[id]
+	if !ok {
+		return model.Job{}, false
+	}
+	return e.job, true
+}
+
+// Running reports whether a job of the given kind is running
+func (m *Manager) Running(kind string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, ok := m.running[kind]
+	return ok
+}
+
+// prune forgets the jobs finished longer than the retention ago, m.mu must be held
+func (m *Manager) prune() {
+	for id, e := range m.jobs {
+		if !e.finished.IsZero() && time.Since(e.finished) > constant.JobRetention {
+			delete(m.jobs, id)
+		}
+	}
+}
+
+// Shutdown cancels the running jobs and waits for them to return, or for ctx to expire
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	m.closed = true
+	m.mu.Unlock()
+
+	log.Info().Msg("Cancelling running jobs")
+	m.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		log.Warn().Msg("Timed out waiting for running jobs to stop")
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,143 @@
+package job
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"skeleton-internship-backend/internal/constant"
+	"skeleton-internship-backend/internal/model"
+
+	"go.uber.org/fx/fxtest"
+)
+
+// wait waits for the job with the given ID to finish
+func wait(t *testing.T, m *Manager, id string) model.Job {
+	t.Helper()
+	for i := 0; i < 1000; i++ {
+		found, _ := m.Get(id)
+		if found.Status != constant.JobStatusRunning {
+			return found
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("job %s did not finish", id)
+	return model.Job{}
+}
+
+func TestPanickingJobFails(t *testing.T) {
+	m := NewManager(fxtest.NewLifecycle(t))
+
+	started, _, err := m.Start("test", func(context.Context) error {
+		panic("boom")
+	})
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	finished := wait(t, m, started.ID)
+	if finished.Status != constant.JobStatusFailed || !strings.Contains(finished.Error, "boom") {
+		t.Errorf("job = %s with error %q, want failed with the panic", finished.Status, finished.Error)
+	}
+	if m.Running("test") {
+		t.Error("panicked job still running")
+	}
+}
+
+func TestStartCoalescesJobsOfAKind(t *testing.T) {
+	m := NewManager(fxtest.NewLifecycle(t))
+
+	block := make(chan struct{})
+	first, started, err := m.Start("test", func(context.Context) error {
+		<-block
+		return nil
+	})
+	if err != nil || !started {
+		t.Fatalf("Start() = %v, %v, want a new job", started, err)
+	}
+	if !m.Running("test") {
+		t.Error("Running() = false while the job runs")
+	}
+
+	ran := false
+	second, started, err := m.Start("test", func(context.Context) error {
+		ran = true
+		return nil
+	})
+	if err != nil || started || second.ID != first.ID {
+		t.Errorf("second Start() = %s, %v, %v, want the running job %s", second.ID, started, err, first.ID)
+	}
+	// Other kinds run alongside
+	other, started, err := m.Start("other", func(context.Context) error { return nil })
+	if err != nil || !started || other.ID == first.ID {
+		t.Errorf("Start() of another kind = %s, %v, %v, want a new job", other.ID, started, err)
+	}
+
+	close(block)
+	if finished := wait(t, m, first.ID); finished.Status != constant.JobStatusSucceeded {
+		t.Errorf("job = %s, want succeeded", finished.Status)
+	}
+	if m.Running("test") {
+		t.Error("Running() = true after the job finished")
+	}
+	if ran {
+		t.Error("coalesced job ran")
+	}
+
+	third, started, err := m.Start("test", func(context.Context) error { return nil })
+	if err != nil || !started || third.ID == first.ID {
+		t.Errorf("Start() after the job finished = %s, %v, %v, want a new job", third.ID, started, err)
+	}
+}
+
+func TestShutdownDrainsRunningJobs(t *testing.T) {
+	m := NewManager(fxtest.NewLifecycle(t))
+
+	running := make(chan struct{})
+	stopped := false
+	started, _, err := m.Start("test", func(ctx context.Context) error {
+		close(running)
+		<-ctx.Done()
+		time.Sleep(10 * time.Millisecond)
+		stopped = true
+		return ctx.Err()
+	})
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	<-running
+
+	if err := m.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if !stopped {
+		t.Error("Shutdown() returned before the job stopped")
+	}
+	if found, _ := m.Get(started.ID); found.Status != constant.JobStatusCancelled {
+		t.Errorf("job = %s, want cancelled", found.Status)
+	}
+	if _, _, err := m.Start("test", func(context.Context) error { return nil }); err == nil {
+		t.Error("Start() after Shutdown() succeeded")
+	}
+}
+
+func TestShutdownTimesOut(t *testing.T) {
+	m := NewManager(fxtest.NewLifecycle(t))
+
+	block := make(chan struct{})
+	defer close(block)
+	if _, _, err := m.Start("test", func(context.Context) error {
+		// Ignores cancellation
+		<-block
+		return nil
+	}); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := m.Shutdown(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Shutdown() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
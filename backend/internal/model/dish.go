@@ -4,7 +4,32 @@ package model
 // @Description This struct is used to represent a dish in the system
 type Dish struct {
 	// Name of the dish
-	Name  string  `json:"name"`
+	Name string `json:"name"`
 	// Price of the dish
 	Price float64 `json:"price"`
-}
\ No newline at end of file
+	// Category the dish belongs to
+	CategoryID int `json:"category_id"`
+	// Name of the category the dish belongs to
+	CategoryName string `json:"category_name"`
+}
+
+// MenuCategory represents a group of dishes sharing the same category
+// @Description This struct is used to represent a category of a restaurant menu
+type MenuCategory struct {
+	// Unique identifier of the category (0 for uncategorized dishes)
+	CategoryID int `json:"category_id"`
+	// Name of the category
+	CategoryName string `json:"category_name"`
+	// Lowest dish price in the category
+	MinPrice float64 `json:"min_price"`
+	// Highest dish price in the category
+	MaxPrice float64 `json:"max_price"`
+	// Dishes in the category
+	Dishes []Dish `json:"dishes"`
+}
+
+// Menu represents a restaurant menu grouped by category
+type Menu struct {
+	Categories  []MenuCategory `json:"categories"`
+	TotalDishes int            `json:"total_dishes"`
+}
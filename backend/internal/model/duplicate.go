@@ -0,0 +1,50 @@
+package model
+
+import "time"
+
+// ReviewText is the part of a review used to fingerprint it during duplicate detection
+type ReviewText struct {
+	RatingID   string
+	UserID     string
+	Feedback   string
+	ReviewTime time.Time
+}
+
+// ReviewDuplicate marks a review as a duplicate of an earlier review of the same restaurant
+type ReviewDuplicate struct {
+	// Review marked as duplicate
+	RatingID string
+	// Earliest review of the cluster, which is kept
+	DuplicateOf string
+	// Why the review was marked (same_user_day, exact, near)
+	Reason string
+	// Jaccard similarity of the texts (1 for exact and same-day duplicates)
+	Similarity float64
+}
+
+// DuplicateReview represents a review marked as duplicate in the admin report
+type DuplicateReview struct {
+	Review
+	// Why the review was marked (same_user_day, exact, near)
+	Reason string `json:"reason"`
+	// Jaccard similarity with the kept review
+	Similarity float64 `json:"similarity"`
+}
+
+// DuplicateCluster represents a kept review and the reviews marked as its duplicates
+// @Description A group of duplicate reviews, only the canonical review counts toward ratings
+type DuplicateCluster struct {
+	// Restaurant the reviews belong to
+	RestaurantID string `json:"restaurant_id"`
+	// Earliest review of the cluster, which is kept
+	Canonical Review `json:"canonical"`
+	// Reviews excluded from the restaurant rating
+	Duplicates []DuplicateReview `json:"duplicates"`
+}
+
+// DuplicateReport represents a paginated list of duplicate clusters
+type DuplicateReport struct {
+	Clusters        []DuplicateCluster `json:"clusters"`
+	TotalClusters   int                `json:"total_clusters"`
+	TotalDuplicates int                `json:"total_duplicates"`
+}
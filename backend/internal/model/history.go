@@ -0,0 +1,59 @@
+package model
+
+// RatingSnapshot represents the rating of a restaurant as of one full recalculation
+type RatingSnapshot struct {
+	// Recalculation version the snapshot was taken at
+	Version int `json:"version"`
+	// Rating strategy the rating was computed with (mean, bayesian, decay)
+	Strategy string `json:"strategy"`
+	// Rating settings the rating was computed with
+	Settings    RatingSettings `json:"settings"`
+	Rating      float64        `json:"rating"`
+	ReviewCount int            `json:"review_count"`
+	// Label ratings keyed by label, unknown ratings counted as by the unknown policy at the time
+	Labels    map[string]float64 `json:"labels"`
+	CreatedAt string             `json:"created_at"`
+}
+
+// RatingSettings represents the rating configuration in use at a full recalculation.
+// Food type overrides of the aspect weights are not included.
+type RatingSettings struct {
+	Strategy          string  `json:"strategy"`
+	PriorWeight       float64 `json:"prior_weight"`
+	PriorScope        string  `json:"prior_scope"`
+	DecayHalfLifeDays float64 `json:"decay_half_life_days"`
+	// Configured weight of each label in the overall rating, labels left out weigh 1
+	AspectWeights    map[string]float64 `json:"aspect_weights"`
+	SuspiciousPolicy string             `json:"suspicious_policy"`
+	SuspiciousWeight float64            `json:"suspicious_weight"`
+	UnknownPolicy    string             `json:"unknown_policy"`
+}
+
+// RatingHistory represents the snapshots of a restaurant, newest first
+// @Description Paginated rating snapshots of a restaurant, one per full recalculation.
+// @Description Single-restaurant, incremental and moderation recalculations are not snapshotted.
+type RatingHistory struct {
+	RestaurantID string           `json:"restaurant_id"`
+	Snapshots    []RatingSnapshot `json:"snapshots"`
+	Total        int              `json:"total"`
+}
+
+// RatingMover represents how a restaurant moved between two snapshots
+type RatingMover struct {
+	RestaurantID   string  `json:"restaurant_id"`
+	RestaurantName string  `json:"restaurant_name"`
+	OldRating      float64 `json:"old_rating"`
+	Rating         float64 `json:"rating"`
+	// Rating difference, positive when the restaurant went up
+	Change         float64 `json:"change"`
+	OldReviewCount int     `json:"old_review_count"`
+	ReviewCount    int     `json:"review_count"`
+}
+
+// RatingMovers represents the restaurants whose rating moved the most between two snapshots
+// @Description Restaurants with the largest rating change between two recalculation versions
+type RatingMovers struct {
+	FromVersion int           `json:"from_version"`
+	ToVersion   int           `json:"to_version"`
+	Movers      []RatingMover `json:"movers"`
+}
@@ -0,0 +1,26 @@
+package model
+
+// JobProgress represents how far a job is in its current step
+type JobProgress struct {
+	// Step the job is running, e.g. recalculate or keywords
+	Step  string `json:"step"`
+	Done  int    `json:"done"`
+	Total int    `json:"total"`
+}
+
+// Job represents a background task started by the API
+// @Description Status of a background job such as a recalculation or an export
+type Job struct {
+	ID   string `json:"id"`
+	Kind string `json:"kind"`
+	// Status of the job (running, succeeded, failed, cancelled)
+	Status     string `json:"status"`
+	CreatedAt  string `json:"created_at"`
+	FinishedAt string `json:"finished_at,omitempty"`
+	// Progress, error and result are only returned to admin callers
+	Progress JobProgress `json:"progress"`
+	// Error message when the job failed
+	Error string `json:"error,omitempty"`
+	// Outcome of the job when it has one, e.g. the restaurants applied by a dry-run apply
+	Result any `json:"result,omitempty"`
+}
@@ -0,0 +1,38 @@
+package model
+
+// LabeledFeedback represents the feedback of a review with one of its label ratings
+type LabeledFeedback struct {
+	RatingID    string
+	Feedback    string
+	Label       string
+	RatingLabel float64
+}
+
+// RestaurantKeyword represents a frequent phrase of a restaurant's reviews
+type RestaurantKeyword struct {
+	Label     string
+	Sentiment string
+	Phrase    string
+	// Number of reviews containing the phrase
+	ReviewCount int
+}
+
+// Keyword represents a phrase and how many reviews use it
+type Keyword struct {
+	Phrase      string `json:"phrase"`
+	ReviewCount int    `json:"review_count"`
+}
+
+// KeywordGroup represents the most frequent phrases of the positive and negative reviews of a label
+type KeywordGroup struct {
+	Positive []Keyword `json:"positive"`
+	Negative []Keyword `json:"negative"`
+}
+
+// RestaurantKeywords represents the phrases explaining the label ratings of a restaurant
+// @Description Most frequent phrases per label, split by positive and negative label ratings
+type RestaurantKeywords struct {
+	RestaurantID string `json:"restaurant_id"`
+	// Keyword groups keyed by label
+	Labels map[string]KeywordGroup `json:"labels"`
+}
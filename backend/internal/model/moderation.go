@@ -0,0 +1,23 @@
+package model
+
+// ModerationItem represents a review in the moderation queue with its reports
+// @Description A reported review waiting for a moderator decision
+type ModerationItem struct {
+	Review
+	// Restaurant the review belongs to
+	RestaurantID string `json:"restaurant_id"`
+	// Moderation status (visible, flagged, hidden)
+	Status string `json:"status"`
+	// Number of unresolved reports
+	ReportCount int `json:"report_count"`
+	// Number of unresolved reports per reason
+	Reasons map[string]int `json:"reasons"`
+	// Time of the latest report
+	LastReportedAt string `json:"last_reported_at"`
+}
+
+// ModerationQueue represents a paginated list of reviews to moderate
+type ModerationQueue struct {
+	Items []ModerationItem `json:"items"`
+	Total int              `json:"total"`
+}
@@ -0,0 +1,28 @@
+package model
+
+// RankedRestaurant represents a restaurant in a ranking
+type RankedRestaurant struct {
+	Rank           int    `json:"rank"`
+	RestaurantID   string `json:"restaurant_id"`
+	RestaurantName string `json:"restaurant_name"`
+	// Overall or label rating the restaurant is ranked by
+	Score       float64 `json:"score"`
+	ReviewCount int     `json:"review_count"`
+	// Rank before the last recalculation, absent when the restaurant was not ranked
+	PreviousRank *int `json:"previous_rank"`
+	// Places gained since the last recalculation, negative when the restaurant dropped
+	Movement int `json:"movement"`
+}
+
+// Ranking represents the top restaurants of a city, district or food type
+// @Description Ranked restaurants by overall or label rating, with their movement since the last recalculation
+type Ranking struct {
+	// Rating the restaurants are ranked by: overall or a label
+	Label      string `json:"label"`
+	CityID     string `json:"city_id,omitempty"`
+	DistrictID string `json:"district_id,omitempty"`
+	FoodType   string `json:"food_type,omitempty"`
+	// Recalculation version the ranking was built at
+	Version     int                `json:"version"`
+	Restaurants []RankedRestaurant `json:"restaurants"`
+}
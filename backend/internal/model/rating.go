@@ -0,0 +1,52 @@
+package model
+
+// RatingBucket represents the number of ratings rounded to a star value
+type RatingBucket struct {
+	// Star value of the bucket (1 to 5)
+	Stars int `json:"stars"`
+	// Number of ratings in the bucket
+	Count int `json:"count"`
+}
+
+// RatingHistogram represents how ratings are spread over the star buckets
+// @Description Histogram of ratings with the mean and standard deviation, a high deviation means a polarizing restaurant
+type RatingHistogram struct {
+	// One bucket per star value, from 1 to 5
+	Buckets []RatingBucket `json:"buckets"`
+	// Total number of ratings
+	Total int `json:"total"`
+	// Mean of the ratings
+	Average float64 `json:"average"`
+	// Population standard deviation of the ratings
+	StdDev float64 `json:"std_dev"`
+}
+
+// RatingDistribution represents the rating histograms of a restaurant
+type RatingDistribution struct {
+	// Histogram of the overall review ratings
+	Overall RatingHistogram `json:"overall"`
+	// Histograms of the label ratings, keyed by label
+	Labels map[string]RatingHistogram `json:"labels"`
+	// Histograms of the overall review ratings, keyed by platform name
+	Platforms map[string]RatingHistogram `json:"platforms"`
+}
+
+// RatingTrendPoint represents the reviews of a restaurant in one time bucket
+type RatingTrendPoint struct {
+	// First day of the bucket (YYYY-MM-DD)
+	Period string `json:"period"`
+	// Number of reviews in the bucket
+	ReviewCount int `json:"review_count"`
+	// Average overall rating of the reviews in the bucket
+	AverageRating float64 `json:"average_rating"`
+	// Average rating and count of each label in the bucket, keyed by label
+	Labels map[string]LabelRating `json:"labels"`
+}
+
+// RatingTrend represents the evolution of a restaurant's ratings over time
+type RatingTrend struct {
+	// Size of each bucket (month or week)
+	Granularity string `json:"granularity"`
+	// Buckets in chronological order, buckets without reviews are omitted
+	Points []RatingTrendPoint `json:"points"`
+}
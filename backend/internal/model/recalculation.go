@@ -0,0 +1,48 @@
+package model
+
+// StagedRating represents a restaurant before and after a dry-run recalculation
+type StagedRating struct {
+	RestaurantID   string `json:"restaurant_id"`
+	RestaurantName string `json:"restaurant_name"`
+	// District the restaurant is ranked in, empty when it has none
+	DistrictID     string  `json:"district_id"`
+	OldReviewCount int     `json:"old_review_count"`
+	ReviewCount    int     `json:"review_count"`
+	OldRating      float64 `json:"old_rating"`
+	Rating         float64 `json:"rating"`
+	// Rank of the restaurant in its district by rating, 1 being the best
+	OldDistrictRank int `json:"old_district_rank"`
+	DistrictRank    int `json:"district_rank"`
+}
+
+// DistrictRankChanges represents the restaurants of a district whose rank would change
+type DistrictRankChanges struct {
+	DistrictID string `json:"district_id"`
+	// Number of restaurants of the district whose rank would change
+	ChangedCount int `json:"changed_count"`
+	// Restaurants moving the most places, up to 5
+	Restaurants []StagedRating `json:"restaurants"`
+}
+
+// DryRunSummary represents what applying a dry-run recalculation would change
+// @Description Restaurants changed, largest rating movers and rank changes per district of the staged recalculation
+type DryRunSummary struct {
+	StagedAt        string `json:"staged_at"`
+	RestaurantCount int    `json:"restaurant_count"`
+	// Number of restaurants whose rating or review count would change
+	ChangedCount int `json:"changed_count"`
+	// Restaurants whose rating would move the most, up to 20
+	LargestMovers []StagedRating        `json:"largest_movers"`
+	Districts     []DistrictRankChanges `json:"districts"`
+}
+
+// DryRunApplyResult represents the outcome of applying a dry-run recalculation
+type DryRunApplyResult struct {
+	// Number of restaurants updated with their staged values
+	Applied int `json:"applied"`
+	// Number of restaurants left out because they changed since the dry run,
+	// they are marked for the next incremental recalculation
+	Skipped int `json:"skipped"`
+	// Recalculation version the applied restaurants are stamped with
+	Version int `json:"version"`
+}
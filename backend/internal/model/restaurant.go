@@ -25,6 +25,10 @@ type Restaurant struct {
 	FoodType    string  `json:"food_type_name"`
 	// Distance from user's location in kilometers
 	Distance    float64 `json:"distance"`
+	// Full recalculation the rating and review count come from
+	RatingVersion int `json:"rating_version"`
+	// When the rating and review count were last recalculated, in full or for this restaurant only
+	RecalculatedAt string `json:"recalculated_at,omitempty"`
 }
 
 type RestaurantDetail struct{
@@ -39,11 +43,71 @@ type RestaurantDetail struct{
 	// Ratings for the restaurant on different platforms
 	// The length of this array should match the length of the Platforms array
 	RatingPlatforms []float64 `json:"rating_platforms"`
+	// Raw and normalized ratings of the restaurant on each platform
+	PlatformRatings []PlatformRating `json:"platform_ratings"`
+	// Spread of the normalized ratings of the platforms and ours, from 0 (all agree) to 1
+	PlatformDisagreement float64 `json:"platform_disagreement"`
+	// Label ratings per source platform of the reviews, only when a breakdown is requested
+	LabelsByPlatform []PlatformLabelsRating `json:"labels_by_platform,omitempty"`
+	// Weight of each label in the overall rating currently configured for the food type of
+	// the restaurant. Changes apply on the next recalculation, so the rating may still use
+	// older weights. When all its rated labels weigh 0 the rating is their plain mean.
+	AspectWeights map[string]float64 `json:"aspect_weights"`
+}
+
+// PlatformLabelsRating represents the label ratings computed from the reviews of one platform
+type PlatformLabelsRating struct {
+	Platform string `json:"platform"`
+	// Number of counted reviews from the platform
+	ReviewCount int          `json:"review_count"`
+	Labels      LabelsRating `json:"labels"`
+}
+
+// RestaurantLabels represents the label ratings of a restaurant
+// @Description Label ratings of a restaurant, optionally broken down by the platform reviews come from
+type RestaurantLabels struct {
+	RestaurantID string       `json:"restaurant_id"`
+	Labels       LabelsRating `json:"labels"`
+	// Label ratings per source platform, only when a breakdown is requested
+	ByPlatform []PlatformLabelsRating `json:"by_platform,omitempty"`
+}
+
+// PlatformRating represents the rating of a restaurant on another platform
+type PlatformRating struct {
+	Platform string `json:"platform"`
+	// Rating as shown on the platform, on its own scale
+	Rating   float64 `json:"rating"`
+	ScaleMin float64 `json:"scale_min"`
+	ScaleMax float64 `json:"scale_max"`
+	// Rating brought to our 1 to 5 scale, 0 when it could not be normalized
+	NormalizedRating float64 `json:"normalized_rating"`
+	// How the rating was normalized: linear, distribution, linear_fallback for a distribution
+	// platform rating not calibrated yet, or none when the platform scale is invalid
+	Normalization string `json:"normalization"`
+}
+
+// PlatformScale represents the rating scale of a platform
+type PlatformScale struct {
+	PlatformID    int
+	Name          string
+	ScaleMin      float64
+	ScaleMax      float64
+	Normalization string
+}
+
+// RatingCount represents how many restaurants have a given rating
+type RatingCount struct {
+	Rating float64
+	Count  int
 }
 
 type LabelRating struct {
 	Rating float64 `json:"rating"`
 	Count  int     `json:"count"`
+	// Number of unknown-labeled reviews included in the rating
+	UnknownCount int `json:"unknown_count"`
+	// Share of the rating weight coming from unknown-labeled reviews, from 0 to 1
+	UnknownShare float64 `json:"unknown_share"`
 }
 
 type LabelsRating struct {
@@ -52,4 +116,8 @@ type LabelsRating struct {
 	Food     LabelRating `json:"food"`
 	Price    LabelRating `json:"price"`
 	Service  LabelRating `json:"service"`
+	// Rating of the reviews labeled unknown, which no aspect could be found in
+	Unknown LabelRating `json:"unknown"`
+	// How unknown ratings are counted: spread, overall or ignore
+	UnknownPolicy string `json:"unknown_policy"`
 }
@@ -3,13 +3,61 @@ package model
 // Review represents the structure of a review entity
 // @Description This struct is used to represent a review in the system
 type Review struct {
-	RatingID    string  `json:"rating_id"`
-	UserName    string  `json:"username"`
+	RatingID string `json:"rating_id"`
+	UserName string `json:"username"`
+	// Platform the review was collected from
+	Platform    string  `json:"platform"`
 	Rating      float64 `json:"rating"`
 	Feedback    string  `json:"feedback"`
 	ReviewTime  string  `json:"review_time"`
 	Label       string  `json:"label"`
 	RatingLabel float64 `json:"rating_label"`
+	// All labels attached to the review
+	Labels []ReviewLabel `json:"labels,omitempty"`
+	// Number of readers who found the review helpful
+	HelpfulCount int `json:"helpful_count"`
+	// Number of readers who found the review unhelpful
+	UnhelpfulCount int `json:"unhelpful_count"`
+}
+
+// ReviewVotes represents the vote tallies of a review after a vote
+type ReviewVotes struct {
+	RatingID       string `json:"rating_id"`
+	HelpfulCount   int    `json:"helpful_count"`
+	UnhelpfulCount int    `json:"unhelpful_count"`
+	// Vote of the current client: helpful, unhelpful or none
+	Vote string `json:"vote"`
+}
+
+// UnlabeledReview represents a review waiting for a labeler to rate its aspects
+type UnlabeledReview struct {
+	RatingID string  `json:"rating_id"`
+	Rating   float64 `json:"rating"`
+	Feedback string  `json:"feedback"`
+}
+
+// ReviewLabel represents the rating a review gives to one aspect of a restaurant
+type ReviewLabel struct {
+	// Aspect of the restaurant (ambience, delivery, food, price, service, unknown)
+	Label string `json:"label"`
+	// Rating given to the aspect
+	RatingLabel float64 `json:"rating_label"`
+}
+
+// ReviewFilter holds the optional filters and ordering of a review listing
+type ReviewFilter struct {
+	// Only reviews rating this label (or unknown) when not empty
+	Label string
+	// One of newest, oldest, highest, lowest, helpful (default newest)
+	Sort string
+	// Lower bound of the overall rating, ignored when 0
+	MinRating float64
+	// Upper bound of the overall rating, ignored when 0
+	MaxRating float64
+	// Only reviews from this platform when not empty
+	Platform string
+	// Ignore reviews without text
+	TextOnly bool
 }
 
 // ReviewResponse represents a paginated response for reviews
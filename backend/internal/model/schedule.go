@@ -0,0 +1,18 @@
+package model
+
+// ScheduleStatus represents a scheduled job and the outcome of its last run
+// @Description Cron schedule of a job with its next run and last run on this instance
+type ScheduleStatus struct {
+	Kind string `json:"kind"`
+	// Cron expression of the schedule
+	Schedule string `json:"schedule"`
+	NextRun  string `json:"next_run"`
+	// Time of the last scheduled run on this instance
+	LastRun string `json:"last_run,omitempty"`
+	// Outcome of the last run: started, skipped_running, skipped_locked or error
+	LastOutcome string `json:"last_outcome,omitempty"`
+	// Error of the last run when it could not start
+	LastError string `json:"last_error,omitempty"`
+	// Job started by the last run, with its current status
+	LastJob *Job `json:"last_job,omitempty"`
+}
@@ -0,0 +1,54 @@
+package model
+
+import "time"
+
+// ReviewActivity is a review with the history of its reviewer, used to detect review bombing
+type ReviewActivity struct {
+	RatingID   string
+	UserID     string
+	Rating     float64
+	ReviewTime time.Time
+	// Number of reviews the reviewer wrote on all restaurants
+	ReviewerReviewCount int
+	// Average rating the reviewer gives on all restaurants
+	ReviewerAverageRating float64
+}
+
+// ReviewFlag represents a review flagged as suspicious
+type ReviewFlag struct {
+	RatingID string `json:"rating_id"`
+	// Suspicion score between 0 and 1
+	Score float64 `json:"score"`
+	// Comma-separated reasons (burst, new_reviewer, one_sided_reviewer)
+	Reasons string `json:"reasons"`
+}
+
+// SuspiciousWindow represents a burst of unusually many, unusually rated reviews for a restaurant
+// @Description A time window where a restaurant received a burst of reviews far from its usual rating
+type SuspiciousWindow struct {
+	WindowID     int    `json:"window_id"`
+	RestaurantID string `json:"restaurant_id"`
+	// First day of the window (YYYY-MM-DD)
+	WindowStart string `json:"window_start"`
+	// Last day of the window (YYYY-MM-DD)
+	WindowEnd string `json:"window_end"`
+	// positive for a burst of high ratings, negative for a burst of low ratings
+	Direction string `json:"direction"`
+	// Number of reviews in the window
+	ReviewCount int `json:"review_count"`
+	// Average rating of the reviews in the window
+	AverageRating float64 `json:"average_rating"`
+	// Average rating of the restaurant outside the window
+	BaselineRating float64 `json:"baseline_rating"`
+	// Usual number of reviews per day outside the window
+	BaselineDailyReviews float64 `json:"baseline_daily_reviews"`
+	DetectedAt           string  `json:"detected_at"`
+	// Reviews of the window flagged as suspicious
+	FlaggedReviews []ReviewFlag `json:"flagged_reviews"`
+}
+
+// SuspiciousReport represents a paginated list of suspicious windows
+type SuspiciousReport struct {
+	Windows      []SuspiciousWindow `json:"windows"`
+	TotalWindows int                `json:"total_windows"`
+}
@@ -0,0 +1,37 @@
+package model
+
+// UserProfile represents a reviewer and the ratings they give
+// @Description This struct is used to judge how credible a reviewer is
+type UserProfile struct {
+	// Unique identifier of the user
+	UserID string `json:"user_id"`
+	// Name of the user
+	UserName string `json:"username"`
+	// Platform the user reviews on
+	Platform string `json:"platform"`
+	// Number of reviews written by the user
+	ReviewCount int `json:"review_count"`
+	// Number of distinct restaurants reviewed by the user
+	RestaurantCount int `json:"restaurant_count"`
+	// Average overall rating given by the user
+	AverageRating float64 `json:"average_rating"`
+	// Average rating and count the user gives to each label, keyed by label
+	Labels map[string]LabelRating `json:"labels"`
+	// Time of the first review of the user
+	FirstReviewTime string `json:"first_review_time"`
+	// Time of the latest review of the user
+	LastReviewTime string `json:"last_review_time"`
+}
+
+// UserReview represents a review written by a user with the restaurant it is about
+type UserReview struct {
+	Review
+	// Summary of the reviewed restaurant
+	Restaurant Restaurant `json:"restaurant"`
+}
+
+// UserReviewResponse represents a paginated response for the reviews of a user
+type UserReviewResponse struct {
+	Reviews      []UserReview `json:"reviews"`
+	TotalReviews int          `json:"total_reviews"`
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"skeleton-internship-backend/config"
+	"strconv"
+)
+
+// RatingStrategy decides how the label averages of a restaurant become its overall rating.
+// Strategies produce SQL fragments so ratings keep being computed in the database.
+type RatingStrategy interface {
+	// ReviewWeight returns a SQL factor multiplied into the weight of each review (aliased r)
+	ReviewWeight() string
+	// Rating returns the SQL expression of the overall rating from the given columns
+	Rating(columns RatingColumns) string
+}
+
+// RatingColumns names the SQL columns a strategy can build the overall rating from
+type RatingColumns struct {
+	// Mean of the label averages of the restaurant, weighted by aspect
+	Mean string
+	// Total weight of the labeled reviews of the restaurant
+	ReviewWeight string
+	// Mean label rating of every restaurant, and of the restaurants of the same food type
+	GlobalPrior  string
+	CuisinePrior string
+}
+
+// NewRatingStrategy returns the strategy selected by the rating configuration, which
+// config.NewConfig has validated
+func NewRatingStrategy(cfg config.RatingConfig) RatingStrategy {
+	switch cfg.Strategy {
+	case "bayesian":
+		return bayesianStrategy{priorWeight: cfg.PriorWeight, byCuisine: cfg.PriorScope == "cuisine"}
+	case "decay":
+		return decayStrategy{halfLifeDays: cfg.DecayHalfLifeDays}
+	default:
+		return meanStrategy{}
+	}
+}
+
+// meanStrategy averages the label averages, every review weighing the same
+type meanStrategy struct{}
+
+func (meanStrategy) ReviewWeight() string {
+	return "1"
+}
+
+func (meanStrategy) Rating(columns RatingColumns) string {
+	return columns.Mean
+}
+
+// bayesianStrategy pulls the mean toward a prior as if priorWeight reviews at the prior
+// rating had been added, so restaurants with few reviews do not outrank well-reviewed ones
+type bayesianStrategy struct {
+	priorWeight float64
+	byCuisine   bool
+}
+
+func (bayesianStrategy) ReviewWeight() string {
+	return "1"
+}
+
+func (s bayesianStrategy) Rating(columns RatingColumns) string {
+	prior := "COALESCE(" + columns.GlobalPrior + ", " + columns.Mean + ")"
+	if s.byCuisine {
+		// Food types without any rated restaurant fall back to the global prior
+		prior = "COALESCE(" + columns.CuisinePrior + ", " + columns.GlobalPrior + ", " + columns.Mean + ")"
+	}
+	c := strconv.FormatFloat(s.priorWeight, 'f', -1, 64)
+	return "(" + c + " * " + prior + " + " + columns.ReviewWeight + " * " + columns.Mean + ") / (" + c + " + " + columns.ReviewWeight + ")"
+}
+
+// decayStrategy halves the weight of a review every halfLifeDays, so recent reviews
+// drive the rating. Reviews without a time keep their full weight.
+type decayStrategy struct {
+	halfLifeDays float64
+}
+
+func (s decayStrategy) ReviewWeight() string {
+	return "COALESCE(POW(0.5, GREATEST(DATEDIFF(NOW(), r.review_time), 0) / " + strconv.FormatFloat(s.halfLifeDays, 'f', -1, 64) + "), 1)"
+}
+
+func (decayStrategy) Rating(columns RatingColumns) string {
+	return columns.Mean
+}
@@ -0,0 +1,216 @@
+package repository
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"skeleton-internship-backend/config"
+	"skeleton-internship-backend/internal/constant"
+	"skeleton-internship-backend/internal/model"
+)
+
+var strategyColumns = RatingColumns{
+	Mean:         "mean",
+	ReviewWeight: "weight",
+	GlobalPrior:  "global",
+	CuisinePrior: "cuisine",
+}
+
+func TestRatingStrategies(t *testing.T) {
+	tests := []struct {
+		name       string
+		cfg        config.RatingConfig
+		wantWeight string
+		wantRating string
+	}{
+		{
+			name:       "mean",
+			cfg:        config.RatingConfig{Strategy: "mean"},
+			wantWeight: "1",
+			wantRating: "mean",
+		},
+		{
+			name:       "bayesian with a global prior",
+			cfg:        config.RatingConfig{Strategy: "bayesian", PriorWeight: 10, PriorScope: "global"},
+			wantWeight: "1",
+			wantRating: "(10 * COALESCE(global, mean) + weight * mean) / (10 + weight)",
+		},
+		{
+			name:       "bayesian with a cuisine prior",
+			cfg:        config.RatingConfig{Strategy: "bayesian", PriorWeight: 2.5, PriorScope: "cuisine"},
+			wantWeight: "1",
+			wantRating: "(2.5 * COALESCE(cuisine, global, mean) + weight * mean) / (2.5 + weight)",
+		},
+		{
+			name:       "decay",
+			cfg:        config.RatingConfig{Strategy: "decay", DecayHalfLifeDays: 180},
+			wantWeight: "COALESCE(POW(0.5, GREATEST(DATEDIFF(NOW(), r.review_time), 0) / 180), 1)",
+			wantRating: "mean",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			strategy := NewRatingStrategy(tt.cfg)
+			if got := strategy.ReviewWeight(); got != tt.wantWeight {
+				t.Errorf("ReviewWeight() = %s, want %s", got, tt.wantWeight)
+			}
+			if got := strategy.Rating(strategyColumns); got != tt.wantRating {
+				t.Errorf("Rating() = %s, want %s", got, tt.wantRating)
+			}
+		})
+	}
+}
+
+func newTestRepository(rating config.RatingConfig) *repository {
+	cfg := &config.Config{Rating: rating}
+	return &repository{cfg: cfg, strategy: NewRatingStrategy(rating)}
+}
+
+func TestReviewWeight(t *testing.T) {
+	tests := []struct {
+		name   string
+		rating config.RatingConfig
+		want   string
+	}{
+		{
+			name:   "mean",
+			rating: config.RatingConfig{Strategy: "mean", SuspiciousPolicy: "downweight", SuspiciousWeight: 0.2},
+			want:   "IF(rf.rating_id IS NULL, 1, 0.2)",
+		},
+		{
+			name:   "decay multiplies in the strategy weight",
+			rating: config.RatingConfig{Strategy: "decay", DecayHalfLifeDays: 365, SuspiciousPolicy: "ignore"},
+			want:   "IF(rf.rating_id IS NULL, 1, 0) * COALESCE(POW(0.5, GREATEST(DATEDIFF(NOW(), r.review_time), 0) / 365), 1)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newTestRepository(tt.rating).reviewWeight(); got != tt.want {
+				t.Errorf("reviewWeight() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// Label stats, the platform breakdown and the overall rating weigh reviews the same way
+func TestAggregationsShareReviewWeight(t *testing.T) {
+	r := newTestRepository(config.RatingConfig{Strategy: "decay", DecayHalfLifeDays: 30, SuspiciousPolicy: "none"})
+	decay := r.strategy.ReviewWeight()
+
+	overall, _ := r.averageRatingQuery("Restaurant", "Restaurant_label_stats", "")
+	labelStats, _ := r.labelStatsSelect("")
+	for name, query := range map[string]string{
+		"label stats":    labelStats,
+		"overall rating": overall,
+	} {
+		if !strings.Contains(query, "SUM(fl.rating_label * "+r.reviewWeight()+")") {
+			t.Errorf("%s query does not weigh label ratings by %s", name, r.reviewWeight())
+		}
+		if !strings.Contains(query, decay) {
+			t.Errorf("%s query is not decayed", name)
+		}
+	}
+}
+
+func TestUnknownPolicies(t *testing.T) {
+	tests := []struct {
+		policy        string
+		spread        bool
+		unknownAspect bool
+	}{
+		{policy: "spread", spread: true},
+		{policy: "overall", unknownAspect: true},
+		{policy: "ignore"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			r := newTestRepository(config.RatingConfig{Strategy: "mean", UnknownPolicy: tt.policy})
+			query, _ := r.averageRatingQuery("Restaurant", "Restaurant_label_stats", "")
+
+			if got := strings.Contains(query, "COALESCE(u.sum_unknown, 0)"); got != tt.spread {
+				t.Errorf("unknown ratings merged into the aspects = %v, want %v", got, tt.spread)
+			}
+			if got := strings.Contains(query, "'unknown',"); got != tt.unknownAspect {
+				t.Errorf("unknown ratings averaged on their own = %v, want %v", got, tt.unknownAspect)
+			}
+			labels := r.cfg.Rating.WeightedLabels()
+			if got := labels[len(labels)-1] == constant.LabelUnknown; got != tt.unknownAspect {
+				t.Errorf("WeightedLabels() = %v, unknown weighted = %v, want %v", labels, got, tt.unknownAspect)
+			}
+		})
+	}
+}
+
+func TestAspectWeightedMean(t *testing.T) {
+	r := newTestRepository(config.RatingConfig{
+		Strategy:      "mean",
+		UnknownPolicy: "overall",
+		AspectWeights: map[string]float64{"food": 2, "delivery": 0.5},
+	})
+
+	want := "CASE la.label WHEN 'ambience' THEN 1 WHEN 'delivery' THEN 0.5 WHEN 'food' THEN 2 WHEN 'price' THEN 1 WHEN 'service' THEN 1 WHEN 'unknown' THEN 1 ELSE 1 END"
+	if got := r.configuredAspectWeight("la.label"); got != want {
+		t.Errorf("configuredAspectWeight() = %s, want %s", got, want)
+	}
+
+	// Food type weights override the configured ones, and all-zero weights fall back to the plain mean
+	weight := "COALESCE(aw.weight, " + want + ")"
+	query, _ := r.averageRatingQuery("Restaurant", "Restaurant_label_stats", "")
+	mean := "COALESCE(\n      SUM(la.label_avg * " + weight + ") / NULLIF(SUM(" + weight + "), 0),\n      AVG(la.label_avg)\n    )"
+	if !strings.Contains(query, mean) {
+		t.Errorf("overall rating query does not compute %s", mean)
+	}
+}
+
+func TestRatingSettings(t *testing.T) {
+	rating := config.RatingConfig{
+		Strategy:          "bayesian",
+		PriorWeight:       5,
+		PriorScope:        "global",
+		DecayHalfLifeDays: 365,
+		AspectWeights:     map[string]float64{"food": 2},
+		SuspiciousPolicy:  "downweight",
+		SuspiciousWeight:  0.2,
+		UnknownPolicy:     "overall",
+	}
+	want := model.RatingSettings{
+		Strategy:          "bayesian",
+		PriorWeight:       5,
+		PriorScope:        "global",
+		DecayHalfLifeDays: 365,
+		AspectWeights:     map[string]float64{"food": 2},
+		SuspiciousPolicy:  "downweight",
+		SuspiciousWeight:  0.2,
+		UnknownPolicy:     "overall",
+	}
+	if got := newTestRepository(rating).ratingSettings(); !reflect.DeepEqual(got, want) {
+		t.Errorf("ratingSettings() = %+v, want %+v", got, want)
+	}
+
+	rating.AspectWeights = nil
+	if got := newTestRepository(rating).ratingSettings(); got.AspectWeights == nil {
+		t.Error("ratingSettings() without aspect weights = null, want an empty map")
+	}
+}
+
+func TestAverageRatingQueryResetsRatingsWithoutLabels(t *testing.T) {
+	r := newTestRepository(config.RatingConfig{Strategy: "mean", UnknownPolicy: "spread"})
+
+	for _, id := range []string{"", "42"} {
+		query, args := r.averageRatingQuery("Restaurant", "Restaurant_label_stats", id)
+		if !strings.Contains(query, "LEFT JOIN final_rating fr") || !strings.Contains(query, "IF(fr.restaurant_id IS NULL, 0, ") {
+			t.Errorf("averageRatingQuery(%q) keeps the rating of restaurants without labeled reviews", id)
+		}
+		if got := strings.Count(query, "?"); got != len(args) {
+			t.Errorf("averageRatingQuery(%q) has %d placeholders for %d arguments", id, got, len(args))
+		}
+		// Only the given restaurant is reset
+		if limited := strings.HasSuffix(query, "WHERE r.restaurant_id = ?;"); limited != (id != "") {
+			t.Errorf("averageRatingQuery(%q) limited to the restaurant = %v", id, limited)
+		}
+	}
+}
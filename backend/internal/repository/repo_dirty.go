@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/rs/zerolog/log"
+)
+
+// markRestaurantDirty queues a restaurant for the next incremental recalculation
+func markRestaurantDirty(tx *sql.Tx, id string) error {
+	_, err := tx.Exec(`INSERT INTO Restaurant_dirty (restaurant_id) VALUES (?)
+	ON DUPLICATE KEY UPDATE marked_at = CURRENT_TIMESTAMP`, id)
+	if err != nil {
+		log.Error().Err(err).Msgf("Error marking restaurant %s dirty", id)
+	}
+	return err
+}
+
+// markReviewsDirty queues the restaurants of the reviews matching a condition on Review (aliased r)
+func markReviewsDirty(tx *sql.Tx, condition string, args ...interface{}) error {
+	_, err := tx.Exec(`INSERT INTO Restaurant_dirty (restaurant_id)
+	SELECT DISTINCT r.restaurant_id FROM Review r WHERE `+condition+`
+	ON DUPLICATE KEY UPDATE marked_at = CURRENT_TIMESTAMP`, args...)
+	if err != nil {
+		log.Error().Err(err).Msg("Error marking restaurants of changed reviews dirty")
+	}
+	return err
+}
+
+// FindDirtyRestaurants returns the restaurants waiting for a recalculation, oldest changes first
+func (r *repository) FindDirtyRestaurants() ([]string, error) {
+	rows, err := r.db.Query(`SELECT restaurant_id FROM Restaurant_dirty ORDER BY marked_at, restaurant_id`)
+	if err != nil {
+		log.Error().Err(err).Msg("Error executing query to find dirty restaurants")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			log.Error().Err(err).Msg("Error scanning dirty restaurant data")
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
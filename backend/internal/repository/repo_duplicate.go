@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"database/sql"
+	"skeleton-internship-backend/internal/constant"
+	"skeleton-internship-backend/internal/model"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// FindReviewTextsByRestaurantID returns the reviews of a restaurant that are not hidden,
+// oldest first, with a zero time for reviews without one. Reviews already marked as
+// duplicates are included, each detection pass replaces the previous marks.
+func (r *repository) FindReviewTextsByRestaurantID(id string) ([]model.ReviewText, error) {
+	query := `SELECT r.rating_id, r.user_id, r.feedback, r.review_time
+	FROM Review r
+	WHERE r.restaurant_id = ? AND ` + visibleReview + `
+	ORDER BY r.review_time, r.rating_id`
+	rows, err := r.db.Query(query, id)
+	if err != nil {
+		log.Error().Err(err).Msg("Error executing query to find review texts")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reviews []model.ReviewText
+	for rows.Next() {
+		var review model.ReviewText
+		var feedback sql.NullString
+		var reviewTime sql.NullTime
+		if err := rows.Scan(&review.RatingID, &review.UserID, &feedback, &reviewTime); err != nil {
+			log.Error().Err(err).Msg("Error scanning review text data")
+			return nil, err
+		}
+		review.Feedback = feedback.String
+		review.ReviewTime = reviewTime.Time
+		reviews = append(reviews, review)
+	}
+
+	return reviews, nil
+}
+
+// MarkDuplicateReviews replaces the marks of the previous detection pass with the given duplicates
+func (r *repository) MarkDuplicateReviews(duplicates []model.ReviewDuplicate) error {
+	log.Info().Msgf("Marking %d duplicate reviews", len(duplicates))
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		log.Error().Err(err).Msg("Error starting transaction to mark duplicates")
+		return err
+	}
+	defer tx.Rollback()
+
+	// Restaurants of reviews that stop or start being duplicates need a recalculation
+	if err := markReviewsDirty(tx, "r.duplicate_of IS NOT NULL"); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`UPDATE Review SET duplicate_of = NULL, duplicate_reason = NULL, duplicate_similarity = NULL WHERE duplicate_of IS NOT NULL`)
+	if err != nil {
+		log.Error().Err(err).Msg("Error clearing duplicate marks")
+		return err
+	}
+
+	stmt, err := tx.Prepare(`UPDATE Review SET duplicate_of = ?, duplicate_reason = ?, duplicate_similarity = ? WHERE rating_id = ?`)
+	if err != nil {
+		log.Error().Err(err).Msg("Error preparing duplicate mark statement")
+		return err
+	}
+	defer stmt.Close()
+
+	for _, duplicate := range duplicates {
+		if _, err := stmt.Exec(duplicate.DuplicateOf, duplicate.Reason, duplicate.Similarity, duplicate.RatingID); err != nil {
+			log.Error().Err(err).Msgf("Error marking review %s as duplicate", duplicate.RatingID)
+			return err
+		}
+	}
+
+	if err := markReviewsDirty(tx, "r.duplicate_of IS NOT NULL"); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error().Err(err).Msg("Error committing duplicate marks")
+		return err
+	}
+	return nil
+}
+
+func (r *repository) FindDuplicateClusters(restaurantID string, page int) (*model.DuplicateReport, error) {
+	offset := (page - 1) * constant.DuplicateClustersPerPage
+	limit := constant.DuplicateClustersPerPage
+
+	where := "duplicate_of IS NOT NULL"
+	var args []interface{}
+	if restaurantID != "" {
+		where += " AND restaurant_id = ?"
+		args = append(args, restaurantID)
+	}
+
+	report := &model.DuplicateReport{Clusters: []model.DuplicateCluster{}}
+	err := r.db.QueryRow(`SELECT COUNT(DISTINCT duplicate_of), COUNT(*) FROM Review WHERE `+where, args...).
+		Scan(&report.TotalClusters, &report.TotalDuplicates)
+	if err != nil {
+		log.Error().Err(err).Msg("Error counting duplicate clusters")
+		return nil, err
+	}
+
+	// Largest clusters first
+	query := `SELECT duplicate_of
+	FROM Review
+	WHERE ` + where + `
+	GROUP BY duplicate_of
+	ORDER BY COUNT(*) DESC, duplicate_of
+	LIMIT ? OFFSET ?`
+	rows, err := r.db.Query(query, append(args, limit, offset)...)
+	if err != nil {
+		log.Error().Err(err).Msg("Error executing query to find duplicate clusters")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var canonicalIDs []interface{}
+	index := map[string]int{}
+	for rows.Next() {
+		var canonicalID string
+		if err := rows.Scan(&canonicalID); err != nil {
+			log.Error().Err(err).Msg("Error scanning duplicate cluster data")
+			return nil, err
+		}
+		index[canonicalID] = len(report.Clusters)
+		canonicalIDs = append(canonicalIDs, canonicalID)
+		report.Clusters = append(report.Clusters, model.DuplicateCluster{Duplicates: []model.DuplicateReview{}})
+	}
+	if len(canonicalIDs) == 0 {
+		return report, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(canonicalIDs)), ",")
+	memberQuery := `SELECT
+		r.rating_id,
+		r.restaurant_id,
+		u.user_name,
+		p.platform_name,
+		r.rating,
+		r.feedback,
+		r.review_time,
+		r.duplicate_of,
+		r.duplicate_reason,
+		r.duplicate_similarity
+	FROM Review r
+	JOIN User u ON r.user_id = u.user_id
+	JOIN Platform p ON u.platform_id = p.platform_id
+	WHERE r.rating_id IN (` + placeholders + `) OR r.duplicate_of IN (` + placeholders + `)
+	ORDER BY r.review_time`
+	memberRows, err := r.db.Query(memberQuery, append(canonicalIDs, canonicalIDs...)...)
+	if err != nil {
+		log.Error().Err(err).Msg("Error executing query to find duplicate cluster members")
+		return nil, err
+	}
+	defer memberRows.Close()
+
+	for memberRows.Next() {
+		var review model.Review
+		var restaurantID string
+		var feedback, duplicateOf, reason sql.NullString
+		var similarity sql.NullFloat64
+		var reviewTime sql.NullTime
+		if err := memberRows.Scan(
+			&review.RatingID,
+			&restaurantID,
+			&review.UserName,
+			&review.Platform,
+			&review.Rating,
+			&feedback,
+			&reviewTime,
+			&duplicateOf,
+			&reason,
+			&similarity,
+		); err != nil {
+			log.Error().Err(err).Msg("Error scanning duplicate cluster member data")
+			return nil, err
+		}
+		review.Feedback = feedback.String
+		if reviewTime.Valid {
+			review.ReviewTime = reviewTime.Time.Format(time.RFC3339)
+		}
+
+		if !duplicateOf.Valid {
+			cluster := &report.Clusters[index[review.RatingID]]
+			cluster.RestaurantID = restaurantID
+			cluster.Canonical = review
+			continue
+		}
+		cluster := &report.Clusters[index[duplicateOf.String]]
+		cluster.Duplicates = append(cluster.Duplicates, model.DuplicateReview{
+			Review:     review,
+			Reason:     reason.String,
+			Similarity: similarity.Float64,
+		})
+	}
+
+	return report, nil
+}
@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"math"
+	"skeleton-internship-backend/internal/constant"
+	"skeleton-internship-backend/internal/model"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// snapshotLabels lists the labels stored in rating snapshots, each in a <label>_rating column
+var snapshotLabels = append(append([]string{}, constant.AspectLabels...), constant.LabelUnknown)
+
+// labelRatingExpr is the SQL rating of a label aggregated from the label stats (aliased ls)
+// of a restaurant, with unknown ratings counted toward it as FindLabelsRating does
+func (r *repository) labelRatingExpr(label string) string {
+	sum := "SUM(IF(ls.label = '" + label + "', ls.rating_sum, 0))"
+	weight := "SUM(IF(ls.label = '" + label + "', ls.weight, 0))"
+	if label != constant.LabelUnknown && r.cfg.Rating.SpreadsUnknown() {
+		sum += " + SUM(IF(ls.label = 'unknown', ls.rating_sum, 0))"
+		weight += " + SUM(IF(ls.label = 'unknown', ls.weight, 0))"
+	}
+	return "ROUND((" + sum + ") / NULLIF(" + weight + ", 0), 2)"
+}
+
+// ratingSettings returns the rating configuration recorded with snapshots
+func (r *repository) ratingSettings() model.RatingSettings {
+	rating := r.cfg.Rating
+	aspectWeights := rating.AspectWeights
+	if aspectWeights == nil {
+		aspectWeights = map[string]float64{}
+	}
+	return model.RatingSettings{
+		Strategy:          rating.Strategy,
+		PriorWeight:       rating.PriorWeight,
+		PriorScope:        rating.PriorScope,
+		DecayHalfLifeDays: rating.DecayHalfLifeDays,
+		AspectWeights:     aspectWeights,
+		SuspiciousPolicy:  rating.SuspiciousPolicy,
+		SuspiciousWeight:  rating.SuspiciousWeight,
+		UnknownPolicy:     rating.UnknownPolicy,
+	}
+}
+
+// snapshotRatings records the review count, rating and label ratings of every restaurant
+// under the given recalculation version, with the rating settings in use, inside the
+// transaction that applied them
+func (r *repository) snapshotRatings(tx *sql.Tx, version int) error {
+	columns := make([]string, len(snapshotLabels))
+	ratings := make([]string, len(snapshotLabels))
+	for i, label := range snapshotLabels {
+		columns[i] = label + "_rating"
+		ratings[i] = r.labelRatingExpr(label)
+	}
+
+	query := `INSERT INTO Restaurant_rating_snapshot
+	(restaurant_id, rating_version, strategy, settings, restaurant_rating, review_count, ` + strings.Join(columns, ", ") + `)
+	SELECT res.restaurant_id, ?, ?, ?, res.restaurant_rating, res.review_count, ` + strings.Join(ratings, ", ") + `
+	FROM Restaurant res
+	LEFT JOIN Restaurant_label_stats ls ON ls.restaurant_id = res.restaurant_id
+	GROUP BY res.restaurant_id, res.restaurant_rating, res.review_count`
+	settings, err := json.Marshal(r.ratingSettings())
+	if err != nil {
+		log.Error().Err(err).Msg("Error encoding rating settings")
+		return err
+	}
+	if _, err := tx.Exec(query, version, r.cfg.Rating.Strategy, settings); err != nil {
+		log.Error().Err(err).Msgf("Error snapshotting ratings of version %d", version)
+		return err
+	}
+	return nil
+}
+
+func (r *repository) FindRatingHistory(id string, page int) (*model.RatingHistory, error) {
+	offset := (page - 1) * constant.RatingHistoryPerPage
+	limit := constant.RatingHistoryPerPage
+
+	history := &model.RatingHistory{RestaurantID: id, Snapshots: []model.RatingSnapshot{}}
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM Restaurant_rating_snapshot WHERE restaurant_id = ?`, id).Scan(&history.Total); err != nil {
+		log.Error().Err(err).Msg("Error counting rating snapshots")
+		return nil, err
+	}
+
+	columns := make([]string, len(snapshotLabels))
+	for i, label := range snapshotLabels {
+		columns[i] = label + "_rating"
+	}
+	query := `SELECT rating_version, strategy, settings, restaurant_rating, review_count, ` + strings.Join(columns, ", ") + `, created_at
+	FROM Restaurant_rating_snapshot
+	WHERE restaurant_id = ?
+	ORDER BY rating_version DESC
+	LIMIT ? OFFSET ?`
+	rows, err := r.db.Query(query, id, limit, offset)
+	if err != nil {
+		log.Error().Err(err).Msg("Error executing query to find rating history")
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		snapshot := model.RatingSnapshot{Labels: map[string]float64{}}
+		var rating sql.NullFloat64
+		labelRatings := make([]sql.NullFloat64, len(snapshotLabels))
+		var settings []byte
+		var createdAt time.Time
+		dest := []interface{}{&snapshot.Version, &snapshot.Strategy, &settings, &rating, &snapshot.ReviewCount}
+		for i := range labelRatings {
+			dest = append(dest, &labelRatings[i])
+		}
+		dest = append(dest, &createdAt)
+		if err := rows.Scan(dest...); err != nil {
+			log.Error().Err(err).Msg("Error scanning rating snapshot data")
+			return nil, err
+		}
+		if err := json.Unmarshal(settings, &snapshot.Settings); err != nil {
+			log.Error().Err(err).Msg("Error decoding snapshot rating settings")
+			return nil, err
+		}
+		snapshot.Rating = rating.Float64
+		// Labels without any rating are left out
+		for i, label := range snapshotLabels {
+			if labelRatings[i].Valid {
+				snapshot.Labels[label] = labelRatings[i].Float64
+			}
+		}
+		snapshot.CreatedAt = createdAt.Format(time.RFC3339)
+		history.Snapshots = append(history.Snapshots, snapshot)
+	}
+
+	return history, nil
+}
+
+// FindRatingMovers compares the snapshots of two recalculation versions and returns the
+// restaurants whose rating changed the most. A zero toVersion means the latest version and
+// a zero fromVersion the one before toVersion; "not found" is returned when there is
+// nothing to compare.
+func (r *repository) FindRatingMovers(fromVersion int, toVersion int, limit int) (*model.RatingMovers, error) {
+	if toVersion == 0 {
+		var latest sql.NullInt64
+		if err := r.db.QueryRow(`SELECT MAX(rating_version) FROM Restaurant_rating_snapshot`).Scan(&latest); err != nil {
+			log.Error().Err(err).Msg("Error finding the latest snapshot version")
+			return nil, err
+		}
+		if !latest.Valid {
+			return nil, errors.New("not found")
+		}
+		toVersion = int(latest.Int64)
+	}
+	if fromVersion == 0 {
+		var previous sql.NullInt64
+		err := r.db.QueryRow(`SELECT MAX(rating_version) FROM Restaurant_rating_snapshot WHERE rating_version < ?`, toVersion).Scan(&previous)
+		if err != nil {
+			log.Error().Err(err).Msg("Error finding the previous snapshot version")
+			return nil, err
+		}
+		if !previous.Valid {
+			return nil, errors.New("not found")
+		}
+		fromVersion = int(previous.Int64)
+	}
+
+	var found int
+	err := r.db.QueryRow(`SELECT COUNT(DISTINCT rating_version) FROM Restaurant_rating_snapshot WHERE rating_version IN (?, ?)`,
+		fromVersion, toVersion).Scan(&found)
+	if err != nil {
+		log.Error().Err(err).Msg("Error checking snapshot versions")
+		return nil, err
+	}
+	expected := 2
+	if fromVersion == toVersion {
+		expected = 1
+	}
+	if found < expected {
+		return nil, errors.New("not found")
+	}
+
+	// Restaurants missing from either snapshot are left out
+	query := `SELECT t.restaurant_id, res.restaurant_name, f.restaurant_rating, t.restaurant_rating, f.review_count, t.review_count
+	FROM Restaurant_rating_snapshot t
+	JOIN Restaurant_rating_snapshot f ON f.restaurant_id = t.restaurant_id AND f.rating_version = ?
+	JOIN Restaurant res ON res.restaurant_id = t.restaurant_id
+	WHERE t.rating_version = ? AND NOT (t.restaurant_rating <=> f.restaurant_rating)
+	ORDER BY ABS(COALESCE(t.restaurant_rating, 0) - COALESCE(f.restaurant_rating, 0)) DESC, t.restaurant_id
+	LIMIT ?`
+	rows, err := r.db.Query(query, fromVersion, toVersion, limit)
+	if err != nil {
+		log.Error().Err(err).Msg("Error executing query to find rating movers")
+		return nil, err
+	}
+	defer rows.Close()
+
+	movers := &model.RatingMovers{FromVersion: fromVersion, ToVersion: toVersion, Movers: []model.RatingMover{}}
+	for rows.Next() {
+		var mover model.RatingMover
+		var oldRating, rating sql.NullFloat64
+		if err := rows.Scan(&mover.RestaurantID, &mover.RestaurantName, &oldRating, &rating, &mover.OldReviewCount, &mover.ReviewCount); err != nil {
+			log.Error().Err(err).Msg("Error scanning rating mover data")
+			return nil, err
+		}
+		mover.OldRating = oldRating.Float64
+		mover.Rating = rating.Float64
+		mover.Change = math.Round((mover.Rating-mover.OldRating)*100) / 100
+		movers.Movers = append(movers.Movers, mover)
+	}
+
+	return movers, nil
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"skeleton-internship-backend/internal/constant"
+	"skeleton-internship-backend/internal/model"
+
+	"github.com/rs/zerolog/log"
+)
+
+func (r *repository) FindLabeledFeedbackByRestaurantID(id string) ([]model.LabeledFeedback, error) {
+	query := `SELECT r.rating_id, r.feedback, fl.label, fl.rating_label
+	FROM Review r
+	JOIN Feedback_label fl ON fl.rating_id = r.rating_id
+	WHERE r.restaurant_id = ? AND r.feedback IS NOT NULL AND r.feedback <> '' AND ` + countedReview
+	rows, err := r.db.Query(query, id)
+	if err != nil {
+		log.Error().Err(err).Msg("Error executing query to find labeled feedback")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var feedbacks []model.LabeledFeedback
+	for rows.Next() {
+		var feedback model.LabeledFeedback
+		if err := rows.Scan(&feedback.RatingID, &feedback.Feedback, &feedback.Label, &feedback.RatingLabel); err != nil {
+			log.Error().Err(err).Msg("Error scanning labeled feedback data")
+			return nil, err
+		}
+		feedbacks = append(feedbacks, feedback)
+	}
+
+	return feedbacks, nil
+}
+
+// SaveRestaurantKeywords replaces the keywords of a restaurant
+func (r *repository) SaveRestaurantKeywords(restaurantID string, keywords []model.RestaurantKeyword) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		log.Error().Err(err).Msg("Error starting transaction to save keywords")
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM Restaurant_keyword WHERE restaurant_id = ?`, restaurantID); err != nil {
+		log.Error().Err(err).Msgf("Error clearing keywords of restaurant %s", restaurantID)
+		return err
+	}
+
+	for _, keyword := range keywords {
+		_, err := tx.Exec(`INSERT INTO Restaurant_keyword (restaurant_id, label, sentiment, phrase, review_count) VALUES (?, ?, ?, ?, ?)`,
+			restaurantID, keyword.Label, keyword.Sentiment, keyword.Phrase, keyword.ReviewCount)
+		if err != nil {
+			log.Error().Err(err).Msgf("Error inserting keyword %q of restaurant %s", keyword.Phrase, restaurantID)
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error().Err(err).Msg("Error committing keywords")
+		return err
+	}
+	return nil
+}
+
+func (r *repository) FindKeywordsByRestaurantID(id string) (*model.RestaurantKeywords, error) {
+	log.Info().Msgf("Finding keywords for restaurant ID: %s", id)
+
+	query := `SELECT label, sentiment, phrase, review_count
+	FROM Restaurant_keyword
+	WHERE restaurant_id = ?
+	ORDER BY review_count DESC, phrase`
+	rows, err := r.db.Query(query, id)
+	if err != nil {
+		log.Error().Err(err).Msg("Error executing query to find keywords")
+		return nil, err
+	}
+	defer rows.Close()
+
+	keywords := &model.RestaurantKeywords{RestaurantID: id, Labels: map[string]model.KeywordGroup{}}
+	for rows.Next() {
+		var label, sentiment string
+		var keyword model.Keyword
+		if err := rows.Scan(&label, &sentiment, &keyword.Phrase, &keyword.ReviewCount); err != nil {
+			log.Error().Err(err).Msg("Error scanning keyword data")
+			return nil, err
+		}
+
+		group, ok := keywords.Labels[label]
+		if !ok {
+			group = model.KeywordGroup{Positive: []model.Keyword{}, Negative: []model.Keyword{}}
+		}
+		if sentiment == constant.KeywordSentimentPositive {
+			group.Positive = append(group.Positive, keyword)
+		} else {
+			group.Negative = append(group.Negative, keyword)
+		}
+		keywords.Labels[label] = group
+	}
+
+	return keywords, nil
+}
@@ -5,126 +5,92 @@ import (
 	"errors"
 	"skeleton-internship-backend/internal/constant"
 	"skeleton-internship-backend/internal/model"
+	"strings"
 	"time"
 
 	"github.com/rs/zerolog/log"
 )
 
-func (r *repository) CalculateLabelsRating(id string) (float64, int, float64, int, float64, int, float64, int, float64, int, error) {
-	query := `SELECT label, SUM(rating_label), COUNT(*) 
-	FROM Review JOIN Feedback_label ON Review.rating_id = Feedback_label.rating_id 
-	WHERE restaurant_id = ? GROUP BY label`
-	rows, err := r.db.Query(query, id)
-	if err != nil {
-		log.Error().Err(err).Msg("Error executing query to calculate labels rating")
-		return 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, err
-	}
-	defer rows.Close()
-
-	var ambienceRating, deliveryRating, foodRating, priceRating, serviceRating float64
-	var ambienceCount, deliveryCount, foodCount, priceCount, serviceCount int
+func (r *repository) FindReviewsByRestaurantID(id string, filter model.ReviewFilter, page int, isCount bool) ([]model.Review, int, error) {
+	log.Info().Msgf("Finding reviews for restaurant ID: %s with filter: %+v on page: %d, isCount: %v", id, filter, page, isCount)
 
-	for rows.Next() {
-		var label string
-		var rating float64
-		var count int
-		if err := rows.Scan(&label, &rating, &count); err != nil {
-			log.Error().Err(err).Msg("Error scanning label rating data")
-			return 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, err
-		}
+	// Calculate offset based on page number (constant reviews per page)
+	offset := (page - 1) * constant.NumberofRestaurantsperPage
+	limit := constant.NumberofRestaurantsperPage
 
-		switch label {
-		case "ambience":
-			ambienceRating += rating
-			ambienceCount += count
-		case "delivery":
-			deliveryRating += rating
-			deliveryCount += count
-		case "food":
-			foodRating += rating
-			foodCount += count
-		case "price":
-			priceRating += rating
-			priceCount += count
-		case "service":
-			serviceRating += rating
-			serviceCount += count
-		case "unknown":
-			ambienceRating += rating
-			ambienceCount += count
-			deliveryRating += rating
-			deliveryCount += count
-			foodRating += rating
-			foodCount += count
-			priceRating += rating
-			priceCount += count
-			serviceRating += rating
-			serviceCount += count
-		}
-	}
+	whereConditions := []string{"r.restaurant_id = ?", visibleReview}
+	args := []interface{}{id}
 
-	if ambienceCount > 0 {
-		ambienceRating = float64(ambienceRating) / float64(ambienceCount)
+	// A review matches a label when it rates that label or is unlabeled (unknown)
+	if filter.Label != "" {
+		whereConditions = append(whereConditions, `EXISTS (
+			SELECT 1 FROM Feedback_label fl
+			WHERE fl.rating_id = r.rating_id AND (fl.label = ? OR fl.label = 'unknown'))`)
+		args = append(args, filter.Label)
 	}
-	if deliveryCount > 0 {
-		deliveryRating = float64(deliveryRating) / float64(deliveryCount)
+	if filter.MinRating > 0 {
+		whereConditions = append(whereConditions, "r.rating >= ?")
+		args = append(args, filter.MinRating)
 	}
-	if foodCount > 0 {
-		foodRating = float64(foodRating) / float64(foodCount)
+	if filter.MaxRating > 0 {
+		whereConditions = append(whereConditions, "r.rating <= ?")
+		args = append(args, filter.MaxRating)
 	}
-	if priceCount > 0 {
-		priceRating = float64(priceRating) / float64(priceCount)
+	if filter.Platform != "" {
+		whereConditions = append(whereConditions, "p.platform_name = ?")
+		args = append(args, filter.Platform)
 	}
-	if serviceCount > 0 {
-		serviceRating = float64(serviceRating) / float64(serviceCount)
+	if filter.TextOnly {
+		whereConditions = append(whereConditions, "r.feedback IS NOT NULL")
 	}
 
-	return ambienceRating, ambienceCount, deliveryRating, deliveryCount, foodRating, foodCount, priceRating, priceCount, serviceRating, serviceCount, nil
-}
-
-func (r *repository) FindReviewsByRestaurantIDAndLabel(id string, label string, page int, isCount bool, textOnly bool) ([]model.Review, int, error) {
-	log.Info().Msgf("Finding reviews for restaurant ID: %s with label: %s on page: %d, isCount: %v, textOnly: %v", id, label, page, isCount, textOnly)
+	var orderBy string
+	switch filter.Sort {
+	case constant.ReviewSortOldest:
+		orderBy = "r.review_time ASC"
+	case constant.ReviewSortHighest:
+		orderBy = "r.rating DESC, r.review_time DESC"
+	case constant.ReviewSortLowest:
+		orderBy = "r.rating ASC, r.review_time DESC"
+	case constant.ReviewSortHelpful:
+		orderBy = "r.helpful_count - r.unhelpful_count DESC, r.helpful_count DESC, r.review_time DESC"
+	default:
+		orderBy = "r.review_time DESC"
+	}
 
-	// Calculate offset based on page number (constant reviews per page)
-	offset := (page - 1) * constant.NumberofRestaurantsperPage
-	limit := constant.NumberofRestaurantsperPage
+	fromClause := `
+		FROM 
+			Review r
+		JOIN 
+			User u ON r.user_id = u.user_id
+		JOIN 
+			Platform p ON u.platform_id = p.platform_id
+		WHERE 
+			` + strings.Join(whereConditions, " AND ")
 
 	// Query to get reviews with pagination
 	query := `
 		SELECT 
 			r.rating_id,
 			u.user_name,
+			p.platform_name,
 			r.rating,
 			r.feedback,
 			r.review_time,
-			fl.label,
-			fl.rating_label
-		FROM 
-			Review r
-		JOIN 
-			User u ON r.user_id = u.user_id
-		JOIN 
-			Feedback_label fl ON r.rating_id = fl.rating_id
-		WHERE 
-			r.restaurant_id = ? 
-			AND (fl.label = ? OR fl.label = 'unknown')`
-
-	if textOnly {
-		query += ` AND r.feedback IS NOT NULL`
-	}
-	query += `
+			r.helpful_count,
+			r.unhelpful_count` + fromClause + `
 		ORDER BY 
-			r.review_time DESC
+			` + orderBy + `
 		LIMIT ? OFFSET ?`
 
-	rows, err := r.db.Query(query, id, label, limit, offset)
+	rows, err := r.db.Query(query, append(args, limit, offset)...)
 	if err != nil {
 		log.Error().Err(err).Msg("Error executing query to find reviews")
 		return nil, 0, err
 	}
 	defer rows.Close()
 
-	var reviews []model.Review
+	reviews := []model.Review{}
 	for rows.Next() {
 		var review model.Review
 		var reviewTime sql.NullTime
@@ -133,11 +99,12 @@ func (r *repository) FindReviewsByRestaurantIDAndLabel(id string, label string,
 		if err := rows.Scan(
 			&review.RatingID,
 			&review.UserName,
+			&review.Platform,
 			&review.Rating,
 			&feedback,
 			&reviewTime,
-			&review.Label,
-			&review.RatingLabel,
+			&review.HelpfulCount,
+			&review.UnhelpfulCount,
 		); err != nil {
 			log.Error().Err(err).Msg("Error scanning review data")
 			return nil, 0, err
@@ -158,21 +125,18 @@ func (r *repository) FindReviewsByRestaurantIDAndLabel(id string, label string,
 		reviews = append(reviews, review)
 	}
 
+	if err := r.attachReviewLabels(reviews, filter.Label); err != nil {
+		return nil, 0, err
+	}
+
 	var totalReviews int
 
 	if isCount {
 		countQuery := `
 			SELECT 
-				COUNT(*)
-			FROM 
-				Review r
-			JOIN 
-				Feedback_label fl ON r.rating_id = fl.rating_id
-			WHERE 
-				r.restaurant_id = ? 
-				AND (fl.label = ? OR fl.label = 'unknown')`
-
-		err = r.db.QueryRow(countQuery, id, label).Scan(&totalReviews)
+				COUNT(*)` + fromClause
+
+		err = r.db.QueryRow(countQuery, args...).Scan(&totalReviews)
 		if err != nil {
 			log.Error().Err(err).Msg("Error executing query to count reviews")
 			return nil, 0, err
@@ -182,6 +146,53 @@ func (r *repository) FindReviewsByRestaurantIDAndLabel(id string, label string,
 	return reviews, totalReviews, nil
 }
 
+// attachReviewLabels loads the labels of every review in one query. When a label
+// filter is given, Label and RatingLabel are set to the matching label, falling
+// back to unknown, so clients reading a single label keep working.
+func (r *repository) attachReviewLabels(reviews []model.Review, label string) error {
+	if len(reviews) == 0 {
+		return nil
+	}
+
+	placeholders := make([]string, len(reviews))
+	args := make([]interface{}, len(reviews))
+	index := make(map[string]int, len(reviews))
+	for i, review := range reviews {
+		placeholders[i] = "?"
+		args[i] = review.RatingID
+		index[review.RatingID] = i
+	}
+
+	query := `SELECT rating_id, label, rating_label 
+	FROM Feedback_label 
+	WHERE rating_id IN (` + strings.Join(placeholders, ",") + `) 
+	ORDER BY feedback_label_id`
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		log.Error().Err(err).Msg("Error executing query to find review labels")
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var ratingID string
+		var reviewLabel model.ReviewLabel
+		if err := rows.Scan(&ratingID, &reviewLabel.Label, &reviewLabel.RatingLabel); err != nil {
+			log.Error().Err(err).Msg("Error scanning review label data")
+			return err
+		}
+
+		review := &reviews[index[ratingID]]
+		review.Labels = append(review.Labels, reviewLabel)
+		if label != "" && (reviewLabel.Label == label || (reviewLabel.Label == constant.LabelUnknown && review.Label == "")) {
+			review.Label = reviewLabel.Label
+			review.RatingLabel = reviewLabel.RatingLabel
+		}
+	}
+
+	return nil
+}
+
 func (r *repository) CountReviewsByRestaurantID(id string) (int, error) {
 	query := `SELECT COUNT(*) FROM Review WHERE restaurant_id = ?`
 	row := r.db.QueryRow(query, id)
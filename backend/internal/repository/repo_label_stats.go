@@ -0,0 +1,234 @@
+package repository
+
+import (
+	"database/sql"
+	"strings"
+
+	"skeleton-internship-backend/internal/constant"
+	"skeleton-internship-backend/internal/model"
+
+	"github.com/rs/zerolog/log"
+)
+
+// labelStatsQuery builds the statement filling a label stats table, Restaurant_label_stats
+// or one of its staging tables, from the weighted label ratings of counted reviews, with
+// its arguments. An empty restaurant ID fills it for every restaurant.
+func (r *repository) labelStatsQuery(table string, restaurantID string) (string, []interface{}) {
+	query, args := r.labelStatsSelect(restaurantID)
+	return `INSERT INTO ` + table + ` (restaurant_id, label, rating_sum, weight, review_count, average_rating)
+	` + query, args
+}
+
+// labelStatsSelect is the aggregation behind labelStatsQuery, with columns named after
+// those of Restaurant_label_stats. Every restaurant gets a row for every aspect and the
+// unknown label, empty when it has no such ratings, so restaurants without stats are
+// restaurants not recalculated yet rather than restaurants without labeled reviews.
+func (r *repository) labelStatsSelect(restaurantID string) (string, []interface{}) {
+	labels := make([]string, 0, len(snapshotLabels))
+	for _, label := range snapshotLabels {
+		labels = append(labels, "SELECT '"+label+"' AS label")
+	}
+
+	aggregationFilter, restaurantFilter := "", ""
+	var args []interface{}
+	if restaurantID != "" {
+		aggregationFilter = " AND r.restaurant_id = ?"
+		restaurantFilter = " WHERE res.restaurant_id = ?"
+		args = []interface{}{restaurantID, restaurantID}
+	}
+
+	weight := r.reviewWeight()
+	return `SELECT
+		res.restaurant_id,
+		l.label,
+		COALESCE(ls.rating_sum, 0) AS rating_sum,
+		COALESCE(ls.weight, 0) AS weight,
+		COALESCE(ls.review_count, 0) AS review_count,
+		ls.average_rating
+	FROM Restaurant res
+	CROSS JOIN (` + strings.Join(labels, " UNION ALL ") + `) l
+	LEFT JOIN (
+		SELECT
+			r.restaurant_id,
+			fl.label,
+			SUM(fl.rating_label * ` + weight + `) AS rating_sum,
+			SUM(` + weight + `) AS weight,
+			COUNT(*) AS review_count,
+			CASE WHEN SUM(` + weight + `) > 0 THEN SUM(fl.rating_label * ` + weight + `) / SUM(` + weight + `) ELSE NULL END AS average_rating
+		FROM Feedback_label fl
+		JOIN Review r ON fl.rating_id = r.rating_id
+		LEFT JOIN Review_flag rf ON rf.rating_id = r.rating_id
+		WHERE ` + countedReview + aggregationFilter + `
+		GROUP BY r.restaurant_id, fl.label
+	) ls ON ls.restaurant_id = res.restaurant_id AND ls.label = l.label` + restaurantFilter, args
+}
+
+// refreshLabelStats rebuilds the label stats of one restaurant inside the given transaction
+func (r *repository) refreshLabelStats(tx *sql.Tx, id string) error {
+	if _, err := tx.Exec(`DELETE FROM Restaurant_label_stats WHERE restaurant_id = ?`, id); err != nil {
+		log.Error().Err(err).Msgf("Error clearing label stats of restaurant %s", id)
+		return err
+	}
+	query, args := r.labelStatsQuery("Restaurant_label_stats", id)
+	if _, err := tx.Exec(query, args...); err != nil {
+		log.Error().Err(err).Msgf("Error updating label stats of restaurant %s", id)
+		return err
+	}
+	return nil
+}
+
+// labelStats holds the weighted sum, total weight and review count of one label
+type labelStats struct {
+	sum    float64
+	weight float64
+	count  int
+}
+
+// newLabelStats returns empty stats for every aspect and the unknown label
+func newLabelStats() map[string]*labelStats {
+	stats := map[string]*labelStats{constant.LabelUnknown: {}}
+	for _, label := range constant.AspectLabels {
+		stats[label] = &labelStats{}
+	}
+	return stats
+}
+
+// FindLabelsRating reads the label ratings of a restaurant from the precomputed
+// label stats and applies the unknown policy
+func (r *repository) FindLabelsRating(id string) (*model.LabelsRating, error) {
+	query := `SELECT label, rating_sum, weight, review_count FROM Restaurant_label_stats WHERE restaurant_id = ?`
+	rows, err := r.db.Query(query, id)
+	if err != nil {
+		log.Error().Err(err).Msg("Error executing query to find labels rating")
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := newLabelStats()
+	for rows.Next() {
+		var label string
+		var sum, weight float64
+		var count int
+		if err := rows.Scan(&label, &sum, &weight, &count); err != nil {
+			log.Error().Err(err).Msg("Error scanning label rating data")
+			return nil, err
+		}
+		if stat, ok := stats[label]; ok {
+			stat.sum += sum
+			stat.weight += weight
+			stat.count += count
+		}
+	}
+
+	return r.labelsRating(stats), nil
+}
+
+// FindLabelsRatingByPlatform computes the label ratings of a restaurant from the counted
+// reviews of each platform, weighted and with the unknown policy applied as FindLabelsRating,
+// along with the number of counted reviews per platform
+func (r *repository) FindLabelsRatingByPlatform(id string) ([]model.PlatformLabelsRating, error) {
+	countQuery := `SELECT p.platform_name, COUNT(*)
+	FROM Review r
+	JOIN User u ON r.user_id = u.user_id
+	JOIN Platform p ON u.platform_id = p.platform_id
+	WHERE r.restaurant_id = ? AND ` + countedReview + `
+	GROUP BY p.platform_id, p.platform_name
+	ORDER BY p.platform_id`
+	countRows, err := r.db.Query(countQuery, id)
+	if err != nil {
+		log.Error().Err(err).Msg("Error executing query to count reviews by platform")
+		return nil, err
+	}
+	defer countRows.Close()
+
+	var platforms []string
+	reviewCounts := map[string]int{}
+	stats := map[string]map[string]*labelStats{}
+	for countRows.Next() {
+		var platform string
+		var count int
+		if err := countRows.Scan(&platform, &count); err != nil {
+			log.Error().Err(err).Msg("Error scanning platform review count data")
+			return nil, err
+		}
+		platforms = append(platforms, platform)
+		reviewCounts[platform] = count
+		stats[platform] = newLabelStats()
+	}
+
+	weight := r.reviewWeight()
+	query := `SELECT p.platform_name, fl.label, SUM(fl.rating_label * ` + weight + `), SUM(` + weight + `), COUNT(*)
+	FROM Feedback_label fl
+	JOIN Review r ON fl.rating_id = r.rating_id
+	JOIN User u ON r.user_id = u.user_id
+	JOIN Platform p ON u.platform_id = p.platform_id
+	LEFT JOIN Review_flag rf ON rf.rating_id = r.rating_id
+	WHERE r.restaurant_id = ? AND ` + countedReview + `
+	GROUP BY p.platform_name, fl.label`
+	rows, err := r.db.Query(query, id)
+	if err != nil {
+		log.Error().Err(err).Msg("Error executing query to find labels rating by platform")
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var platform, label string
+		var sum, weight float64
+		var count int
+		if err := rows.Scan(&platform, &label, &sum, &weight, &count); err != nil {
+			log.Error().Err(err).Msg("Error scanning platform label rating data")
+			return nil, err
+		}
+		if stat, ok := stats[platform][label]; ok {
+			stat.sum += sum
+			stat.weight += weight
+			stat.count += count
+		}
+	}
+
+	breakdown := []model.PlatformLabelsRating{}
+	for _, platform := range platforms {
+		breakdown = append(breakdown, model.PlatformLabelsRating{
+			Platform:    platform,
+			ReviewCount: reviewCounts[platform],
+			Labels:      *r.labelsRating(stats[platform]),
+		})
+	}
+	return breakdown, nil
+}
+
+// labelsRating turns label stats into label ratings, counting unknown ratings toward
+// every aspect when the unknown policy spreads them
+func (r *repository) labelsRating(stats map[string]*labelStats) *model.LabelsRating {
+	unknown := stats[constant.LabelUnknown]
+	rating := func(label string) model.LabelRating {
+		stat := *stats[label]
+		result := model.LabelRating{}
+		// Unknown ratings count toward every aspect when they are spread
+		if label != constant.LabelUnknown && r.cfg.Rating.SpreadsUnknown() {
+			stat.sum += unknown.sum
+			stat.weight += unknown.weight
+			stat.count += unknown.count
+			result.UnknownCount = unknown.count
+			if stat.weight > 0 {
+				result.UnknownShare = unknown.weight / stat.weight
+			}
+		}
+		result.Count = stat.count
+		if stat.weight > 0 {
+			result.Rating = stat.sum / stat.weight
+		}
+		return result
+	}
+
+	return &model.LabelsRating{
+		Ambience:      rating(constant.LabelAmbience),
+		Delivery:      rating(constant.LabelDelivery),
+		Food:          rating(constant.LabelFood),
+		Price:         rating(constant.LabelPrice),
+		Service:       rating(constant.LabelService),
+		Unknown:       rating(constant.LabelUnknown),
+		UnknownPolicy: r.cfg.Rating.UnknownPolicy,
+	}
+}
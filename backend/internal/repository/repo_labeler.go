@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"database/sql"
+	"skeleton-internship-backend/internal/model"
+
+	"github.com/rs/zerolog/log"
+)
+
+// FindUnlabeledReviews returns the oldest reviews without any Feedback_label row
+func (r *repository) FindUnlabeledReviews(limit int) ([]model.UnlabeledReview, error) {
+	query := `SELECT r.rating_id, r.rating, r.feedback
+	FROM Review r
+	WHERE NOT EXISTS (SELECT 1 FROM Feedback_label fl WHERE fl.rating_id = r.rating_id)
+	ORDER BY r.review_time, r.rating_id
+	LIMIT ?`
+	rows, err := r.db.Query(query, limit)
+	if err != nil {
+		log.Error().Err(err).Msg("Error executing query to find unlabeled reviews")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reviews []model.UnlabeledReview
+	for rows.Next() {
+		var review model.UnlabeledReview
+		var feedback sql.NullString
+		if err := rows.Scan(&review.RatingID, &review.Rating, &feedback); err != nil {
+			log.Error().Err(err).Msg("Error scanning unlabeled review data")
+			return nil, err
+		}
+		review.Feedback = feedback.String
+		reviews = append(reviews, review)
+	}
+
+	return reviews, nil
+}
+
+// SaveReviewLabels inserts the labels of a batch of reviews, keyed by rating ID
+func (r *repository) SaveReviewLabels(labels map[string][]model.ReviewLabel) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		log.Error().Err(err).Msg("Error starting transaction to save review labels")
+		return err
+	}
+	defer tx.Rollback()
+
+	// A label already rated on the review keeps its rating
+	stmt, err := tx.Prepare(`INSERT INTO Feedback_label (label, rating_label, rating_id) VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE rating_label = rating_label`)
+	if err != nil {
+		log.Error().Err(err).Msg("Error preparing feedback label statement")
+		return err
+	}
+	defer stmt.Close()
+
+	for ratingID, reviewLabels := range labels {
+		if err := markReviewsDirty(tx, "r.rating_id = ?", ratingID); err != nil {
+			return err
+		}
+		for _, label := range reviewLabels {
+			if _, err := stmt.Exec(label.Label, label.RatingLabel, ratingID); err != nil {
+				log.Error().Err(err).Msgf("Error inserting feedback label of review %s", ratingID)
+				return err
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error().Err(err).Msg("Error committing review labels")
+		return err
+	}
+	return nil
+}
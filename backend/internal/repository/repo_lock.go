@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/rs/zerolog/log"
+)
+
+// AcquireLock takes a MySQL named lock without waiting, so only one replica runs work
+// guarded by the same name. The lock belongs to a dedicated connection and is held
+// until release is called.
+func (r *repository) AcquireLock(ctx context.Context, name string) (func(), bool, error) {
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Error opening connection for lock")
+		return nil, false, err
+	}
+
+	var acquired sql.NullInt64
+	if err := conn.QueryRowContext(ctx, `SELECT GET_LOCK(?, 0)`, name).Scan(&acquired); err != nil {
+		conn.Close()
+		log.Error().Err(err).Msgf("Error acquiring lock %s", name)
+		return nil, false, err
+	}
+	if acquired.Int64 != 1 {
+		conn.Close()
+		return nil, false, nil
+	}
+
+	release := func() {
+		if _, err := conn.ExecContext(context.Background(), `SELECT RELEASE_LOCK(?)`, name); err != nil {
+			log.Error().Err(err).Msgf("Error releasing lock %s", name)
+		}
+		conn.Close()
+	}
+	return release, true, nil
+}
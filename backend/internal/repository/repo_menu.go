@@ -3,14 +3,31 @@ package repository
 import (
 	"database/sql"
 	"errors"
+	"skeleton-internship-backend/internal/constant"
 	"skeleton-internship-backend/internal/model"
+	"strings"
 
 	"github.com/rs/zerolog/log"
 )
 
-func (r *repository) FindDishesByRestaurantID(id string) ([]model.Dish, error) {
-	query := "SELECT item_name, price FROM Dish WHERE restaurant_id = ?"
-	rows, err := r.db.Query(query, id)
+func (r *repository) FindDishesByRestaurantID(id string, searchWords []string, sortBy string) ([]model.Dish, error) {
+	log.Info().Msgf("Finding dishes for restaurant ID: %s with search words: %v, sort: %s", id, searchWords, sortBy)
+
+	whereConditions := []string{"restaurant_id = ?"}
+	args := []interface{}{id}
+
+	// Every search word must appear in the dish name or its category name
+	for _, word := range searchWords {
+		whereConditions = append(whereConditions, "(item_name LIKE ? OR category_name LIKE ?)")
+		args = append(args, "%"+word+"%", "%"+word+"%")
+	}
+
+	query := `SELECT item_name, price, category_id, category_name 
+	FROM Dish 
+	WHERE ` + strings.Join(whereConditions, " AND ") + `
+	ORDER BY ` + menuOrderBy(sortBy)
+
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		log.Error().Err(err).Msg("Error executing query to find dishes by restaurant ID")
 		return nil, err
@@ -20,15 +37,39 @@ func (r *repository) FindDishesByRestaurantID(id string) ([]model.Dish, error) {
 	var dishes []model.Dish
 	for rows.Next() {
 		var dish model.Dish
-		if err := rows.Scan(&dish.Name, &dish.Price); err != nil {
+		var categoryID sql.NullInt64
+		var categoryName sql.NullString
+		if err := rows.Scan(&dish.Name, &dish.Price, &categoryID, &categoryName); err != nil {
 			if err == sql.ErrNoRows {
 				return nil, errors.New("not found")
 			}
 			log.Error().Err(err).Msg("Error scanning dish data")
 			return nil, err
 		}
+		if categoryID.Valid {
+			dish.CategoryID = int(categoryID.Int64)
+		}
+		if categoryName.Valid {
+			dish.CategoryName = categoryName.String
+		}
 		dishes = append(dishes, dish)
 	}
 
 	return dishes, nil
 }
+
+// menuOrderBy returns the ORDER BY clause of a menu. Categories keep their menu order and
+// uncategorized dishes go last. Without a sort, dishes keep the order of their IDs, which
+// are numbers stored as text: they are compared as numbers so "10" comes after "2".
+func menuOrderBy(sortBy string) string {
+	orderBy := "category_id IS NULL, category_id"
+	switch sortBy {
+	case constant.MenuSortPriceAsc:
+		return orderBy + ", price ASC, item_name"
+	case constant.MenuSortPriceDesc:
+		return orderBy + ", price DESC, item_name"
+	case constant.MenuSortName:
+		return orderBy + ", item_name"
+	}
+	return orderBy + ", CAST(dish_id AS UNSIGNED), dish_id"
+}
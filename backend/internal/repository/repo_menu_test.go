@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"testing"
+
+	"skeleton-internship-backend/internal/constant"
+)
+
+func TestMenuOrderBy(t *testing.T) {
+	tests := []struct {
+		sortBy string
+		want   string
+	}{
+		{sortBy: "", want: "category_id IS NULL, category_id, CAST(dish_id AS UNSIGNED), dish_id"},
+		{sortBy: constant.MenuSortPriceAsc, want: "category_id IS NULL, category_id, price ASC, item_name"},
+		{sortBy: constant.MenuSortPriceDesc, want: "category_id IS NULL, category_id, price DESC, item_name"},
+		{sortBy: constant.MenuSortName, want: "category_id IS NULL, category_id, item_name"},
+	}
+	for _, tt := range tests {
+		if got := menuOrderBy(tt.sortBy); got != tt.want {
+			t.Errorf("menuOrderBy(%q) = %s, want %s", tt.sortBy, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"skeleton-internship-backend/internal/constant"
+	"skeleton-internship-backend/internal/model"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/rs/zerolog/log"
+)
+
+// ReportReview records a report on a review and puts visible reviews in the moderation queue
+func (r *repository) ReportReview(ratingID string, reporterHash string, reason string, details string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		log.Error().Err(err).Msg("Error starting transaction to report review")
+		return err
+	}
+	defer tx.Rollback()
+
+	var status string
+	err = tx.QueryRow(`SELECT moderation_status FROM Review WHERE rating_id = ? FOR UPDATE`, ratingID).Scan(&status)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errors.New("not found")
+		}
+		log.Error().Err(err).Msg("Error locking review to report it")
+		return err
+	}
+
+	var detailsValue sql.NullString
+	if details != "" {
+		detailsValue = sql.NullString{String: details, Valid: true}
+	}
+	_, err = tx.Exec(`INSERT INTO Review_report (rating_id, reporter_hash, reason, details) VALUES (?, ?, ?, ?)`,
+		ratingID, reporterHash, reason, detailsValue)
+	if err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
+			return errors.New("already reported")
+		}
+		log.Error().Err(err).Msg("Error inserting review report")
+		return err
+	}
+
+	if status == constant.ModerationVisible {
+		_, err = tx.Exec(`UPDATE Review SET moderation_status = ? WHERE rating_id = ?`, constant.ModerationFlagged, ratingID)
+		if err != nil {
+			log.Error().Err(err).Msg("Error flagging reported review")
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error().Err(err).Msg("Error committing review report")
+		return err
+	}
+	return nil
+}
+
+func (r *repository) FindModerationQueue(status string, page int) (*model.ModerationQueue, error) {
+	offset := (page - 1) * constant.ModerationItemsPerPage
+	limit := constant.ModerationItemsPerPage
+
+	queue := &model.ModerationQueue{Items: []model.ModerationItem{}}
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM Review WHERE moderation_status = ?`, status).Scan(&queue.Total); err != nil {
+		log.Error().Err(err).Msg("Error counting moderation queue")
+		return nil, err
+	}
+
+	// Most reported reviews first
+	query := `SELECT
+		r.rating_id,
+		r.restaurant_id,
+		u.user_name,
+		p.platform_name,
+		r.rating,
+		r.feedback,
+		r.review_time,
+		r.moderation_status,
+		COUNT(rr.report_id) AS report_count,
+		MAX(rr.reported_at)
+	FROM Review r
+	JOIN User u ON r.user_id = u.user_id
+	JOIN Platform p ON u.platform_id = p.platform_id
+	LEFT JOIN Review_report rr ON rr.rating_id = r.rating_id AND rr.resolved = FALSE
+	WHERE r.moderation_status = ?
+	GROUP BY r.rating_id, r.restaurant_id, u.user_name, p.platform_name, r.rating, r.feedback, r.review_time, r.moderation_status
+	ORDER BY report_count DESC, MAX(rr.reported_at) DESC
+	LIMIT ? OFFSET ?`
+	rows, err := r.db.Query(query, status, limit, offset)
+	if err != nil {
+		log.Error().Err(err).Msg("Error executing query to find moderation queue")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ratingIDs []interface{}
+	index := map[string]int{}
+	for rows.Next() {
+		item := model.ModerationItem{Reasons: map[string]int{}}
+		var feedback sql.NullString
+		var reviewTime, lastReportedAt sql.NullTime
+		if err := rows.Scan(
+			&item.RatingID,
+			&item.RestaurantID,
+			&item.UserName,
+			&item.Platform,
+			&item.Rating,
+			&feedback,
+			&reviewTime,
+			&item.Status,
+			&item.ReportCount,
+			&lastReportedAt,
+		); err != nil {
+			log.Error().Err(err).Msg("Error scanning moderation queue data")
+			return nil, err
+		}
+		item.Feedback = feedback.String
+		if reviewTime.Valid {
+			item.ReviewTime = reviewTime.Time.Format(time.RFC3339)
+		}
+		if lastReportedAt.Valid {
+			item.LastReportedAt = lastReportedAt.Time.Format(time.RFC3339)
+		}
+		index[item.RatingID] = len(queue.Items)
+		ratingIDs = append(ratingIDs, item.RatingID)
+		queue.Items = append(queue.Items, item)
+	}
+	if len(ratingIDs) == 0 {
+		return queue, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(ratingIDs)), ",")
+	reasonRows, err := r.db.Query(`SELECT rating_id, reason, COUNT(*)
+	FROM Review_report
+	WHERE resolved = FALSE AND rating_id IN (`+placeholders+`)
+	GROUP BY rating_id, reason`, ratingIDs...)
+	if err != nil {
+		log.Error().Err(err).Msg("Error executing query to find report reasons")
+		return nil, err
+	}
+	defer reasonRows.Close()
+
+	for reasonRows.Next() {
+		var ratingID, reason string
+		var count int
+		if err := reasonRows.Scan(&ratingID, &reason, &count); err != nil {
+			log.Error().Err(err).Msg("Error scanning report reason data")
+			return nil, err
+		}
+		queue.Items[index[ratingID]].Reasons[reason] = count
+	}
+
+	return queue, nil
+}
+
+// ModerateReview sets the moderation status of a review and resolves its reports.
+// The restaurant's review count and rating are refreshed when the review is hidden or shown again.
+func (r *repository) ModerateReview(ratingID string, status string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		log.Error().Err(err).Msg("Error starting transaction to moderate review")
+		return err
+	}
+	defer tx.Rollback()
+
+	var restaurantID string
+	err = tx.QueryRow(`SELECT restaurant_id FROM Review WHERE rating_id = ?`, ratingID).Scan(&restaurantID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errors.New("not found")
+		}
+		log.Error().Err(err).Msg("Error finding review to moderate it")
+		return err
+	}
+
+	// Lock the restaurant row before the review, in the order CreateReview takes them, so
+	// its stats are not refreshed concurrently with a new review of the restaurant
+	var locked string
+	err = tx.QueryRow(`SELECT restaurant_id FROM Restaurant WHERE restaurant_id = ? FOR UPDATE`, restaurantID).Scan(&locked)
+	if err != nil {
+		log.Error().Err(err).Msgf("Error locking restaurant %s to moderate review", restaurantID)
+		return err
+	}
+
+	var previous string
+	err = tx.QueryRow(`SELECT moderation_status FROM Review WHERE rating_id = ? FOR UPDATE`, ratingID).Scan(&previous)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errors.New("not found")
+		}
+		log.Error().Err(err).Msg("Error locking review to moderate it")
+		return err
+	}
+
+	if _, err := tx.Exec(`UPDATE Review SET moderation_status = ? WHERE rating_id = ?`, status, ratingID); err != nil {
+		log.Error().Err(err).Msg("Error updating moderation status")
+		return err
+	}
+	if _, err := tx.Exec(`UPDATE Review_report SET resolved = TRUE WHERE rating_id = ?`, ratingID); err != nil {
+		log.Error().Err(err).Msg("Error resolving review reports")
+		return err
+	}
+
+	if (previous == constant.ModerationHidden) != (status == constant.ModerationHidden) {
+		if err := r.refreshRestaurantStats(tx, restaurantID); err != nil {
+			return err
+		}
+		// Keywords are refreshed by the next incremental recalculation
+		if err := markRestaurantDirty(tx, restaurantID); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error().Err(err).Msg("Error committing review moderation")
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"database/sql"
+	"math"
+	"skeleton-internship-backend/internal/constant"
+	"skeleton-internship-backend/internal/model"
+
+	"github.com/rs/zerolog/log"
+)
+
+// FindPlatformRatingsByRestaurantID returns the ratings of a restaurant on other platforms,
+// normalized to our scale. Platforms calibrated on our rating distribution use their
+// calibration, the others are mapped linearly from their scale.
+func (r *repository) FindPlatformRatingsByRestaurantID(id string) ([]model.PlatformRating, error) {
+	query := `SELECT p.platform_name, t.restaurant_rating, p.scale_min, p.scale_max, p.normalization, c.normalized_rating
+	FROM Temp t
+	JOIN Platform p ON t.platform_id = p.platform_id
+	LEFT JOIN Platform_rating_calibration c ON c.platform_id = t.platform_id AND c.raw_rating = t.restaurant_rating
+	WHERE t.restaurant_id = ?
+	ORDER BY p.platform_id`
+	rows, err := r.db.Query(query, id)
+	if err != nil {
+		log.Error().Err(err).Msg("Error executing query to find platform ratings by restaurant ID")
+		return nil, err
+	}
+	defer rows.Close()
+
+	ratings := []model.PlatformRating{}
+	for rows.Next() {
+		var rating model.PlatformRating
+		var calibrated sql.NullFloat64
+		if err := rows.Scan(&rating.Platform, &rating.Rating, &rating.ScaleMin, &rating.ScaleMax, &rating.Normalization, &calibrated); err != nil {
+			log.Error().Err(err).Msg("Error scanning platform rating data")
+			return nil, err
+		}
+		normalizePlatformRating(&rating, calibrated)
+		ratings = append(ratings, rating)
+	}
+
+	return ratings, nil
+}
+
+// normalizePlatformRating sets the normalized rating of a platform rating and how it was
+// obtained. A rating of a distribution platform without calibration, e.g. one not seen at
+// the last recalculation, falls back to the linear mapping and is marked as such.
+func normalizePlatformRating(rating *model.PlatformRating, calibrated sql.NullFloat64) {
+	if rating.Normalization == constant.PlatformNormalizationDistribution {
+		if calibrated.Valid {
+			rating.NormalizedRating = calibrated.Float64
+			return
+		}
+		rating.Normalization = constant.PlatformNormalizationLinearFallback
+	} else {
+		rating.Normalization = constant.PlatformNormalizationLinear
+	}
+
+	normalized, ok := linearNormalize(rating.Rating, rating.ScaleMin, rating.ScaleMax)
+	if !ok {
+		log.Warn().Msgf("Platform %s has an invalid rating scale %v to %v, not normalizing its rating",
+			rating.Platform, rating.ScaleMin, rating.ScaleMax)
+		rating.Normalization = constant.PlatformNormalizationNone
+		return
+	}
+	rating.NormalizedRating = normalized
+}
+
+// linearNormalize maps a rating from a platform scale to ours, rounded like our ratings.
+// It fails when the scale is empty or reversed.
+func linearNormalize(rating, scaleMin, scaleMax float64) (float64, bool) {
+	if scaleMax <= scaleMin {
+		return 0, false
+	}
+	share := math.Max(0, math.Min(1, (rating-scaleMin)/(scaleMax-scaleMin)))
+	normalized := constant.ReviewMinRating + share*(constant.ReviewMaxRating-constant.ReviewMinRating)
+	return math.Round(normalized*100) / 100, true
+}
+
+func (r *repository) FindPlatformScales() ([]model.PlatformScale, error) {
+	rows, err := r.db.Query(`SELECT platform_id, platform_name, scale_min, scale_max, normalization FROM Platform ORDER BY platform_id`)
+	if err != nil {
+		log.Error().Err(err).Msg("Error executing query to find platform scales")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var scales []model.PlatformScale
+	for rows.Next() {
+		var scale model.PlatformScale
+		if err := rows.Scan(&scale.PlatformID, &scale.Name, &scale.ScaleMin, &scale.ScaleMax, &scale.Normalization); err != nil {
+			log.Error().Err(err).Msg("Error scanning platform scale data")
+			return nil, err
+		}
+		scales = append(scales, scale)
+	}
+
+	return scales, nil
+}
+
+// FindPlatformRatingCounts returns how many restaurants have each rating on a platform, lowest rating first
+func (r *repository) FindPlatformRatingCounts(platformID int) ([]model.RatingCount, error) {
+	return r.findRatingCounts(`SELECT restaurant_rating, COUNT(*)
+	FROM Temp
+	WHERE platform_id = ? AND restaurant_rating IS NOT NULL
+	GROUP BY restaurant_rating
+	ORDER BY restaurant_rating`, platformID)
+}
+
+// FindRestaurantRatingCounts returns how many restaurants have each of our ratings, lowest rating first.
+// Restaurants without counted reviews are left out.
+func (r *repository) FindRestaurantRatingCounts() ([]model.RatingCount, error) {
+	return r.findRatingCounts(`SELECT restaurant_rating, COUNT(*)
+	FROM Restaurant
+	WHERE review_count > 0 AND restaurant_rating IS NOT NULL
+	GROUP BY restaurant_rating
+	ORDER BY restaurant_rating`)
+}
+
+func (r *repository) findRatingCounts(query string, args ...interface{}) ([]model.RatingCount, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		log.Error().Err(err).Msg("Error executing query to find rating counts")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var counts []model.RatingCount
+	for rows.Next() {
+		var count model.RatingCount
+		if err := rows.Scan(&count.Rating, &count.Count); err != nil {
+			log.Error().Err(err).Msg("Error scanning rating count data")
+			return nil, err
+		}
+		counts = append(counts, count)
+	}
+
+	return counts, nil
+}
+
+// SavePlatformCalibration replaces the calibration of a platform, keyed by raw rating
+func (r *repository) SavePlatformCalibration(platformID int, calibration map[float64]float64) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		log.Error().Err(err).Msg("Error starting transaction to save platform calibration")
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM Platform_rating_calibration WHERE platform_id = ?`, platformID); err != nil {
+		log.Error().Err(err).Msgf("Error clearing calibration of platform %d", platformID)
+		return err
+	}
+
+	for raw, normalized := range calibration {
+		_, err := tx.Exec(`INSERT INTO Platform_rating_calibration (platform_id, raw_rating, normalized_rating) VALUES (?, ?, ?)`,
+			platformID, raw, normalized)
+		if err != nil {
+			log.Error().Err(err).Msgf("Error inserting calibration of platform %d", platformID)
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error().Err(err).Msg("Error committing platform calibration")
+		return err
+	}
+	return nil
+}
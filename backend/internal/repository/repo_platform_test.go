@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"database/sql"
+	"testing"
+
+	"skeleton-internship-backend/internal/constant"
+	"skeleton-internship-backend/internal/model"
+)
+
+func TestNormalizePlatformRating(t *testing.T) {
+	tests := []struct {
+		name              string
+		rating            model.PlatformRating
+		calibrated        sql.NullFloat64
+		wantRating        float64
+		wantNormalization string
+	}{
+		{
+			name:              "linear",
+			rating:            model.PlatformRating{Rating: 8, ScaleMin: 0, ScaleMax: 10, Normalization: constant.PlatformNormalizationLinear},
+			wantRating:        4.2,
+			wantNormalization: constant.PlatformNormalizationLinear,
+		},
+		{
+			name:              "linear clamps to the scale",
+			rating:            model.PlatformRating{Rating: 12, ScaleMin: 0, ScaleMax: 10, Normalization: constant.PlatformNormalizationLinear},
+			wantRating:        5,
+			wantNormalization: constant.PlatformNormalizationLinear,
+		},
+		{
+			name:              "calibrated",
+			rating:            model.PlatformRating{Rating: 4, ScaleMin: 1, ScaleMax: 5, Normalization: constant.PlatformNormalizationDistribution},
+			calibrated:        sql.NullFloat64{Float64: 3.1, Valid: true},
+			wantRating:        3.1,
+			wantNormalization: constant.PlatformNormalizationDistribution,
+		},
+		{
+			name:              "not calibrated yet",
+			rating:            model.PlatformRating{Rating: 4, ScaleMin: 1, ScaleMax: 5, Normalization: constant.PlatformNormalizationDistribution},
+			wantRating:        4,
+			wantNormalization: constant.PlatformNormalizationLinearFallback,
+		},
+		{
+			name:              "reversed scale",
+			rating:            model.PlatformRating{Rating: 4, ScaleMin: 5, ScaleMax: 1, Normalization: constant.PlatformNormalizationLinear},
+			wantNormalization: constant.PlatformNormalizationNone,
+		},
+		{
+			name:              "empty scale",
+			rating:            model.PlatformRating{Rating: 4, ScaleMin: 5, ScaleMax: 5, Normalization: constant.PlatformNormalizationDistribution},
+			wantNormalization: constant.PlatformNormalizationNone,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rating := tt.rating
+			normalizePlatformRating(&rating, tt.calibrated)
+			if rating.NormalizedRating != tt.wantRating || rating.Normalization != tt.wantNormalization {
+				t.Errorf("normalized rating = %v (%s), want %v (%s)",
+					rating.NormalizedRating, rating.Normalization, tt.wantRating, tt.wantNormalization)
+			}
+		})
+	}
+}
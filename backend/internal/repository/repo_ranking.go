@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"skeleton-internship-backend/internal/constant"
+	"skeleton-internship-backend/internal/model"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// rankingScopes lists the city, district and food type columns each ranking is partitioned
+// by, a zero standing for any. Districts are unique across cities so they need no city.
+var rankingScopes = []struct {
+	city, district, foodType string
+}{
+	{"0", "0", "0"},
+	{"0", "0", "food_type_id"},
+	{"city_id", "0", "0"},
+	{"city_id", "0", "food_type_id"},
+	{"0", "district_id", "0"},
+	{"0", "district_id", "food_type_id"},
+}
+
+// RefreshRankings rebuilds the rankings of every scope and label under the given
+// recalculation version, keeping the previous rank of each restaurant, and drops the
+// previous rankings in the same transaction
+func (r *repository) RefreshRankings(version int) error {
+	labels := append([]string{constant.RankingLabelOverall}, constant.AspectLabels...)
+
+	columns := []string{"res.restaurant_rating AS overall"}
+	for _, label := range constant.AspectLabels {
+		columns = append(columns, r.labelRatingExpr(label)+" AS "+label)
+	}
+
+	var scores []string
+	for _, label := range labels {
+		scores = append(scores, `SELECT restaurant_id, city_id, district_id, food_type_id, review_count, '`+label+`' AS label, `+label+` AS score
+		FROM restaurant_scores WHERE `+label+` IS NOT NULL`)
+	}
+
+	var scoped []string
+	for _, scope := range rankingScopes {
+		var conditions []string
+		for _, column := range []string{scope.city, scope.district, scope.foodType} {
+			if column != "0" {
+				conditions = append(conditions, column+" IS NOT NULL")
+			}
+		}
+		where := ""
+		if len(conditions) > 0 {
+			where = " WHERE " + strings.Join(conditions, " AND ")
+		}
+		scoped = append(scoped, `SELECT `+scope.city+` AS scope_city, `+scope.district+` AS scope_district, `+scope.foodType+` AS scope_food_type,
+			restaurant_id, label, score, review_count
+		FROM scores`+where)
+	}
+
+	query := `INSERT INTO Restaurant_ranking
+	(rating_version, city_id, district_id, food_type_id, label, rank_position, restaurant_id, score, review_count, previous_rank)
+	WITH restaurant_scores AS (
+		SELECT res.restaurant_id, res.city_id, res.district_id, res.food_type_id, res.review_count, ` + strings.Join(columns, ", ") + `
+		FROM Restaurant res
+		LEFT JOIN Restaurant_label_stats ls ON ls.restaurant_id = res.restaurant_id
+		WHERE res.review_count >= ?
+		GROUP BY res.restaurant_id
+	),
+	scores AS (
+		` + strings.Join(scores, "\n\t\tUNION ALL\n\t\t") + `
+	),
+	scoped AS (
+		` + strings.Join(scoped, "\n\t\tUNION ALL\n\t\t") + `
+	),
+	ranked AS (
+		SELECT scoped.*, ROW_NUMBER() OVER (
+			PARTITION BY scope_city, scope_district, scope_food_type, label
+			ORDER BY score DESC, review_count DESC, restaurant_id
+		) AS rank_position
+		FROM scoped
+	)
+	SELECT ?, k.scope_city, k.scope_district, k.scope_food_type, k.label, k.rank_position, k.restaurant_id, k.score, k.review_count,
+		prev.rank_position
+	FROM ranked k
+	LEFT JOIN Restaurant_ranking prev ON prev.rating_version <> ?
+		AND prev.city_id = k.scope_city AND prev.district_id = k.scope_district AND prev.food_type_id = k.scope_food_type
+		AND prev.label = k.label AND prev.restaurant_id = k.restaurant_id
+	WHERE k.rank_position <= ?`
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		log.Error().Err(err).Msg("Error starting transaction to refresh rankings")
+		return err
+	}
+	defer tx.Rollback()
+
+	// Rankings of the same version are rebuilt from scratch
+	if _, err := tx.Exec(`DELETE FROM Restaurant_ranking WHERE rating_version = ?`, version); err != nil {
+		log.Error().Err(err).Msg("Error clearing rankings of the version")
+		return err
+	}
+	if _, err := tx.Exec(query, constant.RankingMinReviews, version, version, constant.RankingSize); err != nil {
+		log.Error().Err(err).Msg("Error building rankings")
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM Restaurant_ranking WHERE rating_version <> ?`, version); err != nil {
+		log.Error().Err(err).Msg("Error clearing previous rankings")
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error().Err(err).Msg("Error committing rankings")
+		return err
+	}
+	return nil
+}
+
+// FindRanking returns the top restaurants of a scope by label. The city is ignored when a
+// district is given, and "not found" is returned for an unknown food type.
+func (r *repository) FindRanking(cityID int, districtID int, foodType string, label string, limit int) (*model.Ranking, error) {
+	if districtID != 0 {
+		cityID = 0
+	}
+
+	foodTypeID := 0
+	if foodType != "" {
+		err := r.db.QueryRow(`SELECT food_type_id FROM Food_type WHERE food_type_name = ?`, foodType).Scan(&foodTypeID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return nil, errors.New("not found")
+			}
+			log.Error().Err(err).Msg("Error finding food type of ranking")
+			return nil, err
+		}
+	}
+
+	query := `SELECT rk.rating_version, rk.rank_position, rk.restaurant_id, res.restaurant_name, rk.score, rk.review_count, rk.previous_rank
+	FROM Restaurant_ranking rk
+	JOIN Restaurant res ON res.restaurant_id = rk.restaurant_id
+	WHERE rk.city_id = ? AND rk.district_id = ? AND rk.food_type_id = ? AND rk.label = ?
+	ORDER BY rk.rank_position
+	LIMIT ?`
+	rows, err := r.db.Query(query, cityID, districtID, foodTypeID, label, limit)
+	if err != nil {
+		log.Error().Err(err).Msg("Error executing query to find ranking")
+		return nil, err
+	}
+	defer rows.Close()
+
+	ranking := &model.Ranking{Label: label, FoodType: foodType, Restaurants: []model.RankedRestaurant{}}
+	if cityID != 0 {
+		ranking.CityID = strconv.Itoa(cityID)
+	}
+	if districtID != 0 {
+		ranking.DistrictID = strconv.Itoa(districtID)
+	}
+	for rows.Next() {
+		var restaurant model.RankedRestaurant
+		var previousRank sql.NullInt64
+		if err := rows.Scan(
+			&ranking.Version,
+			&restaurant.Rank,
+			&restaurant.RestaurantID,
+			&restaurant.RestaurantName,
+			&restaurant.Score,
+			&restaurant.ReviewCount,
+			&previousRank,
+		); err != nil {
+			log.Error().Err(err).Msg("Error scanning ranked restaurant data")
+			return nil, err
+		}
+		if previousRank.Valid {
+			rank := int(previousRank.Int64)
+			restaurant.PreviousRank = &rank
+			restaurant.Movement = rank - restaurant.Rank
+		}
+		ranking.Restaurants = append(ranking.Restaurants, restaurant)
+	}
+
+	return ranking, nil
+}
@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"fmt"
+	"math"
+	"skeleton-internship-backend/internal/constant"
+	"skeleton-internship-backend/internal/model"
+
+	"github.com/rs/zerolog/log"
+)
+
+// starBucket rounds a rating to its star value, clamped to 1..5
+const starBucket = "LEAST(GREATEST(ROUND(%s), 1), 5)"
+
+// histogramAccumulator collects bucket counts and running sums for a histogram
+type histogramAccumulator struct {
+	counts     [5]int
+	sum        float64
+	sumSquares float64
+}
+
+func (h *histogramAccumulator) add(stars int, count int, sum float64, sumSquares float64) {
+	if stars >= 1 && stars <= 5 {
+		h.counts[stars-1] += count
+	}
+	h.sum += sum
+	h.sumSquares += sumSquares
+}
+
+func (h *histogramAccumulator) histogram() model.RatingHistogram {
+	histogram := model.RatingHistogram{Buckets: make([]model.RatingBucket, 5)}
+	for i, count := range h.counts {
+		histogram.Buckets[i] = model.RatingBucket{Stars: i + 1, Count: count}
+		histogram.Total += count
+	}
+	if histogram.Total > 0 {
+		n := float64(histogram.Total)
+		histogram.Average = h.sum / n
+		histogram.StdDev = math.Sqrt(math.Max(h.sumSquares/n-histogram.Average*histogram.Average, 0))
+	}
+	return histogram
+}
+
+// groupedHistograms runs a query returning (group, stars, count, sum, sum of squares) rows
+func (r *repository) groupedHistograms(query string, args ...interface{}) (map[string]*histogramAccumulator, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	groups := map[string]*histogramAccumulator{}
+	for rows.Next() {
+		var group string
+		var stars, count int
+		var sum, sumSquares float64
+		if err := rows.Scan(&group, &stars, &count, &sum, &sumSquares); err != nil {
+			return nil, err
+		}
+		if groups[group] == nil {
+			groups[group] = &histogramAccumulator{}
+		}
+		groups[group].add(stars, count, sum, sumSquares)
+	}
+
+	return groups, rows.Err()
+}
+
+func (r *repository) FindRatingDistribution(id string) (*model.RatingDistribution, error) {
+	log.Info().Msgf("Finding rating distribution for restaurant ID: %s", id)
+
+	reviewBucket := fmt.Sprintf(starBucket, "r.rating")
+	platformQuery := `SELECT p.platform_name, ` + reviewBucket + ` AS stars, COUNT(*), SUM(r.rating), SUM(r.rating * r.rating)
+	FROM Review r
+	JOIN User u ON r.user_id = u.user_id
+	JOIN Platform p ON u.platform_id = p.platform_id
+	WHERE r.restaurant_id = ? AND ` + countedReview + `
+	GROUP BY p.platform_name, stars`
+	platforms, err := r.groupedHistograms(platformQuery, id)
+	if err != nil {
+		log.Error().Err(err).Msg("Error executing query to find rating distribution by platform")
+		return nil, err
+	}
+
+	labelBucket := fmt.Sprintf(starBucket, "fl.rating_label")
+	labelQuery := `SELECT fl.label, ` + labelBucket + ` AS stars, COUNT(*), SUM(fl.rating_label), SUM(fl.rating_label * fl.rating_label)
+	FROM Review r
+	JOIN Feedback_label fl ON r.rating_id = fl.rating_id
+	WHERE r.restaurant_id = ? AND ` + countedReview + `
+	GROUP BY fl.label, stars`
+	labels, err := r.groupedHistograms(labelQuery, id)
+	if err != nil {
+		log.Error().Err(err).Msg("Error executing query to find rating distribution by label")
+		return nil, err
+	}
+
+	// Every review belongs to exactly one platform, so the overall histogram is their sum
+	overall := &histogramAccumulator{}
+	distribution := &model.RatingDistribution{
+		Labels:    map[string]model.RatingHistogram{},
+		Platforms: map[string]model.RatingHistogram{},
+	}
+	for platform, accumulator := range platforms {
+		for i, count := range accumulator.counts {
+			overall.counts[i] += count
+		}
+		overall.sum += accumulator.sum
+		overall.sumSquares += accumulator.sumSquares
+		distribution.Platforms[platform] = accumulator.histogram()
+	}
+	for label, accumulator := range labels {
+		distribution.Labels[label] = accumulator.histogram()
+	}
+	distribution.Overall = overall.histogram()
+
+	return distribution, nil
+}
+
+// trendPeriod returns the SQL expression of the first day of the bucket holding review_time
+func trendPeriod(granularity string) string {
+	if granularity == constant.TrendGranularityWeek {
+		// Weeks start on Monday
+		return "DATE_FORMAT(DATE_SUB(DATE(r.review_time), INTERVAL WEEKDAY(r.review_time) DAY), '%Y-%m-%d')"
+	}
+	return "DATE_FORMAT(r.review_time, '%Y-%m-01')"
+}
+
+func (r *repository) FindRatingTrend(id string, granularity string) (*model.RatingTrend, error) {
+	log.Info().Msgf("Finding rating trend for restaurant ID: %s with granularity: %s", id, granularity)
+
+	period := trendPeriod(granularity)
+	query := `SELECT ` + period + ` AS period, COUNT(*), AVG(r.rating)
+	FROM Review r
+	WHERE r.restaurant_id = ? AND r.review_time IS NOT NULL AND ` + countedReview + `
+	GROUP BY period
+	ORDER BY period`
+	rows, err := r.db.Query(query, id)
+	if err != nil {
+		log.Error().Err(err).Msg("Error executing query to find rating trend")
+		return nil, err
+	}
+	defer rows.Close()
+
+	trend := &model.RatingTrend{
+		Granularity: granularity,
+		Points:      []model.RatingTrendPoint{},
+	}
+	index := map[string]int{}
+	for rows.Next() {
+		point := model.RatingTrendPoint{Labels: map[string]model.LabelRating{}}
+		if err := rows.Scan(&point.Period, &point.ReviewCount, &point.AverageRating); err != nil {
+			log.Error().Err(err).Msg("Error scanning rating trend data")
+			return nil, err
+		}
+		index[point.Period] = len(trend.Points)
+		trend.Points = append(trend.Points, point)
+	}
+
+	labelQuery := `SELECT ` + period + ` AS period, fl.label, COUNT(*), AVG(fl.rating_label)
+	FROM Review r
+	JOIN Feedback_label fl ON r.rating_id = fl.rating_id
+	WHERE r.restaurant_id = ? AND r.review_time IS NOT NULL AND ` + countedReview + `
+	GROUP BY period, fl.label`
+	labelRows, err := r.db.Query(labelQuery, id)
+	if err != nil {
+		log.Error().Err(err).Msg("Error executing query to find label rating trend")
+		return nil, err
+	}
+	defer labelRows.Close()
+
+	for labelRows.Next() {
+		var periodValue, label string
+		var labelRating model.LabelRating
+		if err := labelRows.Scan(&periodValue, &label, &labelRating.Count, &labelRating.Rating); err != nil {
+			log.Error().Err(err).Msg("Error scanning label rating trend data")
+			return nil, err
+		}
+		if i, ok := index[periodValue]; ok {
+			trend.Points[i].Labels[label] = labelRating
+		}
+	}
+
+	return trend, nil
+}
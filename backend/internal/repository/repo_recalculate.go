@@ -1,47 +1,159 @@
 package repository
 
 import (
+	"database/sql"
+	"errors"
+	"strconv"
+
 	"github.com/rs/zerolog/log"
 )
 
-func (r *repository) RecalculateCountReviews() error {
-	query := `UPDATE Restaurant r
+// countReviewsQuery builds the statement that rewrites the review_count of every row of
+// the given table, Restaurant or a table keyed by restaurant_id like the staging table
+func countReviewsQuery(table string) string {
+	return `UPDATE ` + table + ` res
               LEFT JOIN (
-                  SELECT restaurant_id, COUNT(*) AS review_total
-                  FROM Review
-                  GROUP BY restaurant_id
-              ) rev ON r.restaurant_id = rev.restaurant_id
-              SET r.review_count = IFNULL(rev.review_total, 0);`
-	_, err := r.db.Exec(query)
+                  SELECT r.restaurant_id, COUNT(*) AS review_total
+                  FROM Review r
+                  WHERE ` + countedReview + `
+                  GROUP BY r.restaurant_id
+              ) rev ON res.restaurant_id = rev.restaurant_id
+              SET res.review_count = IFNULL(rev.review_total, 0);`
+}
+
+// reviewWeight is the SQL weight of a review (aliased r, with Review_flag joined as rf)
+// in every rating aggregation, so label ratings and the overall rating agree. Reviews
+// flagged as suspicious get the configured weight, times the weight of the rating strategy.
+func (r *repository) reviewWeight() string {
+	weight := "IF(rf.rating_id IS NULL, 1, " + strconv.FormatFloat(r.cfg.Rating.FlaggedReviewWeight(), 'f', -1, 64) + ")"
+	if strategyWeight := r.strategy.ReviewWeight(); strategyWeight != "1" {
+		weight += " * " + strategyWeight
+	}
+	return weight
+}
+
+// configuredAspectWeight is the SQL weight of the label in the given column according to
+// the rating configuration
+func (r *repository) configuredAspectWeight(column string) string {
+	expr := "CASE " + column
+	for _, label := range r.cfg.Rating.WeightedLabels() {
+		expr += " WHEN '" + label + "' THEN " + strconv.FormatFloat(r.cfg.Rating.AspectWeight(label), 'f', -1, 64)
+	}
+	return expr + " ELSE 1 END"
+}
+
+// FindAspectWeights returns the weight of each label in the overall rating of a restaurant:
+// the weights of its food type, falling back to the configured ones
+func (r *repository) FindAspectWeights(restaurantID string) (map[string]float64, error) {
+	weights := map[string]float64{}
+	for _, label := range r.cfg.Rating.WeightedLabels() {
+		weights[label] = r.cfg.Rating.AspectWeight(label)
+	}
+
+	query := `SELECT aw.label, aw.weight
+	FROM Food_type_aspect_weight aw
+	JOIN Restaurant res ON res.food_type_id = aw.food_type_id
+	WHERE res.restaurant_id = ?`
+	rows, err := r.db.Query(query, restaurantID)
 	if err != nil {
-		log.Error().Err(err).Msg("Error recalculating review count")
-		return err
+		log.Error().Err(err).Msg("Error executing query to find aspect weights")
+		return nil, err
 	}
-	return nil
+	defer rows.Close()
+
+	for rows.Next() {
+		var label string
+		var weight float64
+		if err := rows.Scan(&label, &weight); err != nil {
+			log.Error().Err(err).Msg("Error scanning aspect weight data")
+			return nil, err
+		}
+		// Weights of labels left out of the overall rating do not apply
+		if _, ok := weights[label]; ok {
+			weights[label] = weight
+		}
+	}
+
+	return weights, nil
 }
 
-func (r *repository) RecalculateAverageRating() error {
-	query :=
-		`WITH label_stats AS (
+// averageRatingQuery builds the statement that rewrites restaurant_rating from
+// the weighted label averages of counted reviews, with its arguments. The table is
+// Restaurant or a table with the same restaurant_id, food_type_id and restaurant_rating
+// columns like the staging table. An empty restaurant ID updates every restaurant.
+// Restaurants without any counted labeled review, e.g. once moderation hid their last
+// one, get a rating of 0. The bayesian priors come from the given label stats table.
+// Unknown label ratings are merged into every label, averaged as a label of their own or
+// left out depending on the unknown policy. The rating strategy turns the weighted mean
+// of the label averages into the overall rating.
+func (r *repository) averageRatingQuery(table string, statsTable string, restaurantID string) (string, []interface{}) {
+	weight := r.reviewWeight()
+
+	filter, target := "", ""
+	var args []interface{}
+	if restaurantID != "" {
+		filter = " AND r.restaurant_id = ?"
+		target = "\nWHERE r.restaurant_id = ?"
+		args = []interface{}{restaurantID, restaurantID, restaurantID, restaurantID}
+	}
+
+	unknownSum, unknownWeight := "0", "0"
+	if r.cfg.Rating.SpreadsUnknown() {
+		unknownSum, unknownWeight = "COALESCE(u.sum_unknown, 0)", "COALESCE(u.weight_unknown, 0)"
+	}
+	unknownAvg := ""
+	if r.cfg.Rating.UnknownInOverall() {
+		unknownAvg = `
+  UNION ALL
+  SELECT
+    restaurant_id,
+    'unknown',
+    CASE WHEN weight_unknown > 0 THEN sum_unknown / weight_unknown ELSE NULL END
+  FROM unknown_stats`
+	}
+
+	aspectWeight := "COALESCE(aw.weight, " + r.configuredAspectWeight("la.label") + ")"
+
+	rating := r.strategy.Rating(RatingColumns{
+		Mean:         "fr.mean_rating",
+		ReviewWeight: "COALESCE(rw.review_weight, 0)",
+		GlobalPrior:  "gp.prior_rating",
+		CuisinePrior: "cp.prior_rating",
+	})
+
+	return `WITH label_stats AS (
   SELECT
     r.restaurant_id,
     fl.label,
-    SUM(fl.rating_label) AS sum_label,
-    COUNT(*)             AS cnt_label
+    SUM(fl.rating_label * ` + weight + `) AS sum_label,
+    SUM(` + weight + `)                   AS weight_label
   FROM Feedback_label fl
   JOIN Review r ON fl.rating_id = r.rating_id
-  WHERE fl.label <> 'unknown'
+  LEFT JOIN Review_flag rf ON rf.rating_id = r.rating_id
+  WHERE fl.label <> 'unknown' AND ` + countedReview + filter + `
   GROUP BY r.restaurant_id, fl.label
 ),
 
 unknown_stats AS (
   SELECT
     r.restaurant_id,
-    SUM(fl.rating_label) AS sum_unknown,
-    COUNT(*) AS cnt_unknown
+    SUM(fl.rating_label * ` + weight + `) AS sum_unknown,
+    SUM(` + weight + `) AS weight_unknown
   FROM Feedback_label fl
   JOIN Review r ON fl.rating_id = r.rating_id
-  WHERE fl.label = 'unknown'
+  LEFT JOIN Review_flag rf ON rf.rating_id = r.rating_id
+  WHERE fl.label = 'unknown' AND ` + countedReview + filter + `
+  GROUP BY r.restaurant_id
+),
+
+review_weights AS (
+  SELECT
+    r.restaurant_id,
+    SUM(` + weight + `) AS review_weight
+  FROM Review r
+  LEFT JOIN Review_flag rf ON rf.rating_id = r.rating_id
+  WHERE ` + countedReview + filter + `
+    AND EXISTS (SELECT 1 FROM Feedback_label fl WHERE fl.rating_id = r.rating_id)
   GROUP BY r.restaurant_id
 ),
 
@@ -50,43 +162,110 @@ label_avgs AS (
     l.restaurant_id,
     l.label,
     CASE
-      WHEN (l.cnt_label + COALESCE(u.cnt_unknown, 0)) > 0 THEN
-        (l.sum_label + COALESCE(u.sum_unknown, 0))
-        / (l.cnt_label + COALESCE(u.cnt_unknown, 0))
+      WHEN (l.weight_label + ` + unknownWeight + `) > 0 THEN
+        (l.sum_label + ` + unknownSum + `)
+        / (l.weight_label + ` + unknownWeight + `)
       ELSE NULL
     END AS label_avg
   FROM label_stats l
   LEFT JOIN unknown_stats u
-    ON l.restaurant_id = u.restaurant_id
+    ON l.restaurant_id = u.restaurant_id` + unknownAvg + `
 ),
+-- Label averages are weighted by food type, falling back to the configured weights.
+-- Restaurants whose rated labels all weigh 0 keep the plain mean.
 final_rating AS (
   SELECT
-    restaurant_id,
-    ROUND(AVG(label_avg), 2) AS restaurant_avg_rating
-  FROM label_avgs
-  WHERE label_avg IS NOT NULL
-  GROUP BY restaurant_id
+    la.restaurant_id,
+    COALESCE(
+      SUM(la.label_avg * ` + aspectWeight + `) / NULLIF(SUM(` + aspectWeight + `), 0),
+      AVG(la.label_avg)
+    ) AS mean_rating
+  FROM label_avgs la
+  JOIN Restaurant res ON res.restaurant_id = la.restaurant_id
+  LEFT JOIN Food_type_aspect_weight aw ON aw.food_type_id = res.food_type_id AND aw.label = la.label
+  WHERE la.label_avg IS NOT NULL
+  GROUP BY la.restaurant_id
+),
+
+-- Priors come from the label stats of every restaurant, even when one restaurant is updated
+global_prior AS (
+  SELECT SUM(rating_sum) / NULLIF(SUM(weight), 0) AS prior_rating
+  FROM ` + statsTable + `
+  WHERE label <> 'unknown'
+),
+cuisine_prior AS (
+  SELECT
+    res.food_type_id,
+    SUM(ls.rating_sum) / NULLIF(SUM(ls.weight), 0) AS prior_rating
+  FROM ` + statsTable + ` ls
+  JOIN Restaurant res ON res.restaurant_id = ls.restaurant_id
+  WHERE ls.label <> 'unknown'
+  GROUP BY res.food_type_id
 )
-UPDATE Restaurant r
-JOIN final_rating fr ON r.restaurant_id = fr.restaurant_id
-SET r.restaurant_rating = fr.restaurant_avg_rating;`
+UPDATE ` + table + ` r
+LEFT JOIN final_rating fr ON r.restaurant_id = fr.restaurant_id
+LEFT JOIN review_weights rw ON rw.restaurant_id = r.restaurant_id
+CROSS JOIN global_prior gp
+LEFT JOIN cuisine_prior cp ON cp.food_type_id = r.food_type_id
+SET r.restaurant_rating = IF(fr.restaurant_id IS NULL, 0, ROUND(` + rating + `, 2))` + target + `;`, args
+}
+
+// refreshRestaurantStats recounts the counted reviews of one restaurant and recomputes
+// its label stats and rating inside the given transaction
+func (r *repository) refreshRestaurantStats(tx *sql.Tx, id string) error {
+	_, err := tx.Exec(`UPDATE Restaurant SET review_count = (
+		SELECT COUNT(*) FROM Review r WHERE r.restaurant_id = ? AND `+countedReview+`
+	), recalculated_at = CURRENT_TIMESTAMP WHERE restaurant_id = ?`, id, id)
+	if err != nil {
+		log.Error().Err(err).Msgf("Error updating restaurant %s review count", id)
+		return err
+	}
+
+	if err := r.refreshLabelStats(tx, id); err != nil {
+		return err
+	}
 
-	_, err := r.db.Exec(query)
+	query, args := r.averageRatingQuery("Restaurant", "Restaurant_label_stats", id)
+	_, err = tx.Exec(query, args...)
 	if err != nil {
-		log.Error().Err(err).Msg("Error recalculating rating labels")
+		log.Error().Err(err).Msgf("Error updating restaurant %s rating", id)
 		return err
 	}
 	return nil
 }
 
-func (r *repository) UpdateRestaurantRating(id string, rating float64, reviewCount int) error {
+// RecalculateRestaurant recalculates the review count, label stats and rating of one
+// restaurant and clears its dirty mark. The restaurant row is locked like review writes
+// do, so no change can slip in between the recalculation and the clearing of the mark.
+func (r *repository) RecalculateRestaurant(id string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		log.Error().Err(err).Msg("Error starting transaction to recalculate restaurant")
+		return err
+	}
+	defer tx.Rollback()
 
-	query := `UPDATE Restaurant SET restaurant_rating = ?, review_count = ? WHERE restaurant_id = ?`
-	_, err := r.db.Exec(query, rating, reviewCount, id)
+	var locked string
+	err = tx.QueryRow(`SELECT restaurant_id FROM Restaurant WHERE restaurant_id = ? FOR UPDATE`, id).Scan(&locked)
 	if err != nil {
-		log.Error().Err(err).Msgf("Error updating restaurant %s rating", id)
+		if err == sql.ErrNoRows {
+			return errors.New("not found")
+		}
+		log.Error().Err(err).Msgf("Error locking restaurant %s to recalculate it", id)
+		return err
+	}
+
+	if err := r.refreshRestaurantStats(tx, id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM Restaurant_dirty WHERE restaurant_id = ?`, id); err != nil {
+		log.Error().Err(err).Msgf("Error clearing dirty mark of restaurant %s", id)
 		return err
 	}
 
+	if err := tx.Commit(); err != nil {
+		log.Error().Err(err).Msgf("Error committing recalculation of restaurant %s", id)
+		return err
+	}
 	return nil
-}
\ No newline at end of file
+}
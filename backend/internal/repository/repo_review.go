@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"skeleton-internship-backend/internal/model"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// newRatingID generates a random identifier for reviews created through the API
+func newRatingID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func (r *repository) CreateReview(restaurantID string, userID string, rating float64, feedback string, labels []model.ReviewLabel) (*model.Review, error) {
+	log.Info().Msgf("Creating review for restaurant ID: %s by user ID: %s with %d labels", restaurantID, userID, len(labels))
+
+	ratingID, err := newRatingID()
+	if err != nil {
+		log.Error().Err(err).Msg("Error generating rating ID")
+		return nil, err
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		log.Error().Err(err).Msg("Error starting transaction to create review")
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Lock the restaurant row so concurrent reviews for it are serialized
+	var reviewCount int
+	err = tx.QueryRow(`SELECT review_count FROM Restaurant WHERE restaurant_id = ? FOR UPDATE`, restaurantID).Scan(&reviewCount)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("not found")
+		}
+		log.Error().Err(err).Msg("Error locking restaurant to create review")
+		return nil, err
+	}
+
+	var userName string
+	err = tx.QueryRow(`SELECT user_name FROM User WHERE user_id = ?`, userID).Scan(&userName)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("user not found")
+		}
+		log.Error().Err(err).Msg("Error finding user to create review")
+		return nil, err
+	}
+
+	// Imported reviews can repeat a user, so this is checked here rather than by a unique
+	// index. The restaurant lock above keeps two reviews by the same user from both passing.
+	var existing int
+	err = tx.QueryRow(`SELECT COUNT(*) FROM Review WHERE restaurant_id = ? AND user_id = ?`, restaurantID, userID).Scan(&existing)
+	if err != nil {
+		log.Error().Err(err).Msg("Error checking existing reviews")
+		return nil, err
+	}
+	if existing > 0 {
+		return nil, errors.New("already reviewed")
+	}
+
+	var feedbackValue sql.NullString
+	if feedback != "" {
+		feedbackValue = sql.NullString{String: feedback, Valid: true}
+	}
+	reviewTime := time.Now()
+
+	_, err = tx.Exec(`INSERT INTO Review (rating_id, restaurant_id, user_id, rating, feedback, review_time) VALUES (?, ?, ?, ?, ?, ?)`,
+		ratingID, restaurantID, userID, rating, feedbackValue, reviewTime)
+	if err != nil {
+		log.Error().Err(err).Msg("Error inserting review")
+		return nil, err
+	}
+
+	// A label repeated in the request keeps its first rating
+	for _, label := range labels {
+		_, err = tx.Exec(`INSERT INTO Feedback_label (label, rating_label, rating_id) VALUES (?, ?, ?)
+			ON DUPLICATE KEY UPDATE rating_label = rating_label`,
+			label.Label, label.RatingLabel, ratingID)
+		if err != nil {
+			log.Error().Err(err).Msg("Error inserting feedback label")
+			return nil, err
+		}
+	}
+
+	_, err = tx.Exec(`UPDATE Restaurant SET review_count = review_count + 1 WHERE restaurant_id = ?`, restaurantID)
+	if err != nil {
+		log.Error().Err(err).Msg("Error updating review count")
+		return nil, err
+	}
+
+	if len(labels) > 0 {
+		if err := r.refreshLabelStats(tx, restaurantID); err != nil {
+			return nil, err
+		}
+
+		query, args := r.averageRatingQuery("Restaurant", "Restaurant_label_stats", restaurantID)
+		_, err = tx.Exec(query, args...)
+		if err != nil {
+			log.Error().Err(err).Msgf("Error updating restaurant %s rating", restaurantID)
+			return nil, err
+		}
+	}
+
+	// Keywords are not refreshed here, the next incremental recalculation does it
+	if err := markRestaurantDirty(tx, restaurantID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error().Err(err).Msg("Error committing review")
+		return nil, err
+	}
+
+	return &model.Review{
+		RatingID:   ratingID,
+		UserName:   userName,
+		Rating:     rating,
+		Feedback:   feedback,
+		ReviewTime: reviewTime.Format(time.RFC3339),
+		Labels:     labels,
+	}, nil
+}
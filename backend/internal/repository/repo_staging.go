@@ -0,0 +1,276 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"skeleton-internship-backend/internal/model"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// stagedLabelStats names the label stats table staged along with each rating staging table
+var stagedLabelStats = map[string]string{
+	"Restaurant_rating_staging": "Restaurant_label_stats_staging",
+	"Restaurant_rating_shadow":  "Restaurant_label_stats_shadow",
+}
+
+// StageRecalculation computes the review count and rating of every restaurant into the
+// staging table, next to their current values, without touching the restaurants
+func (r *repository) StageRecalculation() error {
+	return r.stageRatings("Restaurant_rating_staging")
+}
+
+// RecalculateRestaurants computes the review count and rating of every restaurant into the
+// shadow table, then swaps them in with a new recalculation version in one transaction,
+// so readers see either the previous snapshot or the new one. It returns the new version.
+func (r *repository) RecalculateRestaurants() (int, error) {
+	if err := r.stageRatings("Restaurant_rating_shadow"); err != nil {
+		return 0, err
+	}
+	result, err := r.applyStagedRatings("Restaurant_rating_shadow")
+	if err != nil {
+		return 0, err
+	}
+	log.Info().Msgf("Recalculation version %d: %d restaurants updated, %d changed meanwhile and marked dirty", result.Version, result.Applied, result.Skipped)
+	return result.Version, nil
+}
+
+// stageRatings fills the given staging table with the current and recalculated
+// review count and rating of every restaurant, and its label stats table with their
+// label stats, which the bayesian priors are taken from
+func (r *repository) stageRatings(table string) error {
+	statsTable := stagedLabelStats[table]
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		log.Error().Err(err).Msg("Error starting transaction to stage recalculation")
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM ` + table); err != nil {
+		log.Error().Err(err).Msg("Error clearing staged ratings")
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM ` + statsTable); err != nil {
+		log.Error().Err(err).Msg("Error clearing staged label stats")
+		return err
+	}
+	statsQuery, _ := r.labelStatsQuery(statsTable, "")
+	if _, err := tx.Exec(statsQuery); err != nil {
+		log.Error().Err(err).Msg("Error staging label stats")
+		return err
+	}
+
+	// The current values are kept as the old ones. Restaurants without labeled reviews then
+	// get a rating of 0, as in a real recalculation.
+	_, err = tx.Exec(`INSERT INTO ` + table + `
+	(restaurant_id, district_id, food_type_id, old_review_count, old_rating, review_count, restaurant_rating)
+	SELECT restaurant_id, district_id, food_type_id, review_count, restaurant_rating, review_count, restaurant_rating
+	FROM Restaurant`)
+	if err != nil {
+		log.Error().Err(err).Msg("Error copying restaurants to the staging table")
+		return err
+	}
+
+	if _, err := tx.Exec(countReviewsQuery(table)); err != nil {
+		log.Error().Err(err).Msg("Error staging review counts")
+		return err
+	}
+
+	query, args := r.averageRatingQuery(table, statsTable, "")
+	if _, err := tx.Exec(query, args...); err != nil {
+		log.Error().Err(err).Msg("Error staging ratings")
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error().Err(err).Msg("Error committing staged recalculation")
+		return err
+	}
+	return nil
+}
+
+// FindStagedRatings returns every staged restaurant and the time of the dry run,
+// or "not found" when nothing is staged
+func (r *repository) FindStagedRatings() ([]model.StagedRating, time.Time, error) {
+	query := `SELECT s.restaurant_id, res.restaurant_name, s.district_id, s.old_review_count, s.review_count,
+		s.old_rating, s.restaurant_rating, s.staged_at
+	FROM Restaurant_rating_staging s
+	JOIN Restaurant res ON res.restaurant_id = s.restaurant_id
+	ORDER BY s.restaurant_id`
+	rows, err := r.db.Query(query)
+	if err != nil {
+		log.Error().Err(err).Msg("Error executing query to find staged ratings")
+		return nil, time.Time{}, err
+	}
+	defer rows.Close()
+
+	var staged []model.StagedRating
+	var stagedAt time.Time
+	for rows.Next() {
+		var rating model.StagedRating
+		var districtID sql.NullInt64
+		var oldReviewCount sql.NullInt64
+		var oldRating, newRating sql.NullFloat64
+		if err := rows.Scan(
+			&rating.RestaurantID,
+			&rating.RestaurantName,
+			&districtID,
+			&oldReviewCount,
+			&rating.ReviewCount,
+			&oldRating,
+			&newRating,
+			&stagedAt,
+		); err != nil {
+			log.Error().Err(err).Msg("Error scanning staged rating data")
+			return nil, time.Time{}, err
+		}
+		if districtID.Valid {
+			rating.DistrictID = strconv.FormatInt(districtID.Int64, 10)
+		}
+		rating.OldReviewCount = int(oldReviewCount.Int64)
+		rating.OldRating = oldRating.Float64
+		rating.Rating = newRating.Float64
+		staged = append(staged, rating)
+	}
+	if len(staged) == 0 {
+		return nil, time.Time{}, errors.New("not found")
+	}
+
+	return staged, stagedAt, nil
+}
+
+// ApplyStagedRecalculation swaps the staged review counts and ratings in,
+// or returns "not found" when nothing is staged
+func (r *repository) ApplyStagedRecalculation() (*model.DryRunApplyResult, error) {
+	result, err := r.applyStagedRatings("Restaurant_rating_staging")
+	if err != nil {
+		return nil, err
+	}
+	if result.Applied == 0 && result.Skipped == 0 {
+		return nil, errors.New("not found")
+	}
+	return result, nil
+}
+
+// applyStagedRatings swaps the review counts, ratings and label stats of the given staging
+// table in, stamped with a new recalculation version, snapshots every restaurant under that
+// version and clears the table in one transaction.
+// Restaurants that changed since they were staged keep their values and are marked dirty
+// instead, so a stale computation cannot undo newer reviews. The dirty marks of the
+// restaurants rewritten are cleared when they predate the staging.
+func (r *repository) applyStagedRatings(table string) (*model.DryRunApplyResult, error) {
+	statsTable := stagedLabelStats[table]
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		log.Error().Err(err).Msg("Error starting transaction to apply staged recalculation")
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Locks the staged restaurants so no review write slips in between the check and the update
+	rows, err := tx.Query(`SELECT s.restaurant_id,
+		r.review_count <=> s.old_review_count AND r.restaurant_rating <=> s.old_rating
+	FROM ` + table + ` s
+	JOIN Restaurant r ON r.restaurant_id = s.restaurant_id
+	FOR UPDATE`)
+	if err != nil {
+		log.Error().Err(err).Msg("Error locking staged restaurants")
+		return nil, err
+	}
+	result := &model.DryRunApplyResult{}
+	var stale []string
+	for rows.Next() {
+		var id string
+		var unchanged bool
+		if err := rows.Scan(&id, &unchanged); err != nil {
+			rows.Close()
+			log.Error().Err(err).Msg("Error scanning staged restaurant data")
+			return nil, err
+		}
+		if unchanged {
+			result.Applied++
+		} else {
+			stale = append(stale, id)
+		}
+	}
+	rows.Close()
+	if result.Applied == 0 && len(stale) == 0 {
+		return result, nil
+	}
+
+	if err := tx.QueryRow(`SELECT COALESCE(MAX(rating_version), 0) + 1 FROM Restaurant`).Scan(&result.Version); err != nil {
+		log.Error().Err(err).Msg("Error reading the recalculation version")
+		return nil, err
+	}
+
+	// Marks set after the staging started are kept, the staged values may predate their change
+	_, err = tx.Exec(`DELETE d FROM Restaurant_dirty d
+	JOIN ` + table + ` s ON s.restaurant_id = d.restaurant_id
+	JOIN Restaurant r ON r.restaurant_id = s.restaurant_id
+	WHERE d.marked_at < s.staged_at
+		AND r.review_count <=> s.old_review_count AND r.restaurant_rating <=> s.old_rating`)
+	if err != nil {
+		log.Error().Err(err).Msg("Error clearing dirty marks of staged restaurants")
+		return nil, err
+	}
+
+	_, err = tx.Exec(`UPDATE Restaurant r
+	JOIN `+table+` s ON s.restaurant_id = r.restaurant_id
+	SET r.review_count = s.review_count, r.restaurant_rating = s.restaurant_rating,
+		r.rating_version = ?, r.recalculated_at = CURRENT_TIMESTAMP
+	WHERE r.review_count <=> s.old_review_count AND r.restaurant_rating <=> s.old_rating`, result.Version)
+	if err != nil {
+		log.Error().Err(err).Msg("Error applying staged ratings")
+		return nil, err
+	}
+
+	for _, id := range stale {
+		if err := markRestaurantDirty(tx, id); err != nil {
+			return nil, err
+		}
+	}
+	result.Skipped = len(stale)
+
+	// Only the restaurants rewritten above, now stamped with the version, take the staged label
+	// stats. The others keep the stats their newer reviews left.
+	_, err = tx.Exec(`DELETE ls FROM Restaurant_label_stats ls
+	JOIN Restaurant r ON r.restaurant_id = ls.restaurant_id
+	WHERE r.rating_version = ?`, result.Version)
+	if err != nil {
+		log.Error().Err(err).Msg("Error clearing label stats of staged restaurants")
+		return nil, err
+	}
+	_, err = tx.Exec(`INSERT INTO Restaurant_label_stats (restaurant_id, label, rating_sum, weight, review_count, average_rating)
+	SELECT st.restaurant_id, st.label, st.rating_sum, st.weight, st.review_count, st.average_rating
+	FROM `+statsTable+` st
+	JOIN Restaurant r ON r.restaurant_id = st.restaurant_id
+	WHERE r.rating_version = ?`, result.Version)
+	if err != nil {
+		log.Error().Err(err).Msg("Error applying staged label stats")
+		return nil, err
+	}
+
+	if err := r.snapshotRatings(tx, result.Version); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM ` + table); err != nil {
+		log.Error().Err(err).Msg("Error clearing staged ratings")
+		return nil, err
+	}
+	if _, err := tx.Exec(`DELETE FROM ` + statsTable); err != nil {
+		log.Error().Err(err).Msg("Error clearing staged label stats")
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error().Err(err).Msg("Error committing staged recalculation")
+		return nil, err
+	}
+	return result, nil
+}
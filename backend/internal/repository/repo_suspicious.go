@@ -0,0 +1,201 @@
+package repository
+
+import (
+	"database/sql"
+	"skeleton-internship-backend/internal/constant"
+	"skeleton-internship-backend/internal/model"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+func (r *repository) FindReviewActivityByRestaurantID(id string) ([]model.ReviewActivity, error) {
+	// Reviewer history is limited to the users who reviewed this restaurant
+	query := `SELECT r.rating_id, r.user_id, r.rating, r.review_time, us.review_count, us.average_rating
+	FROM Review r
+	JOIN (
+		SELECT user_id, COUNT(*) AS review_count, AVG(rating) AS average_rating
+		FROM Review
+		WHERE user_id IN (SELECT user_id FROM Review WHERE restaurant_id = ?)
+		GROUP BY user_id
+	) us ON us.user_id = r.user_id
+	WHERE r.restaurant_id = ? AND r.review_time IS NOT NULL AND ` + countedReview + `
+	ORDER BY r.review_time, r.rating_id`
+	rows, err := r.db.Query(query, id, id)
+	if err != nil {
+		log.Error().Err(err).Msg("Error executing query to find review activity")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reviews []model.ReviewActivity
+	for rows.Next() {
+		var review model.ReviewActivity
+		if err := rows.Scan(
+			&review.RatingID,
+			&review.UserID,
+			&review.Rating,
+			&review.ReviewTime,
+			&review.ReviewerReviewCount,
+			&review.ReviewerAverageRating,
+		); err != nil {
+			log.Error().Err(err).Msg("Error scanning review activity data")
+			return nil, err
+		}
+		reviews = append(reviews, review)
+	}
+
+	return reviews, nil
+}
+
+// SaveSuspiciousWindows replaces the windows and flags of the previous detection pass
+func (r *repository) SaveSuspiciousWindows(windows []model.SuspiciousWindow) error {
+	log.Info().Msgf("Saving %d suspicious windows", len(windows))
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		log.Error().Err(err).Msg("Error starting transaction to save suspicious windows")
+		return err
+	}
+	defer tx.Rollback()
+
+	// Restaurants with flags before or after this pass need a recalculation
+	if _, err := tx.Exec(`INSERT INTO Restaurant_dirty (restaurant_id)
+	SELECT DISTINCT restaurant_id FROM Suspicious_window
+	ON DUPLICATE KEY UPDATE marked_at = CURRENT_TIMESTAMP`); err != nil {
+		log.Error().Err(err).Msg("Error marking restaurants of suspicious windows dirty")
+		return err
+	}
+
+	// Flags are removed along with their window
+	if _, err := tx.Exec(`DELETE FROM Suspicious_window`); err != nil {
+		log.Error().Err(err).Msg("Error clearing suspicious windows")
+		return err
+	}
+
+	for _, window := range windows {
+		if err := markRestaurantDirty(tx, window.RestaurantID); err != nil {
+			return err
+		}
+
+		result, err := tx.Exec(`INSERT INTO Suspicious_window
+		(restaurant_id, window_start, window_end, direction, review_count, average_rating, baseline_rating, baseline_daily_reviews)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			window.RestaurantID, window.WindowStart, window.WindowEnd, window.Direction, window.ReviewCount,
+			window.AverageRating, window.BaselineRating, window.BaselineDailyReviews)
+		if err != nil {
+			log.Error().Err(err).Msgf("Error inserting suspicious window for restaurant %s", window.RestaurantID)
+			return err
+		}
+		windowID, err := result.LastInsertId()
+		if err != nil {
+			log.Error().Err(err).Msg("Error reading suspicious window ID")
+			return err
+		}
+
+		for _, flag := range window.FlaggedReviews {
+			_, err := tx.Exec(`INSERT INTO Review_flag (rating_id, window_id, score, reasons) VALUES (?, ?, ?, ?)`,
+				flag.RatingID, windowID, flag.Score, flag.Reasons)
+			if err != nil {
+				log.Error().Err(err).Msgf("Error flagging review %s", flag.RatingID)
+				return err
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error().Err(err).Msg("Error committing suspicious windows")
+		return err
+	}
+	return nil
+}
+
+func (r *repository) FindSuspiciousWindows(restaurantID string, page int) (*model.SuspiciousReport, error) {
+	offset := (page - 1) * constant.SuspiciousWindowsPerPage
+	limit := constant.SuspiciousWindowsPerPage
+
+	where := ""
+	var args []interface{}
+	if restaurantID != "" {
+		where = " WHERE restaurant_id = ?"
+		args = append(args, restaurantID)
+	}
+
+	report := &model.SuspiciousReport{Windows: []model.SuspiciousWindow{}}
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM Suspicious_window`+where, args...).Scan(&report.TotalWindows); err != nil {
+		log.Error().Err(err).Msg("Error counting suspicious windows")
+		return nil, err
+	}
+
+	// Largest bursts first
+	query := `SELECT window_id, restaurant_id, window_start, window_end, direction, review_count,
+		average_rating, baseline_rating, baseline_daily_reviews, detected_at
+	FROM Suspicious_window` + where + `
+	ORDER BY review_count DESC, window_id
+	LIMIT ? OFFSET ?`
+	rows, err := r.db.Query(query, append(args, limit, offset)...)
+	if err != nil {
+		log.Error().Err(err).Msg("Error executing query to find suspicious windows")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var windowIDs []interface{}
+	index := map[int]int{}
+	for rows.Next() {
+		window := model.SuspiciousWindow{FlaggedReviews: []model.ReviewFlag{}}
+		var windowStart, windowEnd time.Time
+		var detectedAt sql.NullTime
+		if err := rows.Scan(
+			&window.WindowID,
+			&window.RestaurantID,
+			&windowStart,
+			&windowEnd,
+			&window.Direction,
+			&window.ReviewCount,
+			&window.AverageRating,
+			&window.BaselineRating,
+			&window.BaselineDailyReviews,
+			&detectedAt,
+		); err != nil {
+			log.Error().Err(err).Msg("Error scanning suspicious window data")
+			return nil, err
+		}
+		window.WindowStart = windowStart.Format("2006-01-02")
+		window.WindowEnd = windowEnd.Format("2006-01-02")
+		if detectedAt.Valid {
+			window.DetectedAt = detectedAt.Time.Format(time.RFC3339)
+		}
+		index[window.WindowID] = len(report.Windows)
+		windowIDs = append(windowIDs, window.WindowID)
+		report.Windows = append(report.Windows, window)
+	}
+	if len(windowIDs) == 0 {
+		return report, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(windowIDs)), ",")
+	flagRows, err := r.db.Query(`SELECT window_id, rating_id, score, reasons
+	FROM Review_flag
+	WHERE window_id IN (`+placeholders+`)
+	ORDER BY score DESC, rating_id`, windowIDs...)
+	if err != nil {
+		log.Error().Err(err).Msg("Error executing query to find review flags")
+		return nil, err
+	}
+	defer flagRows.Close()
+
+	for flagRows.Next() {
+		var windowID int
+		var flag model.ReviewFlag
+		if err := flagRows.Scan(&windowID, &flag.RatingID, &flag.Score, &flag.Reasons); err != nil {
+			log.Error().Err(err).Msg("Error scanning review flag data")
+			return nil, err
+		}
+		window := &report.Windows[index[windowID]]
+		window.FlaggedReviews = append(window.FlaggedReviews, flag)
+	}
+
+	return report, nil
+}
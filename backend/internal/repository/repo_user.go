@@ -0,0 +1,188 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"skeleton-internship-backend/internal/constant"
+	"skeleton-internship-backend/internal/model"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+func (r *repository) FindUserByID(id string) (*model.UserProfile, error) {
+	query := `SELECT
+		u.user_id,
+		u.user_name,
+		p.platform_name,
+		COUNT(r.rating_id),
+		COUNT(DISTINCT r.restaurant_id),
+		COALESCE(AVG(r.rating), 0),
+		MIN(r.review_time),
+		MAX(r.review_time)
+	FROM User u
+	JOIN Platform p ON u.platform_id = p.platform_id
+	LEFT JOIN Review r ON r.user_id = u.user_id AND ` + visibleReview + `
+	WHERE u.user_id = ?
+	GROUP BY u.user_id, u.user_name, p.platform_name`
+
+	var user model.UserProfile
+	var firstReview, lastReview sql.NullTime
+	err := r.db.QueryRow(query, id).Scan(
+		&user.UserID,
+		&user.UserName,
+		&user.Platform,
+		&user.ReviewCount,
+		&user.RestaurantCount,
+		&user.AverageRating,
+		&firstReview,
+		&lastReview,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("not found")
+		}
+		log.Error().Err(err).Msg("Error scanning user data")
+		return nil, err
+	}
+	if firstReview.Valid {
+		user.FirstReviewTime = firstReview.Time.Format(time.RFC3339)
+	}
+	if lastReview.Valid {
+		user.LastReviewTime = lastReview.Time.Format(time.RFC3339)
+	}
+
+	labelQuery := `SELECT fl.label, AVG(fl.rating_label), COUNT(*)
+	FROM Review r
+	JOIN Feedback_label fl ON r.rating_id = fl.rating_id
+	WHERE r.user_id = ? AND ` + visibleReview + `
+	GROUP BY fl.label`
+	rows, err := r.db.Query(labelQuery, id)
+	if err != nil {
+		log.Error().Err(err).Msg("Error executing query to find user label ratings")
+		return nil, err
+	}
+	defer rows.Close()
+
+	user.Labels = map[string]model.LabelRating{}
+	for rows.Next() {
+		var label string
+		var labelRating model.LabelRating
+		if err := rows.Scan(&label, &labelRating.Rating, &labelRating.Count); err != nil {
+			log.Error().Err(err).Msg("Error scanning user label rating data")
+			return nil, err
+		}
+		user.Labels[label] = labelRating
+	}
+
+	return &user, nil
+}
+
+func (r *repository) FindReviewsByUserID(id string, page int, isCount bool) ([]model.UserReview, int, error) {
+	log.Info().Msgf("Finding reviews for user ID: %s on page: %d, isCount: %v", id, page, isCount)
+
+	offset := (page - 1) * constant.NumberofRestaurantsperPage
+	limit := constant.NumberofRestaurantsperPage
+
+	query := `
+		SELECT 
+			r.rating_id,
+			u.user_name,
+			p.platform_name,
+			r.rating,
+			r.feedback,
+			r.review_time,
+			r.helpful_count,
+			r.unhelpful_count,
+			res.restaurant_id,
+			res.restaurant_name,
+			res.latitude,
+			res.longitude,
+			res.address,
+			res.restaurant_rating,
+			res.review_count,
+			res.city_id,
+			res.district_id,
+			Food_type.food_type_name
+		FROM 
+			Review r
+		JOIN 
+			User u ON r.user_id = u.user_id
+		JOIN 
+			Platform p ON u.platform_id = p.platform_id
+		JOIN 
+			Restaurant res ON r.restaurant_id = res.restaurant_id
+		JOIN 
+			Food_type ON res.food_type_id = Food_type.food_type_id
+		WHERE 
+			r.user_id = ? AND ` + visibleReview + `
+		ORDER BY 
+			r.review_time DESC
+		LIMIT ? OFFSET ?`
+
+	rows, err := r.db.Query(query, id, limit, offset)
+	if err != nil {
+		log.Error().Err(err).Msg("Error executing query to find user reviews")
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	userReviews := []model.UserReview{}
+	for rows.Next() {
+		var userReview model.UserReview
+		var reviewTime sql.NullTime
+		var feedback, address sql.NullString
+		if err := rows.Scan(
+			&userReview.RatingID,
+			&userReview.UserName,
+			&userReview.Platform,
+			&userReview.Rating,
+			&feedback,
+			&reviewTime,
+			&userReview.HelpfulCount,
+			&userReview.UnhelpfulCount,
+			&userReview.Restaurant.ID,
+			&userReview.Restaurant.Name,
+			&userReview.Restaurant.Latitude,
+			&userReview.Restaurant.Longitude,
+			&address,
+			&userReview.Restaurant.Rating,
+			&userReview.Restaurant.ReviewCount,
+			&userReview.Restaurant.CityID,
+			&userReview.Restaurant.DistrictID,
+			&userReview.Restaurant.FoodType,
+		); err != nil {
+			log.Error().Err(err).Msg("Error scanning user review data")
+			return nil, 0, err
+		}
+		userReview.Feedback = feedback.String
+		userReview.Restaurant.Address = address.String
+		if reviewTime.Valid {
+			userReview.ReviewTime = reviewTime.Time.Format(time.RFC3339)
+		}
+		userReviews = append(userReviews, userReview)
+	}
+
+	// Load the labels through the embedded reviews
+	reviews := make([]model.Review, len(userReviews))
+	for i := range userReviews {
+		reviews[i] = userReviews[i].Review
+	}
+	if err := r.attachReviewLabels(reviews, ""); err != nil {
+		return nil, 0, err
+	}
+	for i := range userReviews {
+		userReviews[i].Review = reviews[i]
+	}
+
+	var totalReviews int
+	if isCount {
+		err = r.db.QueryRow(`SELECT COUNT(*) FROM Review r WHERE r.user_id = ? AND `+visibleReview, id).Scan(&totalReviews)
+		if err != nil {
+			log.Error().Err(err).Msg("Error executing query to count user reviews")
+			return nil, 0, err
+		}
+	}
+
+	return userReviews, totalReviews, nil
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"skeleton-internship-backend/internal/constant"
+	"skeleton-internship-backend/internal/model"
+
+	"github.com/rs/zerolog/log"
+)
+
+// voteName converts a stored vote to its API name
+func voteName(helpful sql.NullBool) string {
+	if !helpful.Valid {
+		return constant.VoteNone
+	}
+	if helpful.Bool {
+		return constant.VoteHelpful
+	}
+	return constant.VoteUnhelpful
+}
+
+// voteDelta returns how a vote changes the helpful and unhelpful tallies
+func voteDelta(helpful sql.NullBool, sign int) (int, int) {
+	if !helpful.Valid {
+		return 0, 0
+	}
+	if helpful.Bool {
+		return sign, 0
+	}
+	return 0, sign
+}
+
+// SaveReviewVote records the vote of a voter on a review, replacing their previous vote.
+// A nil helpful removes the vote. Tallies on Review are updated in the same transaction.
+// Hidden reviews are not found, as they are not shown to voters.
+func (r *repository) SaveReviewVote(ratingID string, voterHash string, helpful *bool) (*model.ReviewVotes, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		log.Error().Err(err).Msg("Error starting transaction to save review vote")
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	votes := &model.ReviewVotes{RatingID: ratingID}
+	var status string
+	err = tx.QueryRow(`SELECT helpful_count, unhelpful_count, moderation_status FROM Review WHERE rating_id = ? FOR UPDATE`, ratingID).
+		Scan(&votes.HelpfulCount, &votes.UnhelpfulCount, &status)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("not found")
+		}
+		log.Error().Err(err).Msg("Error locking review to save vote")
+		return nil, err
+	}
+	if status == constant.ModerationHidden {
+		return nil, errors.New("not found")
+	}
+
+	var previous sql.NullBool
+	err = tx.QueryRow(`SELECT helpful FROM Review_vote WHERE rating_id = ? AND voter_hash = ?`, ratingID, voterHash).Scan(&previous)
+	if err != nil && err != sql.ErrNoRows {
+		log.Error().Err(err).Msg("Error finding previous vote")
+		return nil, err
+	}
+
+	var next sql.NullBool
+	if helpful != nil {
+		next = sql.NullBool{Bool: *helpful, Valid: true}
+	}
+	if previous == next {
+		votes.Vote = voteName(next)
+		return votes, nil
+	}
+
+	if next.Valid {
+		_, err = tx.Exec(`INSERT INTO Review_vote (rating_id, voter_hash, helpful) VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE helpful = VALUES(helpful), voted_at = CURRENT_TIMESTAMP`, ratingID, voterHash, next.Bool)
+	} else {
+		_, err = tx.Exec(`DELETE FROM Review_vote WHERE rating_id = ? AND voter_hash = ?`, ratingID, voterHash)
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("Error saving review vote")
+		return nil, err
+	}
+
+	removedHelpful, removedUnhelpful := voteDelta(previous, -1)
+	addedHelpful, addedUnhelpful := voteDelta(next, 1)
+	votes.HelpfulCount += removedHelpful + addedHelpful
+	votes.UnhelpfulCount += removedUnhelpful + addedUnhelpful
+	_, err = tx.Exec(`UPDATE Review SET helpful_count = ?, unhelpful_count = ? WHERE rating_id = ?`,
+		votes.HelpfulCount, votes.UnhelpfulCount, ratingID)
+	if err != nil {
+		log.Error().Err(err).Msg("Error updating review vote tallies")
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error().Err(err).Msg("Error committing review vote")
+		return nil, err
+	}
+
+	votes.Vote = voteName(next)
+	return votes, nil
+}
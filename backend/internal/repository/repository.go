@@ -1,12 +1,15 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"math"
+	"skeleton-internship-backend/config"
 	"skeleton-internship-backend/internal/model"
 	"sort"
 	"strings"
+	"time"
 
 	"skeleton-internship-backend/internal/constant"
 
@@ -16,26 +19,68 @@ import (
 type Repository interface {
 	FindRestaurantByID(id string, lat float64, lng float64) (*model.Restaurant, error)
 	FindAllFoodTypes() ([]string, error)
-	FindDishesByRestaurantID(id string) ([]model.Dish, error)
-	CalculateLabelsRating(id string) (float64, int, float64, int, float64, int, float64, int, float64, int, error)
+	FindDishesByRestaurantID(id string, searchWords []string, sortBy string) ([]model.Dish, error)
+	FindLabelsRating(id string) (*model.LabelsRating, error)
+	FindLabelsRatingByPlatform(id string) ([]model.PlatformLabelsRating, error)
 	CountReviewsByRestaurantID(id string) (int, error)
-	FindPlatformsAndRatingsByRestaurantID(id string) ([]string, []float64, error)
+	FindPlatformRatingsByRestaurantID(id string) ([]model.PlatformRating, error)
+	FindPlatformScales() ([]model.PlatformScale, error)
+	FindPlatformRatingCounts(platformID int) ([]model.RatingCount, error)
+	FindRestaurantRatingCounts() ([]model.RatingCount, error)
+	SavePlatformCalibration(platformID int, calibration map[float64]float64) error
 	FindRestaurantsByFilter(lat, lng float64, foodType string, cityID string, districtIDs []string, page int, limit int, isCount bool) ([]model.Restaurant, int, error)
 	FindNearbyRestaurants(lat, lng float64, limit int) ([]model.Restaurant, error)
-	FindReviewsByRestaurantIDAndLabel(id string, label string, page int, isCount bool, textOnly bool) ([]model.Review, int, error)
+	FindUnlabeledReviews(limit int) ([]model.UnlabeledReview, error)
+	SaveReviewLabels(labels map[string][]model.ReviewLabel) error
+	CreateReview(restaurantID string, userID string, rating float64, feedback string, labels []model.ReviewLabel) (*model.Review, error)
+	SaveReviewVote(ratingID string, voterHash string, helpful *bool) (*model.ReviewVotes, error)
+	ReportReview(ratingID string, reporterHash string, reason string, details string) error
+	FindModerationQueue(status string, page int) (*model.ModerationQueue, error)
+	ModerateReview(ratingID string, status string) error
+	FindReviewsByRestaurantID(id string, filter model.ReviewFilter, page int, isCount bool) ([]model.Review, int, error)
+	FindRatingDistribution(id string) (*model.RatingDistribution, error)
+	FindRatingTrend(id string, granularity string) (*model.RatingTrend, error)
+	FindLabeledFeedbackByRestaurantID(id string) ([]model.LabeledFeedback, error)
+	SaveRestaurantKeywords(restaurantID string, keywords []model.RestaurantKeyword) error
+	FindKeywordsByRestaurantID(id string) (*model.RestaurantKeywords, error)
+	FindUserByID(id string) (*model.UserProfile, error)
+	FindReviewsByUserID(id string, page int, isCount bool) ([]model.UserReview, int, error)
 	FindRestaurantsByName(searchWords []string, limit int) ([]model.Restaurant, error)
 	FindAllRestaurants() ([]string, []float64, []int, error)
-	UpdateRestaurantRating(id string, rating float64, reviewCount int) error
-	RecalculateCountReviews() error
-	RecalculateAverageRating() error
+	FindReviewTextsByRestaurantID(id string) ([]model.ReviewText, error)
+	MarkDuplicateReviews(duplicates []model.ReviewDuplicate) error
+	FindDuplicateClusters(restaurantID string, page int) (*model.DuplicateReport, error)
+	FindReviewActivityByRestaurantID(id string) ([]model.ReviewActivity, error)
+	SaveSuspiciousWindows(windows []model.SuspiciousWindow) error
+	FindSuspiciousWindows(restaurantID string, page int) (*model.SuspiciousReport, error)
+	RecalculateRestaurant(id string) error
+	FindDirtyRestaurants() ([]string, error)
+	RecalculateRestaurants() (int, error)
+	FindAspectWeights(restaurantID string) (map[string]float64, error)
+	StageRecalculation() error
+	FindStagedRatings() ([]model.StagedRating, time.Time, error)
+	ApplyStagedRecalculation() (*model.DryRunApplyResult, error)
+	FindRatingHistory(id string, page int) (*model.RatingHistory, error)
+	FindRatingMovers(fromVersion int, toVersion int, limit int) (*model.RatingMovers, error)
+	RefreshRankings(version int) error
+	FindRanking(cityID int, districtID int, foodType string, label string, limit int) (*model.Ranking, error)
+	AcquireLock(ctx context.Context, name string) (release func(), acquired bool, err error)
 }
 
+// visibleReview is the condition a review (aliased r) must meet to be listed
+const visibleReview = "r.moderation_status <> 'hidden'"
+
+// countedReview is the condition a review (aliased r) must meet to count toward ratings and review counts
+const countedReview = "r.duplicate_of IS NULL AND " + visibleReview
+
 type repository struct {
-	db *sql.DB
+	db       *sql.DB
+	cfg      *config.Config
+	strategy RatingStrategy
 }
 
-func NewRepository(db *sql.DB) Repository {
-	return &repository{db: db}
+func NewRepository(db *sql.DB, cfg *config.Config) Repository {
+	return &repository{db: db, cfg: cfg, strategy: NewRatingStrategy(cfg.Rating)}
 }
 
 // Haversine function to calculate distance (km)
@@ -55,11 +100,12 @@ func haversine(lat1, lon1, lat2, lon2 float64) float64 {
 }
 
 func (r *repository) FindRestaurantByID(id string, lat float64, lng float64) (*model.Restaurant, error) {
-	query := "SELECT restaurant_id, restaurant_name, latitude, longitude, address, restaurant_rating, review_count, city_id, district_id, Food_type.food_type_name FROM Restaurant JOIN Food_type ON Restaurant.food_type_id = Food_type.food_type_id WHERE restaurant_id = ?"
+	query := "SELECT restaurant_id, restaurant_name, latitude, longitude, address, restaurant_rating, review_count, city_id, district_id, Food_type.food_type_name, rating_version, recalculated_at FROM Restaurant JOIN Food_type ON Restaurant.food_type_id = Food_type.food_type_id WHERE restaurant_id = ?"
 	row := r.db.QueryRow(query, id)
 	var restaurant model.Restaurant
 	var address sql.NullString
-	if err := row.Scan(&restaurant.ID, &restaurant.Name, &restaurant.Latitude, &restaurant.Longitude, &address, &restaurant.Rating, &restaurant.ReviewCount, &restaurant.CityID, &restaurant.DistrictID, &restaurant.FoodType); err != nil {
+	var recalculatedAt sql.NullTime
+	if err := row.Scan(&restaurant.ID, &restaurant.Name, &restaurant.Latitude, &restaurant.Longitude, &address, &restaurant.Rating, &restaurant.ReviewCount, &restaurant.CityID, &restaurant.DistrictID, &restaurant.FoodType, &restaurant.RatingVersion, &recalculatedAt); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, errors.New("not found")
 		}
@@ -71,6 +117,9 @@ func (r *repository) FindRestaurantByID(id string, lat float64, lng float64) (*m
 	} else {
 		restaurant.Address = "" // Empty string for NULL address
 	}
+	if recalculatedAt.Valid {
+		restaurant.RecalculatedAt = recalculatedAt.Time.Format(time.RFC3339)
+	}
 
 	if lat != 0 && lng != 0 {
 		restaurant.Distance = haversine(lat, lng, restaurant.Latitude, restaurant.Longitude)
@@ -81,33 +130,6 @@ func (r *repository) FindRestaurantByID(id string, lat float64, lng float64) (*m
 	return &restaurant, nil
 }
 
-func (r *repository) FindPlatformsAndRatingsByRestaurantID(id string) ([]string, []float64, error) {
-	query := `SELECT Platform.platform_name, Temp.restaurant_rating 
-	FROM Temp JOIN Platform ON Temp.platform_id = Platform.platform_id 
-	WHERE Temp.restaurant_id = ?`
-	rows, err := r.db.Query(query, id)
-	if err != nil {
-		log.Error().Err(err).Msg("Error executing query to find platforms and ratings by restaurant ID")
-		return nil, nil, err
-	}
-	defer rows.Close()
-
-	var platforms []string
-	var ratings []float64
-	for rows.Next() {
-		var platform string
-		var rating float64
-		if err := rows.Scan(&platform, &rating); err != nil {
-			log.Error().Err(err).Msg("Error scanning platform and rating data")
-			return nil, nil, err
-		}
-		platforms = append(platforms, platform)
-		ratings = append(ratings, rating)
-	}
-
-	return platforms, ratings, nil
-}
-
 func (r *repository) FindRestaurantsByFilter(lat, lng float64, foodType string, cityID string, districtIDs []string, page int, limit int, isCount bool) ([]model.Restaurant, int, error) {
 	var queryBuilder strings.Builder
 	var args []interface{}
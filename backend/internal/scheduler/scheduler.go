@@ -0,0 +1,307 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"skeleton-internship-backend/config"
+	"skeleton-internship-backend/internal/constant"
+	"skeleton-internship-backend/internal/job"
+	"skeleton-internship-backend/internal/model"
+	"skeleton-internship-backend/internal/repository"
+	"skeleton-internship-backend/internal/service"
+
+	"github.com/robfig/cron/v3"
+	"github.com/rs/zerolog/log"
+	"go.uber.org/fx"
+)
+
+// schedule is a job kind run on a cron schedule, with the outcome of its last run
+type schedule struct {
+	kind    string
+	spec    string
+	run     job.Func
+	entryID cron.EntryID
+
+	lastRun     time.Time
+	lastOutcome string
+	lastError   string
+	lastJobID   string
+}
+
+// heldLock is a replica lock held by this instance, for a running job or, once a scheduled
+// run is done, until the end of its jitter window
+type heldLock struct {
+	kind    string
+	release func()
+	// Set while the lock is only kept for the jitter window
+	timer *time.Timer
+}
+
+// Scheduler starts recalculation and export jobs from the cron expressions of the
+// configuration. Runs are skipped when the same kind of job is already running on
+// this instance, or on another replica holding the MySQL lock of that kind.
+type Scheduler struct {
+	cron      *cron.Cron
+	jobs      *job.Manager
+	repo      repository.Repository
+	jitter    time.Duration
+	mu        sync.Mutex
+	schedules []*schedule
+	// Replica locks held by this instance by name, guarded by mu
+	held   map[string]*heldLock
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func NewScheduler(lifecycle fx.Lifecycle, cfg *config.Config, service service.Service, jobs *job.Manager, repo repository.Repository) (*Scheduler, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Scheduler{
+		cron:   cron.New(cron.WithChain(cron.Recover(cronLogger{}))),
+		jobs:   jobs,
+		held:   map[string]*heldLock{},
+		repo:   repo,
+		jitter: cfg.Schedule.Jitter,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+
+	if err := s.add(constant.JobKindRecalculate, cfg.Schedule.Recalculate, service.RecalculateRestaurantsRating); err != nil {
+		cancel()
+		return nil, err
+	}
+	if err := s.add(constant.JobKindRecalculateDirty, cfg.Schedule.RecalculateDirty, service.RecalculateDirtyRestaurants); err != nil {
+		cancel()
+		return nil, err
+	}
+	if err := s.add(constant.JobKindExport, cfg.Schedule.Export, service.ExportRestaurantsToCSV); err != nil {
+		cancel()
+		return nil, err
+	}
+
+	lifecycle.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			s.cron.Start()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			log.Info().Msg("Stopping scheduler")
+			s.cancel()
+			select {
+			case <-s.cron.Stop().Done():
+			case <-ctx.Done():
+			}
+			return nil
+		},
+	})
+	return s, nil
+}
+
+// add schedules a job kind, empty specs leave it unscheduled
+func (s *Scheduler) add(kind string, spec string, run job.Func) error {
+	if spec == "" {
+		return nil
+	}
+
+	sch := &schedule{kind: kind, spec: spec, run: run}
+	entryID, err := s.cron.AddFunc(spec, func() { s.tick(sch) })
+	if err != nil {
+		log.Error().Err(err).Msgf("Invalid schedule %q for %s", spec, kind)
+		return err
+	}
+	sch.entryID = entryID
+	s.schedules = append(s.schedules, sch)
+	log.Info().Msgf("Scheduled %s at %q", kind, spec)
+	return nil
+}
+
+// tick runs one occurrence of a schedule after a random delay
+func (s *Scheduler) tick(sch *schedule) {
+	scheduledAt := time.Now()
+	if s.jitter > 0 {
+		select {
+		case <-time.After(time.Duration(rand.Int63n(int64(s.jitter)))):
+		case <-s.ctx.Done():
+			return
+		}
+	}
+
+	outcome, jobID, err := s.start(sch, scheduledAt.Add(s.jitter))
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to start scheduled %s", sch.kind)
+	} else {
+		log.Info().Msgf("Scheduled %s: %s", sch.kind, outcome)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sch.lastRun = time.Now()
+	sch.lastOutcome = outcome
+	sch.lastJobID = jobID
+	sch.lastError = ""
+	if err != nil {
+		sch.lastError = err.Error()
+	}
+}
+
+// start takes the replica lock of the schedule and starts its job. The lock is held
+// until the job is done and the jitter window of this occurrence is over, so replicas
+// firing later in the window do not run the job again.
+func (s *Scheduler) start(sch *schedule, holdUntil time.Time) (string, string, error) {
+	if s.jobs.Running(sch.kind) {
+		return constant.ScheduleOutcomeSkippedRunning, "", nil
+	}
+
+	unlock, owner, err := s.lock(s.ctx, sch.kind)
+	if err != nil {
+		return constant.ScheduleOutcomeError, "", err
+	}
+	if owner != "" {
+		log.Info().Msgf("Skipping scheduled %s, lock held by %s", sch.kind, owner)
+		return constant.ScheduleOutcomeSkippedLocked, "", nil
+	}
+
+	started, isNew, err := s.jobs.Start(sch.kind, func(ctx context.Context) error {
+		defer unlock(holdUntil)
+		return sch.run(ctx)
+	})
+	if err != nil {
+		unlock(time.Time{})
+		return constant.ScheduleOutcomeError, "", err
+	}
+	if !isNew {
+		unlock(time.Time{})
+		return constant.ScheduleOutcomeSkippedRunning, started.ID, nil
+	}
+	return constant.ScheduleOutcomeStarted, started.ID, nil
+}
+
+// Locked wraps a job started by hand so it takes the replica lock of its kind, like
+// scheduled runs do. The job fails when the lock is held by a job of another replica,
+// or by another running job of this instance.
+func (s *Scheduler) Locked(kind string, run job.Func) job.Func {
+	return func(ctx context.Context) error {
+		unlock, owner, err := s.lock(ctx, kind)
+		if err != nil {
+			return err
+		}
+		if owner != "" {
+			log.Info().Msgf("Not running %s, lock held by %s", kind, owner)
+			return fmt.Errorf("the lock of this job is held by %s", owner)
+		}
+		defer unlock(time.Time{})
+		return run(ctx)
+	}
+}
+
+// lockName returns the MySQL lock guarding a job kind. Jobs writing ratings share one,
+// so no two of them run at the same time across replicas.
+func lockName(kind string) string {
+	for _, ratingKind := range constant.RatingJobKinds {
+		if kind == ratingKind {
+			return constant.ScheduleLockPrefix + constant.RatingsLock
+		}
+	}
+	return constant.ScheduleLockPrefix + kind
+}
+
+// lock takes the replica lock of a job kind. A lock this instance still holds after a
+// scheduled run, until the end of its jitter window, is handed over. When the lock is
+// held by someone else, owner describes them. unlock releases the lock at holdUntil,
+// or right away when it is zero or past.
+func (s *Scheduler) lock(ctx context.Context, kind string) (unlock func(holdUntil time.Time), owner string, err error) {
+	name := lockName(kind)
+
+	s.mu.Lock()
+	if held, ok := s.held[name]; ok {
+		if held.timer == nil {
+			s.mu.Unlock()
+			return nil, fmt.Sprintf("a %s job on this instance", held.kind), nil
+		}
+		if !held.timer.Stop() {
+			s.mu.Unlock()
+			return nil, fmt.Sprintf("a finished %s job on this instance releasing it", held.kind), nil
+		}
+		held.kind = kind
+		held.timer = nil
+		s.mu.Unlock()
+		return s.unlocker(name, held), "", nil
+	}
+	s.mu.Unlock()
+
+	release, acquired, err := s.repo.AcquireLock(ctx, name)
+	if err != nil {
+		return nil, "", err
+	}
+	if !acquired {
+		return nil, "another replica", nil
+	}
+
+	held := &heldLock{kind: kind, release: release}
+	s.mu.Lock()
+	s.held[name] = held
+	s.mu.Unlock()
+	return s.unlocker(name, held), "", nil
+}
+
+// unlocker returns the function releasing a held lock, now or at the given time
+func (s *Scheduler) unlocker(name string, held *heldLock) func(time.Time) {
+	releaseNow := func() {
+		s.mu.Lock()
+		delete(s.held, name)
+		s.mu.Unlock()
+		held.release()
+	}
+	return func(holdUntil time.Time) {
+		if wait := time.Until(holdUntil); wait > 0 {
+			s.mu.Lock()
+			held.timer = time.AfterFunc(wait, releaseNow)
+			s.mu.Unlock()
+			return
+		}
+		releaseNow()
+	}
+}
+
+// Statuses returns the schedules with their next run and the outcome of their last run
+func (s *Scheduler) Statuses() []model.ScheduleStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statuses := make([]model.ScheduleStatus, 0, len(s.schedules))
+	for _, sch := range s.schedules {
+		status := model.ScheduleStatus{
+			Kind:        sch.kind,
+			Schedule:    sch.spec,
+			LastOutcome: sch.lastOutcome,
+			LastError:   sch.lastError,
+		}
+		if next := s.cron.Entry(sch.entryID).Next; !next.IsZero() {
+			status.NextRun = next.Format(time.RFC3339)
+		}
+		if !sch.lastRun.IsZero() {
+			status.LastRun = sch.lastRun.Format(time.RFC3339)
+		}
+		if sch.lastJobID != "" {
+			if lastJob, ok := s.jobs.Get(sch.lastJobID); ok {
+				status.LastJob = &lastJob
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// cronLogger writes the logs of cron, such as recovered panics, with zerolog
+type cronLogger struct{}
+
+func (cronLogger) Info(msg string, keysAndValues ...interface{}) {
+	log.Info().Fields(keysAndValues).Msgf("Cron: %s", msg)
+}
+
+func (cronLogger) Error(err error, msg string, keysAndValues ...interface{}) {
+	log.Error().Err(err).Fields(keysAndValues).Msgf("Cron: %s", msg)
+}
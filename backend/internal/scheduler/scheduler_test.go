@@ -0,0 +1,202 @@
+package scheduler
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"skeleton-internship-backend/internal/constant"
+	"skeleton-internship-backend/internal/job"
+	"skeleton-internship-backend/internal/model"
+	"skeleton-internship-backend/internal/repository"
+
+	"go.uber.org/fx/fxtest"
+)
+
+// fakeLocks stands for the MySQL named locks. Methods it does not override panic
+// through the nil embedded Repository.
+type fakeLocks struct {
+	repository.Repository
+
+	mu sync.Mutex
+	// Locks held by this instance
+	held map[string]bool
+	// Locks held by another replica
+	other    map[string]bool
+	released []string
+}
+
+func (f *fakeLocks) AcquireLock(ctx context.Context, name string) (func(), bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.held[name] || f.other[name] {
+		return nil, false, nil
+	}
+	f.held[name] = true
+	return func() {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		delete(f.held, name)
+		f.released = append(f.released, name)
+	}, true, nil
+}
+
+func (f *fakeLocks) isHeld(name string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.held[name]
+}
+
+const ratingsLock = constant.ScheduleLockPrefix + constant.RatingsLock
+
+func newTestScheduler(t *testing.T) (*Scheduler, *fakeLocks) {
+	locks := &fakeLocks{held: map[string]bool{}, other: map[string]bool{}}
+	return &Scheduler{
+		jobs: job.NewManager(fxtest.NewLifecycle(t)),
+		repo: locks,
+		held: map[string]*heldLock{},
+		ctx:  context.Background(),
+	}, locks
+}
+
+// waitJob waits for the job with the given ID to finish
+func waitJob(t *testing.T, jobs *job.Manager, id string) model.Job {
+	t.Helper()
+	for i := 0; i < 1000; i++ {
+		found, _ := jobs.Get(id)
+		if found.Status != constant.JobStatusRunning {
+			return found
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("job %s did not finish", id)
+	return model.Job{}
+}
+
+func TestLockName(t *testing.T) {
+	for _, kind := range constant.RatingJobKinds {
+		if got := lockName(kind); got != ratingsLock {
+			t.Errorf("lockName(%s) = %s, want %s", kind, got, ratingsLock)
+		}
+	}
+	if got := lockName(constant.JobKindExport); got != constant.ScheduleLockPrefix+constant.JobKindExport {
+		t.Errorf("lockName(export) = %s, want its own lock", got)
+	}
+}
+
+func TestLockedHeldByAnotherReplica(t *testing.T) {
+	s, locks := newTestScheduler(t)
+	locks.other[ratingsLock] = true
+
+	ran := false
+	err := s.Locked(constant.JobKindRecalculateDirty, func(context.Context) error {
+		ran = true
+		return nil
+	})(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "another replica") {
+		t.Errorf("Locked() error = %v, want held by another replica", err)
+	}
+	if ran {
+		t.Error("job ran without the lock")
+	}
+}
+
+func TestLockedRatingJobsExcludeEachOther(t *testing.T) {
+	s, locks := newTestScheduler(t)
+
+	running := make(chan struct{})
+	done := make(chan struct{})
+	result := make(chan error)
+	go func() {
+		result <- s.Locked(constant.JobKindRecalculate, func(context.Context) error {
+			close(running)
+			<-done
+			return nil
+		})(context.Background())
+	}()
+	<-running
+
+	err := s.Locked(constant.JobKindLabelReviews, func(context.Context) error { return nil })(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "a recalculate job on this instance") {
+		t.Errorf("Locked() error = %v, want held by the recalculate job", err)
+	}
+
+	close(done)
+	if err := <-result; err != nil {
+		t.Fatalf("Locked() error = %v", err)
+	}
+	if locks.isHeld(ratingsLock) {
+		t.Error("lock still held after the job")
+	}
+}
+
+func TestScheduledLockHandedOverToManualRun(t *testing.T) {
+	s, locks := newTestScheduler(t)
+
+	sch := &schedule{kind: constant.JobKindRecalculate, run: func(context.Context) error { return nil }}
+	outcome, jobID, err := s.start(sch, time.Now().Add(time.Hour))
+	if err != nil || outcome != constant.ScheduleOutcomeStarted {
+		t.Fatalf("start() = %s, %v, want started", outcome, err)
+	}
+	waitJob(t, s.jobs, jobID)
+	// Kept for the jitter window
+	if !locks.isHeld(ratingsLock) {
+		t.Fatal("lock released before the end of the jitter window")
+	}
+
+	ran := false
+	err = s.Locked(constant.JobKindRecalculateDirty, func(context.Context) error {
+		ran = true
+		return nil
+	})(context.Background())
+	if err != nil || !ran {
+		t.Fatalf("Locked() error = %v, ran = %v, want the job to take over the lock", err, ran)
+	}
+	if locks.isHeld(ratingsLock) || len(locks.released) != 1 {
+		t.Errorf("lock held = %v, released %d times, want released once", locks.isHeld(ratingsLock), len(locks.released))
+	}
+}
+
+func TestScheduledLockReleasedAfterWindow(t *testing.T) {
+	s, locks := newTestScheduler(t)
+
+	sch := &schedule{kind: constant.JobKindExport, run: func(context.Context) error { return nil }}
+	_, jobID, err := s.start(sch, time.Now().Add(20*time.Millisecond))
+	if err != nil {
+		t.Fatalf("start() error = %v", err)
+	}
+	waitJob(t, s.jobs, jobID)
+
+	name := constant.ScheduleLockPrefix + constant.JobKindExport
+	for i := 0; i < 1000 && locks.isHeld(name); i++ {
+		time.Sleep(time.Millisecond)
+	}
+	if locks.isHeld(name) {
+		t.Error("lock still held after the jitter window")
+	}
+}
+
+func TestScheduledRunSkipped(t *testing.T) {
+	s, locks := newTestScheduler(t)
+	sch := &schedule{kind: constant.JobKindRecalculate, run: func(context.Context) error { return nil }}
+
+	locks.other[ratingsLock] = true
+	if outcome, _, err := s.start(sch, time.Now()); err != nil || outcome != constant.ScheduleOutcomeSkippedLocked {
+		t.Errorf("start() with the lock held elsewhere = %s, %v, want %s", outcome, err, constant.ScheduleOutcomeSkippedLocked)
+	}
+	delete(locks.other, ratingsLock)
+
+	block := make(chan struct{})
+	defer close(block)
+	if _, _, err := s.jobs.Start(constant.JobKindRecalculate, func(context.Context) error {
+		<-block
+		return nil
+	}); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if outcome, _, err := s.start(sch, time.Now()); err != nil || outcome != constant.ScheduleOutcomeSkippedRunning {
+		t.Errorf("start() with the job running = %s, %v, want %s", outcome, err, constant.ScheduleOutcomeSkippedRunning)
+	}
+}
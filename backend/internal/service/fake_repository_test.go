@@ -0,0 +1,132 @@
+package service
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"skeleton-internship-backend/config"
+	"skeleton-internship-backend/internal/model"
+	"skeleton-internship-backend/internal/repository"
+)
+
+// fakeRepository keeps the data a test needs in memory. Methods it does not override
+// panic through the nil embedded Repository, so a test touching them fails loudly.
+type fakeRepository struct {
+	repository.Repository
+
+	unlabeled []model.UnlabeledReview
+	saved     map[string][]model.ReviewLabel
+	batches   int
+	dirty     []string
+
+	staged   []model.StagedRating
+	stagedAt time.Time
+	applied  *model.DryRunApplyResult
+
+	scales         []model.PlatformScale
+	ownCounts      []model.RatingCount
+	platformCounts map[int][]model.RatingCount
+	calibrations   map[int]map[float64]float64
+
+	restaurants []string
+	feedback    map[string][]model.LabeledFeedback
+	keywords    map[string][]model.RestaurantKeyword
+
+	// Steps run after a recalculation, in order
+	steps []string
+}
+
+func newFakeRepository() *fakeRepository {
+	return &fakeRepository{
+		saved:          map[string][]model.ReviewLabel{},
+		platformCounts: map[int][]model.RatingCount{},
+		calibrations:   map[int]map[float64]float64{},
+		feedback:       map[string][]model.LabeledFeedback{},
+		keywords:       map[string][]model.RestaurantKeyword{},
+	}
+}
+
+func (f *fakeRepository) FindUnlabeledReviews(limit int) ([]model.UnlabeledReview, error) {
+	f.batches++
+	var reviews []model.UnlabeledReview
+	for _, review := range f.unlabeled {
+		if _, ok := f.saved[review.RatingID]; ok {
+			continue
+		}
+		if len(reviews) == limit {
+			break
+		}
+		reviews = append(reviews, review)
+	}
+	return reviews, nil
+}
+
+func (f *fakeRepository) SaveReviewLabels(labels map[string][]model.ReviewLabel) error {
+	for id, reviewLabels := range labels {
+		f.saved[id] = reviewLabels
+	}
+	return nil
+}
+
+func (f *fakeRepository) FindDirtyRestaurants() ([]string, error) {
+	return f.dirty, nil
+}
+
+func (f *fakeRepository) FindStagedRatings() ([]model.StagedRating, time.Time, error) {
+	staged := make([]model.StagedRating, len(f.staged))
+	copy(staged, f.staged)
+	return staged, f.stagedAt, nil
+}
+
+func (f *fakeRepository) ApplyStagedRecalculation() (*model.DryRunApplyResult, error) {
+	if f.applied == nil {
+		return nil, errors.New("not found")
+	}
+	f.steps = append(f.steps, "apply")
+	return f.applied, nil
+}
+
+func (f *fakeRepository) RefreshRankings(version int) error {
+	f.steps = append(f.steps, "rankings "+strconv.Itoa(version))
+	return nil
+}
+
+func (f *fakeRepository) FindPlatformScales() ([]model.PlatformScale, error) {
+	return f.scales, nil
+}
+
+func (f *fakeRepository) FindRestaurantRatingCounts() ([]model.RatingCount, error) {
+	return f.ownCounts, nil
+}
+
+func (f *fakeRepository) FindPlatformRatingCounts(platformID int) ([]model.RatingCount, error) {
+	return f.platformCounts[platformID], nil
+}
+
+func (f *fakeRepository) SavePlatformCalibration(platformID int, calibration map[float64]float64) error {
+	f.steps = append(f.steps, "calibration "+strconv.Itoa(platformID))
+	f.calibrations[platformID] = calibration
+	return nil
+}
+
+func (f *fakeRepository) FindAllRestaurants() ([]string, []float64, []int, error) {
+	return f.restaurants, nil, nil, nil
+}
+
+func (f *fakeRepository) FindLabeledFeedbackByRestaurantID(id string) ([]model.LabeledFeedback, error) {
+	return f.feedback[id], nil
+}
+
+func (f *fakeRepository) SaveRestaurantKeywords(restaurantID string, keywords []model.RestaurantKeyword) error {
+	f.steps = append(f.steps, "keywords "+restaurantID)
+	f.keywords[restaurantID] = keywords
+	return nil
+}
+
+// newTestService returns a service over the fake repository with the given labeler
+func newTestService(repo *fakeRepository, labeler Labeler) *service {
+	cfg := &config.Config{}
+	cfg.Labeler.BatchSize = 2
+	return &service{repo: repo, labeler: labeler, cfg: cfg}
+}
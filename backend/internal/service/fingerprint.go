@@ -0,0 +1,103 @@
+package service
+
+import (
+	"hash/fnv"
+	"strings"
+	"unicode"
+
+	"skeleton-internship-backend/internal/constant"
+)
+
+// minHashSeeds holds one seed per MinHash function, generated once with splitmix64
+// so signatures stay comparable between runs
+var minHashSeeds = func() []uint64 {
+	seeds := make([]uint64, constant.DuplicateMinHashCount)
+	state := uint64(0x9e3779b97f4a7c15)
+	for i := range seeds {
+		state += 0x9e3779b97f4a7c15
+		seeds[i] = mix64(state)
+	}
+	return seeds
+}()
+
+// mix64 is the splitmix64 finalizer, used to derive independent hash functions from one shingle hash
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+// normalizeWords lowercases a text and splits it into words, dropping punctuation.
+// Vietnamese letters with diacritics are kept as they are.
+func normalizeWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// shingles returns the hashes of the distinct word n-grams of a text
+func shingles(words []string, size int) map[uint64]struct{} {
+	set := map[uint64]struct{}{}
+	if len(words) == 0 {
+		return set
+	}
+	if len(words) < size {
+		size = len(words)
+	}
+	for i := 0; i+size <= len(words); i++ {
+		h := fnv.New64a()
+		h.Write([]byte(strings.Join(words[i:i+size], " ")))
+		set[h.Sum64()] = struct{}{}
+	}
+	return set
+}
+
+// minHash computes the MinHash signature of a shingle set
+func minHash(set map[uint64]struct{}) []uint64 {
+	signature := make([]uint64, len(minHashSeeds))
+	for i := range signature {
+		signature[i] = ^uint64(0)
+	}
+	for shingle := range set {
+		for i, seed := range minHashSeeds {
+			if h := mix64(shingle ^ seed); h < signature[i] {
+				signature[i] = h
+			}
+		}
+	}
+	return signature
+}
+
+// lshBands hashes each band of a signature, texts sharing any band are candidate near duplicates
+func lshBands(signature []uint64) []uint64 {
+	rowsPerBand := len(signature) / constant.DuplicateLSHBands
+	bands := make([]uint64, constant.DuplicateLSHBands)
+	for b := range bands {
+		h := uint64(b)
+		for _, value := range signature[b*rowsPerBand : (b+1)*rowsPerBand] {
+			h = mix64(h ^ value)
+		}
+		bands[b] = h
+	}
+	return bands
+}
+
+// jaccard returns the exact Jaccard similarity of two shingle sets
+func jaccard(a, b map[uint64]struct{}) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	intersection := 0
+	for shingle := range a {
+		if _, ok := b[shingle]; ok {
+			intersection++
+		}
+	}
+	return float64(intersection) / float64(len(a)+len(b)-intersection)
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeWords(t *testing.T) {
+	got := normalizeWords("Phở NGON, giá 50k!!")
+	want := []string{"phở", "ngon", "giá", "50k"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("normalizeWords() = %v, want %v", got, want)
+	}
+}
+
+func TestShingles(t *testing.T) {
+	tests := []struct {
+		name  string
+		words []string
+		size  int
+		want  int
+	}{
+		{name: "one per n-gram", words: []string{"a", "b", "c", "d"}, size: 3, want: 2},
+		{name: "repeated n-grams count once", words: []string{"a", "b", "a", "b", "a"}, size: 2, want: 2},
+		{name: "short text is one shingle", words: []string{"a", "b"}, size: 3, want: 1},
+		{name: "empty text", words: nil, size: 3, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := len(shingles(tt.words, tt.size)); got != tt.want {
+				t.Errorf("len(shingles()) = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJaccard(t *testing.T) {
+	set := func(values ...uint64) map[uint64]struct{} {
+		s := map[uint64]struct{}{}
+		for _, v := range values {
+			s[v] = struct{}{}
+		}
+		return s
+	}
+	tests := []struct {
+		name string
+		a, b map[uint64]struct{}
+		want float64
+	}{
+		{name: "identical", a: set(1, 2, 3), b: set(1, 2, 3), want: 1},
+		{name: "disjoint", a: set(1, 2), b: set(3, 4), want: 0},
+		{name: "overlap", a: set(1, 2, 3), b: set(2, 3, 4), want: 0.5},
+		{name: "subset", a: set(1), b: set(1, 2, 3, 4), want: 0.25},
+		{name: "both empty", a: set(), b: set(), want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := jaccard(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("jaccard() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMinHashBands(t *testing.T) {
+	text := normalizeWords("quán phở này nước dùng rất ngon thịt mềm giá hợp lý nhân viên thân thiện")
+	same := shingles(text, 3)
+	if !reflect.DeepEqual(lshBands(minHash(same)), lshBands(minHash(shingles(text, 3)))) {
+		t.Fatal("the same text gave different bands")
+	}
+
+	near := normalizeWords("quán phở này nước dùng rất ngon thịt mềm giá hợp lý nhân viên thân thiện lắm")
+	other := normalizeWords("trà sữa ở đây quá ngọt trân châu cứng không gian chật chội và ồn ào")
+	bands := lshBands(minHash(same))
+	if !sharesBand(bands, lshBands(minHash(shingles(near, 3)))) {
+		t.Error("near duplicate shares no band")
+	}
+	if sharesBand(bands, lshBands(minHash(shingles(other, 3)))) {
+		t.Error("unrelated text shares a band")
+	}
+}
+
+func sharesBand(a, b []uint64) bool {
+	for i := range a {
+		if a[i] == b[i] {
+			return true
+		}
+	}
+	return false
+}
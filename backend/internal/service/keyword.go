@@ -0,0 +1,138 @@
+package service
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"skeleton-internship-backend/internal/constant"
+	"skeleton-internship-backend/internal/model"
+)
+
+// keywordStopWords are Vietnamese function words, pronouns, particles and intensifiers.
+// Phrases cannot start or end with them, so "rất ngon" and "ngon quá" are not kept
+// but "nước dùng đậm đà" is. Negations like "không" and "chưa" are left out on purpose
+// since they carry the meaning of phrases like "không ngon".
+var keywordStopWords = map[string]bool{}
+
+func init() {
+	for _, word := range strings.Fields(`
+		à ạ ai anh bạn bị các cái cho chị chứ có con còn của cũng đã đang để đến đi đó
+		được đấy đây em gì hay hơn khi kia là lại lắm luôn mà mình mọi một nè nha nhé
+		như những nhất nên nếu nào này nữa ơi ở quá ra rất rồi sẽ sau tại thấy thế thì
+		thôi tôi tới trong trước từ và vào vậy về vì với vẫn lúc theo khá hơi cực siêu
+		ok oke thật thực sự mấy nhiều ít chỉ cả đều
+	`) {
+		keywordStopWords[word] = true
+	}
+}
+
+// keywordClauses splits a feedback into lowercase word lists, one per clause, so phrases
+// never span punctuation
+func keywordClauses(feedback string) [][]string {
+	var clauses [][]string
+	for _, clause := range strings.FieldsFunc(feedback, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.IsSpace(r)
+	}) {
+		if words := normalizeWords(clause); len(words) > 0 {
+			clauses = append(clauses, words)
+		}
+	}
+	return clauses
+}
+
+// keywordPhrases returns the distinct significant n-grams of a feedback
+func keywordPhrases(feedback string) map[string]struct{} {
+	phrases := map[string]struct{}{}
+	for _, words := range keywordClauses(feedback) {
+		for size := constant.KeywordMinWords; size <= constant.KeywordMaxWords; size++ {
+			for i := 0; i+size <= len(words); i++ {
+				gram := words[i : i+size]
+				if keywordStopWords[gram[0]] || keywordStopWords[gram[size-1]] {
+					continue
+				}
+				phrases[strings.Join(gram, " ")] = struct{}{}
+			}
+		}
+	}
+	return phrases
+}
+
+// keywordSentiment classifies a label rating, neutral ratings return an empty string
+func keywordSentiment(rating float64) string {
+	switch {
+	case rating >= constant.KeywordPositiveMinRating:
+		return constant.KeywordSentimentPositive
+	case rating <= constant.KeywordNegativeMaxRating:
+		return constant.KeywordSentimentNegative
+	}
+	return ""
+}
+
+// extractKeywords finds the phrases used by the most reviews for each aspect label and
+// sentiment. A phrase found in exactly the same reviews as a longer phrase containing it
+// is dropped in favor of the longer one, so "nước dùng" gives way to "nước dùng đậm đà".
+func extractKeywords(feedbacks []model.LabeledFeedback) []model.RestaurantKeyword {
+	type group struct{ label, sentiment string }
+	counts := map[group]map[string]int{}
+	for _, feedback := range feedbacks {
+		sentiment := keywordSentiment(feedback.RatingLabel)
+		if feedback.Label == constant.LabelUnknown || sentiment == "" {
+			continue
+		}
+		key := group{feedback.Label, sentiment}
+		if counts[key] == nil {
+			counts[key] = map[string]int{}
+		}
+		for phrase := range keywordPhrases(feedback.Feedback) {
+			counts[key][phrase]++
+		}
+	}
+
+	var keywords []model.RestaurantKeyword
+	for key, phrases := range counts {
+		var candidates []model.RestaurantKeyword
+		for phrase, count := range phrases {
+			if count >= constant.KeywordMinReviews {
+				candidates = append(candidates, model.RestaurantKeyword{
+					Label:       key.label,
+					Sentiment:   key.sentiment,
+					Phrase:      phrase,
+					ReviewCount: count,
+				})
+			}
+		}
+		// Most used first, longer phrases first on ties
+		sort.Slice(candidates, func(i, j int) bool {
+			if candidates[i].ReviewCount != candidates[j].ReviewCount {
+				return candidates[i].ReviewCount > candidates[j].ReviewCount
+			}
+			if len(candidates[i].Phrase) != len(candidates[j].Phrase) {
+				return len(candidates[i].Phrase) > len(candidates[j].Phrase)
+			}
+			return candidates[i].Phrase < candidates[j].Phrase
+		})
+
+		var kept []model.RestaurantKeyword
+		for _, candidate := range candidates {
+			subsumed := false
+			for _, longer := range kept {
+				if longer.ReviewCount == candidate.ReviewCount &&
+					strings.Contains(" "+longer.Phrase+" ", " "+candidate.Phrase+" ") {
+					subsumed = true
+					break
+				}
+			}
+			if subsumed {
+				continue
+			}
+			kept = append(kept, candidate)
+			if len(kept) == constant.KeywordsPerGroup {
+				break
+			}
+		}
+		keywords = append(keywords, kept...)
+	}
+
+	return keywords
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+
+	"skeleton-internship-backend/internal/constant"
+	"skeleton-internship-backend/internal/model"
+)
+
+func TestKeywordClauses(t *testing.T) {
+	got := keywordClauses("Phở NGON, nước dùng đậm đà!! 10/10")
+	want := [][]string{{"phở", "ngon"}, {"nước", "dùng", "đậm", "đà"}, {"10"}, {"10"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("keywordClauses() = %v, want %v", got, want)
+	}
+	if got := keywordClauses(" ... "); got != nil {
+		t.Errorf("keywordClauses() of punctuation = %v, want none", got)
+	}
+}
+
+func TestKeywordPhrases(t *testing.T) {
+	tests := []struct {
+		name     string
+		feedback string
+		want     []string
+	}{
+		{
+			name:     "every n-gram of a clause",
+			feedback: "nước dùng đậm đà",
+			want:     []string{"dùng đậm", "dùng đậm đà", "nước dùng", "nước dùng đậm", "nước dùng đậm đà", "đậm đà"},
+		},
+		{name: "phrases cannot start or end with a stopword", feedback: "rất ngon quá", want: []string{}},
+		{name: "stopwords inside a phrase are kept", feedback: "giá hơi cao", want: []string{"giá hơi cao"}},
+		{name: "negations are kept", feedback: "không ngon", want: []string{"không ngon"}},
+		{name: "phrases do not span punctuation", feedback: "phở ngon, giá rẻ", want: []string{"giá rẻ", "phở ngon"}},
+		{name: "repeated phrases count once", feedback: "phở ngon. Phở ngon!", want: []string{"phở ngon"}},
+		{name: "single words are too short", feedback: "ngon", want: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []string{}
+			for phrase := range keywordPhrases(tt.feedback) {
+				got = append(got, phrase)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("keywordPhrases(%q) = %v, want %v", tt.feedback, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeywordSentiment(t *testing.T) {
+	tests := []struct {
+		rating float64
+		want   string
+	}{
+		{rating: 5, want: constant.KeywordSentimentPositive},
+		{rating: constant.KeywordPositiveMinRating, want: constant.KeywordSentimentPositive},
+		{rating: 3.99, want: ""},
+		{rating: 3, want: ""},
+		{rating: 2.01, want: ""},
+		{rating: constant.KeywordNegativeMaxRating, want: constant.KeywordSentimentNegative},
+		{rating: 1, want: constant.KeywordSentimentNegative},
+	}
+	for _, tt := range tests {
+		if got := keywordSentiment(tt.rating); got != tt.want {
+			t.Errorf("keywordSentiment(%v) = %q, want %q", tt.rating, got, tt.want)
+		}
+	}
+}
+
+// feedbacks labels each feedback with the label and rating, under its own rating ID
+func feedbacks(label string, rating float64, texts ...string) []model.LabeledFeedback {
+	var result []model.LabeledFeedback
+	for i, text := range texts {
+		result = append(result, model.LabeledFeedback{
+			RatingID:    fmt.Sprintf("%s-%v-%d", label, rating, i),
+			Feedback:    text,
+			Label:       label,
+			RatingLabel: rating,
+		})
+	}
+	return result
+}
+
+func TestExtractKeywords(t *testing.T) {
+	tests := []struct {
+		name      string
+		feedbacks []model.LabeledFeedback
+		want      []model.RestaurantKeyword
+	}{
+		{
+			name:      "phrases need several reviews",
+			feedbacks: feedbacks(constant.LabelFood, 5, "nước dùng đậm đà", "phở ngon"),
+		},
+		{
+			name: "shorter phrases in the same reviews give way to the longer one",
+			feedbacks: feedbacks(constant.LabelFood, 5,
+				"nước dùng đậm đà", "Nước dùng đậm đà!", "nước dùng thơm"),
+			want: []model.RestaurantKeyword{
+				{Label: constant.LabelFood, Sentiment: constant.KeywordSentimentPositive, Phrase: "nước dùng", ReviewCount: 3},
+				{Label: constant.LabelFood, Sentiment: constant.KeywordSentimentPositive, Phrase: "nước dùng đậm đà", ReviewCount: 2},
+			},
+		},
+		{
+			name: "split by label and sentiment",
+			feedbacks: append(append(append(
+				feedbacks(constant.LabelFood, 4, "phục vụ chậm", "phục vụ chậm"),
+				feedbacks(constant.LabelService, 1, "phục vụ chậm", "phục vụ chậm")...),
+				feedbacks(constant.LabelService, 2, "phục vụ chậm")...),
+				feedbacks(constant.LabelService, 5, "phục vụ chậm", "phục vụ chậm")...),
+			want: []model.RestaurantKeyword{
+				{Label: constant.LabelFood, Sentiment: constant.KeywordSentimentPositive, Phrase: "phục vụ chậm", ReviewCount: 2},
+				{Label: constant.LabelService, Sentiment: constant.KeywordSentimentNegative, Phrase: "phục vụ chậm", ReviewCount: 3},
+				{Label: constant.LabelService, Sentiment: constant.KeywordSentimentPositive, Phrase: "phục vụ chậm", ReviewCount: 2},
+			},
+		},
+		{
+			name: "neutral ratings and unknown labels are left out",
+			feedbacks: append(
+				feedbacks(constant.LabelFood, 3, "phở ngon", "phở ngon"),
+				feedbacks(constant.LabelUnknown, 5, "phở ngon", "phở ngon")...),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := extractKeywords(tt.feedbacks)
+			// Groups come out in any order
+			sort.SliceStable(got, func(i, j int) bool {
+				if got[i].Label != got[j].Label {
+					return got[i].Label < got[j].Label
+				}
+				return got[i].Sentiment < got[j].Sentiment
+			})
+			if len(got) == 0 && len(tt.want) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("extractKeywords() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractKeywordsKeepsTheMostUsedPerGroup(t *testing.T) {
+	var texts []string
+	for i := 0; i < constant.KeywordsPerGroup+2; i++ {
+		// Each phrase in KeywordMinReviews reviews, the first ones in one more
+		for j := 0; j < constant.KeywordMinReviews; j++ {
+			texts = append(texts, fmt.Sprintf("món%d ngon", i))
+		}
+		if i < 2 {
+			texts = append(texts, fmt.Sprintf("món%d ngon", i))
+		}
+	}
+
+	got := extractKeywords(feedbacks(constant.LabelFood, 5, texts...))
+	if len(got) != constant.KeywordsPerGroup {
+		t.Fatalf("extractKeywords() kept %d phrases, want %d", len(got), constant.KeywordsPerGroup)
+	}
+	for i, keyword := range got[:2] {
+		if want := fmt.Sprintf("món%d ngon", i); keyword.Phrase != want || keyword.ReviewCount != constant.KeywordMinReviews+1 {
+			t.Errorf("keyword %d = %q in %d reviews, want %q first", i, keyword.Phrase, keyword.ReviewCount, want)
+		}
+	}
+}
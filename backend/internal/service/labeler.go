@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+
+	"skeleton-internship-backend/config"
+	"skeleton-internship-backend/internal/constant"
+	"skeleton-internship-backend/internal/model"
+)
+
+// Labeler rates the aspects (food, service, price, ...) a review talks about.
+// LabelReviews returns the labels of each review keyed by rating ID; reviews
+// missing from the result are labeled unknown with their own rating.
+type Labeler interface {
+	LabelReviews(ctx context.Context, reviews []model.UnlabeledReview) (map[string][]model.ReviewLabel, error)
+}
+
+// NewLabeler builds the labeler selected by the configuration, validated at startup
+func NewLabeler(cfg *config.Config) Labeler {
+	switch cfg.Labeler.Provider {
+	case "http":
+		return NewHTTPLabeler(cfg.Labeler.URL, cfg.Labeler.Timeout)
+	case "stub":
+		return NewStubLabeler(nil)
+	default:
+		return NewLexiconLabeler()
+	}
+}
+
+// unknownLabels is the fallback for reviews no aspect could be found in, so they
+// still count toward ratings through the unknown label
+func unknownLabels(review model.UnlabeledReview) []model.ReviewLabel {
+	return []model.ReviewLabel{{Label: constant.LabelUnknown, RatingLabel: review.Rating}}
+}
+
+// validLabels drops labels that are not aspect labels or whose rating is out of range
+func validLabels(labels []model.ReviewLabel) []model.ReviewLabel {
+	known := map[string]bool{constant.LabelUnknown: true}
+	for _, label := range constant.AspectLabels {
+		known[label] = true
+	}
+
+	var valid []model.ReviewLabel
+	seen := map[string]bool{}
+	for _, label := range labels {
+		if !known[label.Label] || seen[label.Label] ||
+			label.RatingLabel < constant.ReviewMinRating || label.RatingLabel > constant.ReviewMaxRating {
+			continue
+		}
+		seen[label.Label] = true
+		valid = append(valid, label)
+	}
+	return valid
+}
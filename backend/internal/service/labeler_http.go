@@ -0,0 +1,73 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"skeleton-internship-backend/internal/model"
+)
+
+// httpLabeler sends reviews to an external labeling model.
+//
+// Request:  {"reviews": [{"rating_id": "...", "rating": 4, "feedback": "..."}]}
+// Response: {"results": [{"rating_id": "...", "labels": [{"label": "food", "rating_label": 5}]}]}
+type httpLabeler struct {
+	url    string
+	client *http.Client
+}
+
+type httpLabelRequest struct {
+	Reviews []model.UnlabeledReview `json:"reviews"`
+}
+
+type httpLabelResponse struct {
+	Results []struct {
+		RatingID string              `json:"rating_id"`
+		Labels   []model.ReviewLabel `json:"labels"`
+	} `json:"results"`
+}
+
+func NewHTTPLabeler(url string, timeout time.Duration) Labeler {
+	return &httpLabeler{url: url, client: &http.Client{Timeout: timeout}}
+}
+
+func (l *httpLabeler) LabelReviews(ctx context.Context, reviews []model.UnlabeledReview) (map[string][]model.ReviewLabel, error) {
+	body, err := json.Marshal(httpLabelRequest{Reviews: reviews})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, l.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("labeler returned status %d", resp.StatusCode)
+	}
+
+	var decoded httpLabelResponse
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		return nil, err
+	}
+
+	// The model's answer is not trusted, unknown labels and out of range ratings are dropped
+	result := map[string][]model.ReviewLabel{}
+	for _, item := range decoded.Results {
+		if labels := validLabels(item.Labels); len(labels) > 0 {
+			result[item.RatingID] = labels
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+
+	"skeleton-internship-backend/internal/constant"
+	"skeleton-internship-backend/internal/model"
+)
+
+// aspectTerms are the Vietnamese words and phrases that tie a clause to an aspect
+var aspectTerms = map[string][]string{
+	constant.LabelFood: {
+		"món", "đồ ăn", "thức ăn", "ngon", "dở", "vị", "nước dùng", "nước lèo", "thịt", "cơm", "phở", "bún",
+		"bánh", "mì", "trà sữa", "đồ uống", "nước uống", "topping", "sốt", "mặn", "nhạt", "ngọt", "cay",
+		"tươi", "khẩu phần", "phần ăn", "đậm đà", "béo", "tanh", "vừa miệng",
+	},
+	constant.LabelService: {
+		"nhân viên", "phục vụ", "chủ quán", "thái độ", "nhiệt tình", "thân thiện", "niềm nở", "chờ", "đợi",
+		"order", "gọi món", "tư vấn", "lễ phép", "cau có", "chu đáo",
+	},
+	constant.LabelPrice: {
+		"giá", "giá cả", "rẻ", "đắt", "mắc", "tiền", "hợp lý", "đáng tiền", "chát", "khuyến mãi", "ưu đãi",
+	},
+	constant.LabelAmbience: {
+		"không gian", "không khí", "chỗ ngồi", "view", "sạch", "sạch sẽ", "bẩn", "dơ", "ồn", "ồn ào", "yên tĩnh", "thoáng",
+		"mát", "trang trí", "decor", "bàn ghế", "nhà vệ sinh", "chật",
+	},
+	constant.LabelDelivery: {
+		"giao hàng", "giao", "ship", "shipper", "đóng gói", "nguội", "vận chuyển", "tài xế", "móp",
+	},
+}
+
+// sentimentTerms score the words and phrases that praise (+1) or criticize (-1)
+var sentimentTerms = map[string]int{
+	"ngon": 1, "tốt": 1, "rẻ": 1, "sạch": 1, "sạch sẽ": 1, "nhanh": 1, "nhiệt tình": 1, "thân thiện": 1,
+	"niềm nở": 1, "đẹp": 1, "tươi": 1, "hợp lý": 1, "đậm đà": 1, "thoáng": 1, "mát": 1, "yên tĩnh": 1,
+	"ổn": 1, "tuyệt": 1, "tuyệt vời": 1, "xuất sắc": 1, "chất lượng": 1, "đáng tiền": 1, "vừa miệng": 1,
+	"chu đáo": 1, "cẩn thận": 1, "lễ phép": 1, "ưng": 1, "thích": 1, "hài lòng": 1, "xịn": 1,
+	"dở": -1, "tệ": -1, "chán": -1, "đắt": -1, "mắc": -1, "chát": -1, "bẩn": -1, "dơ": -1, "chậm": -1,
+	"lâu": -1, "nguội": -1, "nhạt": -1, "ồn": -1, "ồn ào": -1, "thất vọng": -1, "kém": -1, "khó chịu": -1,
+	"cau có": -1, "lạnh nhạt": -1, "hôi": -1, "tanh": -1, "móp": -1, "thiếu": -1, "sai": -1, "hỏng": -1,
+	"ôi": -1, "khô": -1, "bực": -1, "chật": -1,
+}
+
+// negations flip the sentiment of the next words, "không ngon" is negative
+var negations = map[string]bool{"không": true, "chưa": true, "chẳng": true, "chả": true, "ko": true, "k": true}
+
+// negationCompounds start with a negation but are nouns, "không gian" is the space of the restaurant
+var negationCompounds = map[string]bool{"không gian": true, "không khí": true}
+
+// contrasts start a new clause, "ngon nhưng đắt" rates food and price separately
+var contrasts = map[string]bool{"nhưng": true, "mà": true, "tuy": true, "song": true, "còn": true}
+
+const (
+	// Longest phrase in the lexicons, in words
+	lexiconMaxTermWords = 3
+	// Negations apply to sentiment words at most this many words after them
+	lexiconNegationReach = 2
+)
+
+// lexiconLabeler labels reviews with keyword lexicons: each clause is tied to the aspects
+// it mentions and rated from its sentiment words, falling back to the review rating
+// when the clause is neutral
+type lexiconLabeler struct{}
+
+func NewLexiconLabeler() Labeler {
+	return &lexiconLabeler{}
+}
+
+func (l *lexiconLabeler) LabelReviews(ctx context.Context, reviews []model.UnlabeledReview) (map[string][]model.ReviewLabel, error) {
+	result := map[string][]model.ReviewLabel{}
+	for _, review := range reviews {
+		if labels := lexiconLabels(review); len(labels) > 0 {
+			result[review.RatingID] = labels
+		}
+	}
+	return result, nil
+}
+
+// lexiconLabels rates each aspect mentioned in a review with the mean rating of its clauses
+func lexiconLabels(review model.UnlabeledReview) []model.ReviewLabel {
+	sums := map[string]float64{}
+	counts := map[string]int{}
+	for _, clause := range lexiconClauses(review.Feedback) {
+		rating := clauseRating(clauseSentiment(clause), review.Rating)
+		text := " " + strings.Join(clause, " ") + " "
+		for aspect, terms := range aspectTerms {
+			for _, term := range terms {
+				if strings.Contains(text, " "+term+" ") {
+					sums[aspect] += rating
+					counts[aspect]++
+					break
+				}
+			}
+		}
+	}
+
+	labels := make([]model.ReviewLabel, 0, len(sums))
+	for aspect, sum := range sums {
+		labels = append(labels, model.ReviewLabel{
+			Label:       aspect,
+			RatingLabel: math.Round(sum/float64(counts[aspect])*100) / 100,
+		})
+	}
+	sort.Slice(labels, func(i, j int) bool { return labels[i].Label < labels[j].Label })
+	return labels
+}
+
+// lexiconClauses splits a feedback on punctuation and contrast words
+func lexiconClauses(feedback string) [][]string {
+	var clauses [][]string
+	for _, words := range keywordClauses(feedback) {
+		start := 0
+		for i, word := range words {
+			if contrasts[word] {
+				if i > start {
+					clauses = append(clauses, words[start:i])
+				}
+				start = i + 1
+			}
+		}
+		if start < len(words) {
+			clauses = append(clauses, words[start:])
+		}
+	}
+	return clauses
+}
+
+// clauseSentiment sums the sentiment terms of a clause, longest phrases first
+func clauseSentiment(words []string) int {
+	score := 0
+	negatedUntil := -1
+	for i := 0; i < len(words); {
+		if negations[words[i]] && (i+1 == len(words) || !negationCompounds[words[i]+" "+words[i+1]]) {
+			negatedUntil = i + lexiconNegationReach
+			i++
+			continue
+		}
+
+		matched := 1
+		for size := lexiconMaxTermWords; size >= 1; size-- {
+			if i+size > len(words) {
+				continue
+			}
+			if value, ok := sentimentTerms[strings.Join(words[i:i+size], " ")]; ok {
+				if i <= negatedUntil {
+					value = -value
+				}
+				score += value
+				matched = size
+				break
+			}
+		}
+		i += matched
+	}
+	return score
+}
+
+// clauseRating turns a sentiment score into a star rating, one praise gives 4 stars and
+// one criticism 2, neutral clauses take the rating of the review
+func clauseRating(sentiment int, reviewRating float64) float64 {
+	switch {
+	case sentiment > 0:
+		return math.Min(constant.ReviewMaxRating, 3.5+0.5*float64(sentiment))
+	case sentiment < 0:
+		return math.Max(constant.ReviewMinRating, 2.5+0.5*float64(sentiment))
+	}
+	return reviewRating
+}
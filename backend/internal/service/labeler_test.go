@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"skeleton-internship-backend/internal/model"
+)
+
+func TestLabelUnlabeledReviewsWithStub(t *testing.T) {
+	repo := newFakeRepository()
+	repo.unlabeled = []model.UnlabeledReview{
+		{RatingID: "1", Rating: 4, Feedback: "ngon"},
+		{RatingID: "2", Rating: 2, Feedback: "chán"},
+		{RatingID: "3", Rating: 5, Feedback: "tuyệt"},
+	}
+	labeler := NewStubLabeler(map[string][]model.ReviewLabel{
+		"1": {
+			{Label: "food", RatingLabel: 4},
+			{Label: "taste", RatingLabel: 5},
+			{Label: "service", RatingLabel: 9},
+			{Label: "food", RatingLabel: 1},
+		},
+		"3": {{Label: "price", RatingLabel: 3}},
+	})
+
+	s := newTestService(repo, labeler)
+	if err := s.LabelUnlabeledReviews(context.Background()); err != nil {
+		t.Fatalf("LabelUnlabeledReviews() error = %v", err)
+	}
+
+	want := map[string][]model.ReviewLabel{
+		// Unknown labels, out of range ratings and repeated labels are dropped
+		"1": {{Label: "food", RatingLabel: 4}},
+		// Reviews the labeler has nothing for fall back to unknown with their rating
+		"2": {{Label: "unknown", RatingLabel: 2}},
+		"3": {{Label: "price", RatingLabel: 3}},
+	}
+	if !reflect.DeepEqual(repo.saved, want) {
+		t.Errorf("saved labels = %v, want %v", repo.saved, want)
+	}
+	// A full batch of 2 then a short batch of 1
+	if repo.batches != 2 {
+		t.Errorf("batches = %d, want 2", repo.batches)
+	}
+}
+
+func TestLabelUnlabeledReviewsNothingToLabel(t *testing.T) {
+	repo := newFakeRepository()
+	s := newTestService(repo, NewStubLabeler(nil))
+	if err := s.LabelUnlabeledReviews(context.Background()); err != nil {
+		t.Fatalf("LabelUnlabeledReviews() error = %v", err)
+	}
+	if len(repo.saved) != 0 || repo.batches != 1 {
+		t.Errorf("saved %d reviews in %d batches, want none in 1", len(repo.saved), repo.batches)
+	}
+}
+
+func TestLabelUnlabeledReviewsCancelled(t *testing.T) {
+	repo := newFakeRepository()
+	repo.unlabeled = []model.UnlabeledReview{{RatingID: "1", Rating: 4}}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	s := newTestService(repo, NewStubLabeler(nil))
+	if err := s.LabelUnlabeledReviews(ctx); err != context.Canceled {
+		t.Fatalf("LabelUnlabeledReviews() error = %v, want %v", err, context.Canceled)
+	}
+	if len(repo.saved) != 0 {
+		t.Errorf("saved %d reviews, want none", len(repo.saved))
+	}
+}
+
+func TestLexiconLabeler(t *testing.T) {
+	tests := []struct {
+		name     string
+		feedback string
+		rating   float64
+		want     []model.ReviewLabel
+	}{
+		{
+			name:     "contrast splits aspects",
+			feedback: "Món ăn ngon nhưng giá hơi đắt",
+			rating:   3,
+			want:     []model.ReviewLabel{{Label: "food", RatingLabel: 4}, {Label: "price", RatingLabel: 2}},
+		},
+		{
+			name:     "negation flips sentiment",
+			feedback: "Đồ ăn không ngon",
+			rating:   4,
+			want:     []model.ReviewLabel{{Label: "food", RatingLabel: 2}},
+		},
+		{
+			name:     "negation compound is not a negation",
+			feedback: "Không gian đẹp",
+			rating:   2,
+			want:     []model.ReviewLabel{{Label: "ambience", RatingLabel: 4}},
+		},
+		{
+			name:     "neutral clause takes the review rating",
+			feedback: "Giao hàng",
+			rating:   5,
+			want:     []model.ReviewLabel{{Label: "delivery", RatingLabel: 5}},
+		},
+		{
+			name:     "clauses of one aspect are averaged",
+			feedback: "Nhân viên nhiệt tình. Phục vụ chậm, chờ lâu",
+			rating:   3,
+			want:     []model.ReviewLabel{{Label: "service", RatingLabel: 2.67}},
+		},
+		{
+			name:     "no aspect",
+			feedback: "Sẽ quay lại",
+			rating:   5,
+			want:     nil,
+		},
+	}
+
+	labeler := NewLexiconLabeler()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			review := model.UnlabeledReview{RatingID: "1", Rating: tt.rating, Feedback: tt.feedback}
+			labels, err := labeler.LabelReviews(context.Background(), []model.UnlabeledReview{review})
+			if err != nil {
+				t.Fatalf("LabelReviews() error = %v", err)
+			}
+			if got := labels["1"]; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LabelReviews() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// The stub only answers for the reviews it was given labels for
+func TestStubLabeler(t *testing.T) {
+	labeler := NewStubLabeler(map[string][]model.ReviewLabel{"1": {{Label: "food", RatingLabel: 5}}})
+	reviews := []model.UnlabeledReview{{RatingID: "1"}, {RatingID: "2"}}
+	labels, err := labeler.LabelReviews(context.Background(), reviews)
+	if err != nil {
+		t.Fatalf("LabelReviews() error = %v", err)
+	}
+	want := map[string][]model.ReviewLabel{"1": {{Label: "food", RatingLabel: 5}}}
+	if !reflect.DeepEqual(labels, want) {
+		t.Errorf("LabelReviews() = %v, want %v", labels, want)
+	}
+}
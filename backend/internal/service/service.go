@@ -1,6 +1,11 @@
 package service
 
 import (
+	"context"
+	"sync"
+
+	"skeleton-internship-backend/config"
+	"skeleton-internship-backend/internal/dto"
 	"skeleton-internship-backend/internal/model"
 	"skeleton-internship-backend/internal/repository"
 
@@ -10,22 +15,53 @@ import (
 type Service interface {
 	GetRestaurantByID(id string, lat float64, lng float64) (*model.Restaurant, error)
 	GetAllFoodTypes() ([]string, error)
-	GetDishesByRestaurantID(id string) ([]model.Dish, error)
-	GetRestaurantDetail(id string, lat float64, lng float64) (*model.RestaurantDetail, error)
+	GetRestaurantMenu(id string, searchWords []string, sortBy string) (*model.Menu, error)
+	GetRestaurantDetail(id string, lat float64, lng float64, byPlatform bool) (*model.RestaurantDetail, error)
+	GetRestaurantLabels(id string, byPlatform bool) (*model.RestaurantLabels, error)
 	GetRestaurantsByFilter(lat, lng float64, foodType string, cityID string, districtIDs []string, page int, limit int, isCount bool) ([]model.Restaurant, int, error)
 	GetNearbyRestaurants(lat, lng float64, limit int) ([]model.Restaurant, error)
-	GetRestaurantReviewsByLabel(id string, label string, page int, isCount bool, textOnly bool) (*model.ReviewResponse, error)
+	GetRestaurantReviews(id string, filter model.ReviewFilter, page int, isCount bool) (*model.ReviewResponse, error)
+	CreateReview(restaurantID string, review dto.ReviewCreate) (*model.Review, error)
+	VoteReview(ratingID string, clientID string, helpful *bool) (*model.ReviewVotes, error)
+	ReportReview(ratingID string, clientID string, report dto.ReviewReport) error
+	GetModerationQueue(status string, page int) (*model.ModerationQueue, error)
+	ApproveReview(ratingID string) error
+	HideReview(ratingID string) error
+	GetRatingDistribution(id string) (*model.RatingDistribution, error)
+	GetRatingTrend(id string, granularity string) (*model.RatingTrend, error)
+	GetRatingHistory(id string, page int) (*model.RatingHistory, error)
+	GetRatingMovers(fromVersion int, toVersion int, limit int) (*model.RatingMovers, error)
+	GetRanking(cityID int, districtID int, foodType string, label string, limit int) (*model.Ranking, error)
+	GetRestaurantKeywords(id string) (*model.RestaurantKeywords, error)
+	GetUserProfile(id string) (*model.UserProfile, error)
+	GetUserReviews(id string, page int, isCount bool) (*model.UserReviewResponse, error)
 	GetRestaurantsByAutocomplete(searchWords []string, limit int) ([]model.Restaurant, error)
-	RecalculateRestaurantsRating() error
-	ExportRestaurantsToCSV() error
+	RecalculateRestaurantsRating(ctx context.Context) error
+	RecalculateRestaurant(id string) (*model.Restaurant, error)
+	RecalculateDirtyRestaurants(ctx context.Context) error
+	DryRunRecalculation(ctx context.Context) error
+	GetDryRunSummary() (*model.DryRunSummary, error)
+	GetDryRunDiff() ([]byte, error)
+	ApplyDryRun(ctx context.Context) error
+	ExportRestaurantsToCSV(ctx context.Context) error
+	DetectDuplicateReviews(ctx context.Context) error
+	GetDuplicateClusters(restaurantID string, page int) (*model.DuplicateReport, error)
+	DetectSuspiciousReviews(ctx context.Context) error
+	LabelUnlabeledReviews(ctx context.Context) error
+	GetSuspiciousWindows(restaurantID string, page int) (*model.SuspiciousReport, error)
 }
 
 type service struct {
-	repo repository.Repository
+	repo    repository.Repository
+	labeler Labeler
+	cfg     *config.Config
+
+	// Serializes full recalculations, which several jobs end with
+	recalculating sync.Mutex
 }
 
-func NewService(repo repository.Repository) Service {
-	return &service{repo: repo}
+func NewService(repo repository.Repository, labeler Labeler, cfg *config.Config) Service {
+	return &service{repo: repo, labeler: labeler, cfg: cfg}
 }
 
 func (s *service) GetRestaurantByID(id string, lat float64, lng float64) (*model.Restaurant, error) {
@@ -37,7 +73,7 @@ func (s *service) GetRestaurantByID(id string, lat float64, lng float64) (*model
 	return restaurant, nil
 }
 
-func (s *service) GetRestaurantDetail(id string, lat float64, lng float64) (*model.RestaurantDetail, error) {
+func (s *service) GetRestaurantDetail(id string, lat float64, lng float64, byPlatform bool) (*model.RestaurantDetail, error) {
 	restaurant, err := s.GetRestaurantByID(id, lat, lng)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to get restaurant by ID (service)")
@@ -50,17 +86,41 @@ func (s *service) GetRestaurantDetail(id string, lat float64, lng float64) (*mod
 		return nil, err
 	}
 
-	platforms, ratings, err := s.repo.FindPlatformsAndRatingsByRestaurantID(id)
+	platformRatings, err := s.repo.FindPlatformRatingsByRestaurantID(id)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get platform ratings by restaurant ID (service)")
+		return nil, err
+	}
+
+	// Platforms and RatingPlatforms keep the raw ratings for older clients
+	var platforms []string
+	var ratings []float64
+	for _, rating := range platformRatings {
+		platforms = append(platforms, rating.Platform)
+		ratings = append(ratings, rating.Rating)
+	}
+
+	aspectWeights, err := s.repo.FindAspectWeights(id)
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to get platforms and ratings by restaurant ID (service)")
+		log.Error().Err(err).Msg("Failed to get aspect weights by restaurant ID (service)")
 		return nil, err
 	}
 
 	restaurantDetail := &model.RestaurantDetail{
-		Restaurant:      *restaurant,
-		Labels:          *labelsRating,
-		Platforms:       platforms,
-		RatingPlatforms: ratings,
+		Restaurant:           *restaurant,
+		Labels:               *labelsRating,
+		Platforms:            platforms,
+		RatingPlatforms:      ratings,
+		PlatformRatings:      platformRatings,
+		PlatformDisagreement: platformDisagreement(restaurant, platformRatings),
+		AspectWeights:        aspectWeights,
+	}
+
+	if byPlatform {
+		restaurantDetail.LabelsByPlatform, err = s.GetLabelsRatingByPlatform(id)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	return restaurantDetail, nil
@@ -89,8 +149,8 @@ func (s *service) GetRestaurantsByFilter(lat, lng float64, foodType string, city
 	return restaurants, totalCount, nil
 }
 
-func (s *service) GetRestaurantReviewsByLabel(id string, label string, page int, isCount bool, textOnly bool) (*model.ReviewResponse, error) {
-	log.Info().Msgf("Fetching reviews for restaurant ID: %s with label: %s on page: %d, isCount: %v, textOnly: %v", id, label, page, isCount, textOnly)
+func (s *service) GetRestaurantReviews(id string, filter model.ReviewFilter, page int, isCount bool) (*model.ReviewResponse, error) {
+	log.Info().Msgf("Fetching reviews for restaurant ID: %s with filter: %+v on page: %d, isCount: %v", id, filter, page, isCount)
 
 	// Check if restaurant exists
 	_, err := s.GetRestaurantByID(id, 0, 0)
@@ -98,9 +158,9 @@ func (s *service) GetRestaurantReviewsByLabel(id string, label string, page int,
 		return nil, err
 	}
 	// Get reviews from repository
-	reviews, totalReviews, err := s.repo.FindReviewsByRestaurantIDAndLabel(id, label, page, isCount, textOnly)
+	reviews, totalReviews, err := s.repo.FindReviewsByRestaurantID(id, filter, page, isCount)
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to fetch reviews by restaurant ID and label (service)")
+		log.Error().Err(err).Msg("Failed to fetch reviews by restaurant ID (service)")
 		return nil, err
 	}
 
@@ -6,11 +6,47 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
-func (s *service) GetDishesByRestaurantID(id string) ([]model.Dish, error) {
-	dishes, err := s.repo.FindDishesByRestaurantID(id)
+// GetRestaurantMenu returns the menu of a restaurant grouped by category.
+// Dishes arrive from the repository already ordered by category, so grouping
+// only needs to watch for the category to change.
+func (s *service) GetRestaurantMenu(id string, searchWords []string, sortBy string) (*model.Menu, error) {
+	// Check if restaurant exists
+	_, err := s.GetRestaurantByID(id, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	dishes, err := s.repo.FindDishesByRestaurantID(id, searchWords, sortBy)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to get dishes by restaurant ID (service)")
 		return nil, err
 	}
-	return dishes, nil
-}
\ No newline at end of file
+
+	menu := &model.Menu{
+		Categories:  []model.MenuCategory{},
+		TotalDishes: len(dishes),
+	}
+	for _, dish := range dishes {
+		last := len(menu.Categories) - 1
+		if last < 0 || menu.Categories[last].CategoryID != dish.CategoryID {
+			menu.Categories = append(menu.Categories, model.MenuCategory{
+				CategoryID:   dish.CategoryID,
+				CategoryName: dish.CategoryName,
+				MinPrice:     dish.Price,
+				MaxPrice:     dish.Price,
+			})
+			last++
+		}
+
+		category := &menu.Categories[last]
+		if dish.Price < category.MinPrice {
+			category.MinPrice = dish.Price
+		}
+		if dish.Price > category.MaxPrice {
+			category.MaxPrice = dish.Price
+		}
+		category.Dishes = append(category.Dishes, dish)
+	}
+
+	return menu, nil
+}
@@ -0,0 +1,218 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"skeleton-internship-backend/internal/constant"
+	"skeleton-internship-backend/internal/job"
+	"skeleton-internship-backend/internal/model"
+
+	"github.com/rs/zerolog/log"
+)
+
+// DryRunRecalculation computes new review counts and ratings into the staging table,
+// replacing the previous dry run, without changing what readers see
+func (s *service) DryRunRecalculation(ctx context.Context) error {
+	s.recalculating.Lock()
+	defer s.recalculating.Unlock()
+
+	log.Info().Msg("Staging a dry-run recalculation (service)")
+	job.ReportProgress(ctx, constant.JobKindDryRun, 0, 1)
+	if err := s.repo.StageRecalculation(); err != nil {
+		log.Error().Err(err).Msg("Failed to stage recalculation (service)")
+		return err
+	}
+	job.ReportProgress(ctx, constant.JobKindDryRun, 1, 1)
+	return nil
+}
+
+// GetDryRunSummary compares the staged values with the current ones
+func (s *service) GetDryRunSummary() (*model.DryRunSummary, error) {
+	log.Info().Msg("Fetching dry-run summary (service)")
+	staged, stagedAt, err := s.repo.FindStagedRatings()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to find staged ratings (service)")
+		return nil, err
+	}
+	rankStagedRatings(staged)
+
+	summary := &model.DryRunSummary{
+		StagedAt:        stagedAt.Format(time.RFC3339),
+		RestaurantCount: len(staged),
+		LargestMovers:   []model.StagedRating{},
+		Districts:       []model.DistrictRankChanges{},
+	}
+
+	districts := map[string]*model.DistrictRankChanges{}
+	for _, rating := range staged {
+		if rating.Rating != rating.OldRating || rating.ReviewCount != rating.OldReviewCount {
+			summary.ChangedCount++
+		}
+		if rating.Rating != rating.OldRating {
+			summary.LargestMovers = append(summary.LargestMovers, rating)
+		}
+		if rating.DistrictID != "" && rating.DistrictRank != rating.OldDistrictRank {
+			district, ok := districts[rating.DistrictID]
+			if !ok {
+				district = &model.DistrictRankChanges{DistrictID: rating.DistrictID}
+				districts[rating.DistrictID] = district
+			}
+			district.ChangedCount++
+			district.Restaurants = append(district.Restaurants, rating)
+		}
+	}
+
+	sort.SliceStable(summary.LargestMovers, func(i, j int) bool {
+		return math.Abs(summary.LargestMovers[i].Rating-summary.LargestMovers[i].OldRating) >
+			math.Abs(summary.LargestMovers[j].Rating-summary.LargestMovers[j].OldRating)
+	})
+	if len(summary.LargestMovers) > constant.DryRunLargestMovers {
+		summary.LargestMovers = summary.LargestMovers[:constant.DryRunLargestMovers]
+	}
+
+	for _, district := range districts {
+		sort.SliceStable(district.Restaurants, func(i, j int) bool {
+			return rankShift(district.Restaurants[i]) > rankShift(district.Restaurants[j])
+		})
+		if len(district.Restaurants) > constant.DryRunDistrictMovers {
+			district.Restaurants = district.Restaurants[:constant.DryRunDistrictMovers]
+		}
+		summary.Districts = append(summary.Districts, *district)
+	}
+	// Most shaken districts first
+	sort.Slice(summary.Districts, func(i, j int) bool {
+		if summary.Districts[i].ChangedCount != summary.Districts[j].ChangedCount {
+			return summary.Districts[i].ChangedCount > summary.Districts[j].ChangedCount
+		}
+		return summary.Districts[i].DistrictID < summary.Districts[j].DistrictID
+	})
+
+	return summary, nil
+}
+
+// GetDryRunDiff returns the staged restaurants whose rating, review count or district
+// rank would change, as CSV
+func (s *service) GetDryRunDiff() ([]byte, error) {
+	log.Info().Msg("Building dry-run diff (service)")
+	staged, _, err := s.repo.FindStagedRatings()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to find staged ratings (service)")
+		return nil, err
+	}
+	rankStagedRatings(staged)
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	writer.Write([]string{"restaurant_id", "restaurant_name", "district_id", "old_review_count", "review_count",
+		"old_rating", "rating", "rating_change", "old_district_rank", "district_rank"})
+	for _, rating := range staged {
+		if rating.Rating == rating.OldRating && rating.ReviewCount == rating.OldReviewCount && rating.DistrictRank == rating.OldDistrictRank {
+			continue
+		}
+		writer.Write([]string{
+			rating.RestaurantID,
+			rating.RestaurantName,
+			rating.DistrictID,
+			strconv.Itoa(rating.OldReviewCount),
+			strconv.Itoa(rating.ReviewCount),
+			strconv.FormatFloat(rating.OldRating, 'f', 2, 64),
+			strconv.FormatFloat(rating.Rating, 'f', 2, 64),
+			strconv.FormatFloat(rating.Rating-rating.OldRating, 'f', 2, 64),
+			strconv.Itoa(rating.OldDistrictRank),
+			strconv.Itoa(rating.DistrictRank),
+		})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		log.Error().Err(err).Msg("Failed to write dry-run diff (service)")
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// ApplyDryRun swaps the staged values in, then refreshes what derives from the ratings
+// like a full recalculation does. The applied and skipped restaurants are reported as the
+// job result as soon as the swap is committed. It is serialized with recalculations so a
+// recalculation cannot run between the check for stale restaurants and the swap.
+func (s *service) ApplyDryRun(ctx context.Context) error {
+	s.recalculating.Lock()
+	defer s.recalculating.Unlock()
+
+	log.Info().Msg("Applying dry-run recalculation (service)")
+	job.ReportProgress(ctx, constant.JobKindRecalculate, 0, recalculationSteps)
+	result, err := s.repo.ApplyStagedRecalculation()
+	if err != nil {
+		if err.Error() == "not found" {
+			return errors.New("no dry run staged")
+		}
+		log.Error().Err(err).Msg("Failed to apply staged recalculation (service)")
+		return err
+	}
+	job.ReportResult(ctx, result)
+	log.Info().Msgf("Applied %d staged restaurants, skipped %d changed since the dry run (service)", result.Applied, result.Skipped)
+
+	job.ReportProgress(ctx, constant.JobKindRecalculate, 1, recalculationSteps)
+	if err := s.finishRecalculation(ctx, result.Version); err != nil {
+		return err
+	}
+	job.ReportProgress(ctx, constant.JobKindRecalculate, recalculationSteps, recalculationSteps)
+	return nil
+}
+
+// rankStagedRatings sets the old and new district ranks of staged restaurants: by rating,
+// then by review count, then by ID so ties rank the same way every time
+func rankStagedRatings(staged []model.StagedRating) {
+	byDistrict := map[string][]*model.StagedRating{}
+	for i := range staged {
+		if staged[i].DistrictID != "" {
+			byDistrict[staged[i].DistrictID] = append(byDistrict[staged[i].DistrictID], &staged[i])
+		}
+	}
+
+	for _, restaurants := range byDistrict {
+		sort.Slice(restaurants, func(i, j int) bool {
+			a, b := restaurants[i], restaurants[j]
+			if a.OldRating != b.OldRating {
+				return a.OldRating > b.OldRating
+			}
+			if a.OldReviewCount != b.OldReviewCount {
+				return a.OldReviewCount > b.OldReviewCount
+			}
+			return a.RestaurantID < b.RestaurantID
+		})
+		for i, restaurant := range restaurants {
+			restaurant.OldDistrictRank = i + 1
+		}
+
+		sort.Slice(restaurants, func(i, j int) bool {
+			a, b := restaurants[i], restaurants[j]
+			if a.Rating != b.Rating {
+				return a.Rating > b.Rating
+			}
+			if a.ReviewCount != b.ReviewCount {
+				return a.ReviewCount > b.ReviewCount
+			}
+			return a.RestaurantID < b.RestaurantID
+		})
+		for i, restaurant := range restaurants {
+			restaurant.DistrictRank = i + 1
+		}
+	}
+}
+
+// rankShift returns how many places a restaurant would move in its district
+func rankShift(rating model.StagedRating) int {
+	shift := rating.DistrictRank - rating.OldDistrictRank
+	if shift < 0 {
+		return -shift
+	}
+	return shift
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"skeleton-internship-backend/internal/constant"
+	"skeleton-internship-backend/internal/job"
+	"skeleton-internship-backend/internal/model"
+
+	"go.uber.org/fx/fxtest"
+)
+
+func TestRankStagedRatings(t *testing.T) {
+	type rank struct{ old, new int }
+
+	tests := []struct {
+		name   string
+		staged []model.StagedRating
+		want   map[string]rank
+	}{
+		{
+			name: "by rating",
+			staged: []model.StagedRating{
+				{RestaurantID: "a", DistrictID: "d1", OldRating: 4, Rating: 3},
+				{RestaurantID: "b", DistrictID: "d1", OldRating: 3, Rating: 4.5},
+				{RestaurantID: "c", DistrictID: "d1", OldRating: 5, Rating: 4},
+			},
+			want: map[string]rank{"a": {2, 3}, "b": {3, 1}, "c": {1, 2}},
+		},
+		{
+			name: "ties broken by review count then ID",
+			staged: []model.StagedRating{
+				{RestaurantID: "b", DistrictID: "d1", OldRating: 4, Rating: 4, OldReviewCount: 10, ReviewCount: 10},
+				{RestaurantID: "a", DistrictID: "d1", OldRating: 4, Rating: 4, OldReviewCount: 10, ReviewCount: 20},
+				{RestaurantID: "c", DistrictID: "d1", OldRating: 4, Rating: 4, OldReviewCount: 30, ReviewCount: 10},
+			},
+			want: map[string]rank{"a": {2, 1}, "b": {3, 2}, "c": {1, 3}},
+		},
+		{
+			name: "districts are ranked apart",
+			staged: []model.StagedRating{
+				{RestaurantID: "a", DistrictID: "d1", OldRating: 3, Rating: 3},
+				{RestaurantID: "b", DistrictID: "d2", OldRating: 2, Rating: 5},
+				{RestaurantID: "c", DistrictID: "d2", OldRating: 4, Rating: 4},
+			},
+			want: map[string]rank{"a": {1, 1}, "b": {2, 1}, "c": {1, 2}},
+		},
+		{
+			name: "restaurants without a district are not ranked",
+			staged: []model.StagedRating{
+				{RestaurantID: "a", OldRating: 5, Rating: 5},
+				{RestaurantID: "b", DistrictID: "d1", OldRating: 3, Rating: 3},
+			},
+			want: map[string]rank{"a": {0, 0}, "b": {1, 1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rankStagedRatings(tt.staged)
+			got := map[string]rank{}
+			for _, rating := range tt.staged {
+				got[rating.RestaurantID] = rank{rating.OldDistrictRank, rating.DistrictRank}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ranks = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// dryRunStaged has one restaurant whose rating changes and swaps ranks with another, one
+// whose review count only changes and one left as it is
+var dryRunStaged = []model.StagedRating{
+	{RestaurantID: "a", RestaurantName: "A", DistrictID: "d1", OldReviewCount: 10, ReviewCount: 10, OldRating: 4, Rating: 3.5},
+	{RestaurantID: "b", RestaurantName: "B", DistrictID: "d1", OldReviewCount: 8, ReviewCount: 8, OldRating: 3.8, Rating: 3.8},
+	{RestaurantID: "c", RestaurantName: "C", DistrictID: "d2", OldReviewCount: 5, ReviewCount: 6, OldRating: 4.2, Rating: 4.2},
+	{RestaurantID: "d", RestaurantName: "D", OldReviewCount: 1, ReviewCount: 1, OldRating: 3, Rating: 3},
+}
+
+func TestGetDryRunDiff(t *testing.T) {
+	repo := newFakeRepository()
+	repo.staged = dryRunStaged
+	s := newTestService(repo, nil)
+
+	diff, err := s.GetDryRunDiff()
+	if err != nil {
+		t.Fatalf("GetDryRunDiff() error = %v", err)
+	}
+
+	want := strings.Join([]string{
+		"restaurant_id,restaurant_name,district_id,old_review_count,review_count,old_rating,rating,rating_change,old_district_rank,district_rank",
+		"a,A,d1,10,10,4.00,3.50,-0.50,1,2",
+		// b keeps its rating but loses its place to a
+		"b,B,d1,8,8,3.80,3.80,0.00,2,1",
+		"c,C,d2,5,6,4.20,4.20,0.00,1,1",
+		"",
+	}, "\n")
+	if string(diff) != want {
+		t.Errorf("GetDryRunDiff() =\n%s\nwant\n%s", diff, want)
+	}
+}
+
+func TestGetDryRunSummary(t *testing.T) {
+	repo := newFakeRepository()
+	repo.staged = dryRunStaged
+	repo.stagedAt = time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	s := newTestService(repo, nil)
+
+	summary, err := s.GetDryRunSummary()
+	if err != nil {
+		t.Fatalf("GetDryRunSummary() error = %v", err)
+	}
+
+	if summary.StagedAt != "2024-05-01T08:00:00Z" || summary.RestaurantCount != 4 || summary.ChangedCount != 2 {
+		t.Errorf("summary = staged at %s, %d restaurants, %d changed, want 2024-05-01T08:00:00Z, 4, 2",
+			summary.StagedAt, summary.RestaurantCount, summary.ChangedCount)
+	}
+	if len(summary.LargestMovers) != 1 || summary.LargestMovers[0].RestaurantID != "a" {
+		t.Errorf("largest movers = %+v, want a", summary.LargestMovers)
+	}
+	if len(summary.Districts) != 1 || summary.Districts[0].DistrictID != "d1" || summary.Districts[0].ChangedCount != 2 {
+		t.Fatalf("districts = %+v, want d1 with 2 changes", summary.Districts)
+	}
+}
+
+func TestApplyDryRunRefreshesDerivedData(t *testing.T) {
+	repo := newFakeRepository()
+	repo.applied = &model.DryRunApplyResult{Applied: 3, Skipped: 1, Version: 7}
+	repo.scales = []model.PlatformScale{
+		{PlatformID: 1, Name: "Native", ScaleMin: 0, ScaleMax: 5, Normalization: constant.PlatformNormalizationLinear},
+		{PlatformID: 2, Name: "Foody", ScaleMin: 0, ScaleMax: 10, Normalization: constant.PlatformNormalizationDistribution},
+	}
+	repo.ownCounts = []model.RatingCount{{Rating: 3, Count: 1}, {Rating: 4, Count: 1}}
+	repo.platformCounts[2] = []model.RatingCount{{Rating: 8, Count: 1}}
+	repo.restaurants = []string{"a"}
+	s := newTestService(repo, nil)
+
+	finished := runJob(t, s.ApplyDryRun)
+	if finished.Status != constant.JobStatusSucceeded {
+		t.Fatalf("job %s: %s", finished.Status, finished.Error)
+	}
+	if !reflect.DeepEqual(finished.Result, repo.applied) {
+		t.Errorf("job result = %+v, want %+v", finished.Result, repo.applied)
+	}
+
+	// Like a full recalculation, with the rankings of the applied version
+	want := []string{"apply", "rankings 7", "calibration 2", "keywords a"}
+	if !reflect.DeepEqual(repo.steps, want) {
+		t.Errorf("steps = %v, want %v", repo.steps, want)
+	}
+}
+
+func TestApplyDryRunCancelled(t *testing.T) {
+	repo := newFakeRepository()
+	repo.applied = &model.DryRunApplyResult{Version: 7}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	s := newTestService(repo, nil)
+	if err := s.ApplyDryRun(ctx); err != context.Canceled {
+		t.Fatalf("ApplyDryRun() error = %v, want %v", err, context.Canceled)
+	}
+	if want := []string{"apply"}; !reflect.DeepEqual(repo.steps, want) {
+		t.Errorf("steps = %v, want %v", repo.steps, want)
+	}
+}
+
+func TestApplyDryRunNothingStaged(t *testing.T) {
+	s := newTestService(newFakeRepository(), nil)
+	if err := s.ApplyDryRun(context.Background()); err == nil || err.Error() != "no dry run staged" {
+		t.Fatalf("ApplyDryRun() error = %v, want no dry run staged", err)
+	}
+}
+
+// runJob runs fn as a background job and returns the job once it is finished
+func runJob(t *testing.T, fn job.Func) model.Job {
+	t.Helper()
+	jobs := job.NewManager(fxtest.NewLifecycle(t))
+	started, _, err := jobs.Start("test", fn)
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	for jobs.Running("test") {
+		time.Sleep(time.Millisecond)
+	}
+	finished, _ := jobs.Get(started.ID)
+	return finished
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"skeleton-internship-backend/internal/constant"
+	"skeleton-internship-backend/internal/job"
+	"skeleton-internship-backend/internal/model"
+
+	"github.com/rs/zerolog/log"
+)
+
+// DetectDuplicateReviews fingerprints the reviews of every restaurant, marks the
+// duplicates and refreshes the ratings of the affected restaurants so the duplicates stop counting
+func (s *service) DetectDuplicateReviews(ctx context.Context) error {
+	log.Info().Msg("Detecting duplicate reviews (service)")
+	restaurantIDs, _, _, err := s.repo.FindAllRestaurants()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to find restaurants to detect duplicates (service)")
+		return err
+	}
+
+	var duplicates []model.ReviewDuplicate
+	for i, id := range restaurantIDs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		job.ReportProgress(ctx, constant.JobKindDetectDuplicates, i, len(restaurantIDs))
+
+		reviews, err := s.repo.FindReviewTextsByRestaurantID(id)
+		if err != nil {
+			log.Error().Err(err).Msgf("Failed to find reviews of restaurant %s to detect duplicates (service)", id)
+			return err
+		}
+		duplicates = append(duplicates, findDuplicates(reviews)...)
+	}
+
+	if err := s.repo.MarkDuplicateReviews(duplicates); err != nil {
+		log.Error().Err(err).Msg("Failed to mark duplicate reviews (service)")
+		return err
+	}
+	log.Info().Msgf("Marked %d duplicate reviews (service)", len(duplicates))
+
+	return s.RecalculateDirtyRestaurants(ctx)
+}
+
+// keptReview is a review that is not a duplicate, which later reviews are compared against
+type keptReview struct {
+	ratingID string
+	shingles map[uint64]struct{}
+}
+
+// findDuplicates compares the reviews of one restaurant, which must be sorted from
+// oldest to newest. Each review is compared only against earlier kept reviews, so
+// every cluster is led by its earliest review.
+func findDuplicates(reviews []model.ReviewText) []model.ReviewDuplicate {
+	var duplicates []model.ReviewDuplicate
+	var kept []keptReview
+	sameDay := map[string]string{}
+	exact := map[string]string{}
+	buckets := map[uint64][]int{}
+
+	for _, review := range reviews {
+		// Reviews without a time have no day to share
+		dayKey := ""
+		if !review.ReviewTime.IsZero() {
+			dayKey = review.UserID + "|" + review.ReviewTime.Format("2006-01-02")
+		}
+		if canonical, ok := sameDay[dayKey]; ok {
+			duplicates = append(duplicates, model.ReviewDuplicate{
+				RatingID:    review.RatingID,
+				DuplicateOf: canonical,
+				Reason:      constant.DuplicateReasonSameUserDay,
+				Similarity:  1,
+			})
+			continue
+		}
+
+		words := normalizeWords(review.Feedback)
+		if len(words) >= constant.DuplicateMinWords {
+			text := strings.Join(words, " ")
+			if canonical, ok := exact[text]; ok {
+				duplicates = append(duplicates, model.ReviewDuplicate{
+					RatingID:    review.RatingID,
+					DuplicateOf: canonical,
+					Reason:      constant.DuplicateReasonExact,
+					Similarity:  1,
+				})
+				continue
+			}
+
+			set := shingles(words, constant.DuplicateShingleSize)
+			bands := lshBands(minHash(set))
+
+			// Verify every candidate sharing a band with the exact similarity
+			best, bestSimilarity := -1, 0.0
+			compared := map[int]bool{}
+			for _, band := range bands {
+				for _, candidate := range buckets[band] {
+					if compared[candidate] {
+						continue
+					}
+					compared[candidate] = true
+					if similarity := jaccard(set, kept[candidate].shingles); similarity > bestSimilarity {
+						best, bestSimilarity = candidate, similarity
+					}
+				}
+			}
+			if best >= 0 && bestSimilarity >= constant.DuplicateSimilarityThreshold {
+				duplicates = append(duplicates, model.ReviewDuplicate{
+					RatingID:    review.RatingID,
+					DuplicateOf: kept[best].ratingID,
+					Reason:      constant.DuplicateReasonNear,
+					Similarity:  bestSimilarity,
+				})
+				continue
+			}
+
+			exact[text] = review.RatingID
+			for _, band := range bands {
+				buckets[band] = append(buckets[band], len(kept))
+			}
+			kept = append(kept, keptReview{ratingID: review.RatingID, shingles: set})
+		}
+
+		if dayKey != "" {
+			sameDay[dayKey] = review.RatingID
+		}
+	}
+
+	return duplicates
+}
+
+func (s *service) GetDuplicateClusters(restaurantID string, page int) (*model.DuplicateReport, error) {
+	log.Info().Msgf("Fetching duplicate clusters for restaurant ID: %q on page: %d", restaurantID, page)
+	report, err := s.repo.FindDuplicateClusters(restaurantID, page)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to fetch duplicate clusters (service)")
+		return nil, err
+	}
+	return report, nil
+}
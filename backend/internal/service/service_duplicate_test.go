@@ -0,0 +1,101 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"skeleton-internship-backend/internal/constant"
+	"skeleton-internship-backend/internal/model"
+)
+
+func TestFindDuplicates(t *testing.T) {
+	day := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	long := "quán phở này nước dùng rất ngon thịt mềm giá hợp lý nhân viên thân thiện"
+
+	tests := []struct {
+		name    string
+		reviews []model.ReviewText
+		want    []model.ReviewDuplicate
+	}{
+		{
+			name: "same user same day",
+			reviews: []model.ReviewText{
+				{RatingID: "1", UserID: "u", Feedback: "ngon", ReviewTime: day},
+				{RatingID: "2", UserID: "u", Feedback: "dở", ReviewTime: day.Add(3 * time.Hour)},
+			},
+			want: []model.ReviewDuplicate{
+				{RatingID: "2", DuplicateOf: "1", Reason: constant.DuplicateReasonSameUserDay, Similarity: 1},
+			},
+		},
+		{
+			name: "same user other day",
+			reviews: []model.ReviewText{
+				{RatingID: "1", UserID: "u", Feedback: "ngon", ReviewTime: day},
+				{RatingID: "2", UserID: "u", Feedback: "dở", ReviewTime: day.AddDate(0, 0, 1)},
+			},
+		},
+		{
+			name: "reviews without a time share no day",
+			reviews: []model.ReviewText{
+				{RatingID: "1", UserID: "u", Feedback: "ngon"},
+				{RatingID: "2", UserID: "u", Feedback: "dở"},
+			},
+		},
+		{
+			name: "exact text from another user",
+			reviews: []model.ReviewText{
+				{RatingID: "1", UserID: "a", Feedback: long, ReviewTime: day},
+				{RatingID: "2", UserID: "b", Feedback: "Quán phở này, nước dùng rất ngon! Thịt mềm, giá hợp lý, nhân viên thân thiện.", ReviewTime: day},
+			},
+			want: []model.ReviewDuplicate{
+				{RatingID: "2", DuplicateOf: "1", Reason: constant.DuplicateReasonExact, Similarity: 1},
+			},
+		},
+		{
+			name: "near duplicate above the threshold",
+			reviews: []model.ReviewText{
+				{RatingID: "1", UserID: "a", Feedback: long, ReviewTime: day},
+				{RatingID: "2", UserID: "b", Feedback: long + " lắm", ReviewTime: day},
+			},
+			want: []model.ReviewDuplicate{
+				// 14 of the 15 shingles are shared
+				{RatingID: "2", DuplicateOf: "1", Reason: constant.DuplicateReasonNear, Similarity: 14.0 / 15},
+			},
+		},
+		{
+			name: "similar text below the threshold",
+			reviews: []model.ReviewText{
+				{RatingID: "1", UserID: "a", Feedback: long, ReviewTime: day},
+				{RatingID: "2", UserID: "b", Feedback: "quán phở này nước dùng rất ngon nhưng phục vụ chậm và bàn ghế khá bẩn", ReviewTime: day},
+			},
+		},
+		{
+			name: "short texts are not compared",
+			reviews: []model.ReviewText{
+				{RatingID: "1", UserID: "a", Feedback: "rất ngon", ReviewTime: day},
+				{RatingID: "2", UserID: "b", Feedback: "rất ngon", ReviewTime: day},
+			},
+		},
+		{
+			name: "duplicates point to the earliest kept review",
+			reviews: []model.ReviewText{
+				{RatingID: "1", UserID: "a", Feedback: long, ReviewTime: day},
+				{RatingID: "2", UserID: "b", Feedback: long, ReviewTime: day},
+				{RatingID: "3", UserID: "c", Feedback: long + " lắm", ReviewTime: day},
+			},
+			want: []model.ReviewDuplicate{
+				{RatingID: "2", DuplicateOf: "1", Reason: constant.DuplicateReasonExact, Similarity: 1},
+				{RatingID: "3", DuplicateOf: "1", Reason: constant.DuplicateReasonNear, Similarity: 14.0 / 15},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := findDuplicates(tt.reviews); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("findDuplicates() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
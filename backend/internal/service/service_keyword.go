@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+
+	"skeleton-internship-backend/internal/job"
+	"skeleton-internship-backend/internal/model"
+
+	"github.com/rs/zerolog/log"
+)
+
+// refreshRestaurantKeywords rebuilds the keywords of every restaurant from its counted reviews
+func (s *service) refreshRestaurantKeywords(ctx context.Context) error {
+	log.Info().Msg("Refreshing restaurant keywords (service)")
+	restaurantIDs, _, _, err := s.repo.FindAllRestaurants()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to find restaurants to refresh keywords (service)")
+		return err
+	}
+
+	for i, id := range restaurantIDs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		job.ReportProgress(ctx, "keywords", i, len(restaurantIDs))
+
+		if err := s.refreshKeywords(id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// refreshKeywords rebuilds the keywords of one restaurant
+func (s *service) refreshKeywords(id string) error {
+	feedbacks, err := s.repo.FindLabeledFeedbackByRestaurantID(id)
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to find feedback of restaurant %s to extract keywords (service)", id)
+		return err
+	}
+	if err := s.repo.SaveRestaurantKeywords(id, extractKeywords(feedbacks)); err != nil {
+		log.Error().Err(err).Msgf("Failed to save keywords of restaurant %s (service)", id)
+		return err
+	}
+	return nil
+}
+
+func (s *service) GetRestaurantKeywords(id string) (*model.RestaurantKeywords, error) {
+	log.Info().Msgf("Fetching keywords for restaurant ID: %s", id)
+
+	// Check if restaurant exists
+	_, err := s.GetRestaurantByID(id, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	keywords, err := s.repo.FindKeywordsByRestaurantID(id)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get restaurant keywords (service)")
+		return nil, err
+	}
+	return keywords, nil
+}
@@ -7,34 +7,45 @@ import (
 )
 
 func (s *service) GetLabelsRating(id string) (*model.LabelsRating, error) {
-	ambienceRating, ambienceCount, deliveryRating, deliveryCount, foodRating, foodCount, priceRating, priceCount, serviceRating, serviceCount, err := s.repo.CalculateLabelsRating(id)
+	labelsRating, err := s.repo.FindLabelsRating(id)
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to calculate labels rating (service)")
+		log.Error().Err(err).Msg("Failed to find labels rating (service)")
 		return nil, err
 	}
 
-	labelsRating := &model.LabelsRating{
-		Ambience: model.LabelRating{
-			Rating: ambienceRating,
-			Count:  ambienceCount,
-		},
-		Delivery: model.LabelRating{
-			Rating: deliveryRating,
-			Count:  deliveryCount,
-		},
-		Food: model.LabelRating{
-			Rating: foodRating,
-			Count:  foodCount,
-		},
-		Price: model.LabelRating{
-			Rating: priceRating,
-			Count:  priceCount,
-		},
-		Service: model.LabelRating{
-			Rating: serviceRating,
-			Count:  serviceCount,
-		},
+	return labelsRating, nil
+}
+
+func (s *service) GetLabelsRatingByPlatform(id string) ([]model.PlatformLabelsRating, error) {
+	breakdown, err := s.repo.FindLabelsRatingByPlatform(id)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to find labels rating by platform (service)")
+		return nil, err
 	}
 
-	return labelsRating, nil
-}
\ No newline at end of file
+	return breakdown, nil
+}
+
+func (s *service) GetRestaurantLabels(id string, byPlatform bool) (*model.RestaurantLabels, error) {
+	log.Info().Msgf("Fetching label ratings for restaurant ID: %s (by platform: %v)", id, byPlatform)
+
+	// Check if restaurant exists
+	_, err := s.GetRestaurantByID(id, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	labelsRating, err := s.GetLabelsRating(id)
+	if err != nil {
+		return nil, err
+	}
+
+	labels := &model.RestaurantLabels{RestaurantID: id, Labels: *labelsRating}
+	if byPlatform {
+		labels.ByPlatform, err = s.GetLabelsRatingByPlatform(id)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return labels, nil
+}
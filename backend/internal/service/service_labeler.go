@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+
+	"skeleton-internship-backend/internal/constant"
+	"skeleton-internship-backend/internal/job"
+	"skeleton-internship-backend/internal/model"
+
+	"github.com/rs/zerolog/log"
+)
+
+// LabelUnlabeledReviews labels the reviews without Feedback_label rows in batches,
+// then refreshes the ratings so the new labels count
+func (s *service) LabelUnlabeledReviews(ctx context.Context) error {
+	log.Info().Msg("Labeling unlabeled reviews (service)")
+
+	total := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		reviews, err := s.repo.FindUnlabeledReviews(s.cfg.Labeler.BatchSize)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to find unlabeled reviews (service)")
+			return err
+		}
+		if len(reviews) == 0 {
+			break
+		}
+
+		labels, err := s.labeler.LabelReviews(ctx, reviews)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to label reviews (service)")
+			return err
+		}
+
+		// Every review of the batch gets at least one label so it is not picked up again
+		batch := make(map[string][]model.ReviewLabel, len(reviews))
+		for _, review := range reviews {
+			batch[review.RatingID] = validLabels(labels[review.RatingID])
+			if len(batch[review.RatingID]) == 0 {
+				batch[review.RatingID] = unknownLabels(review)
+			}
+		}
+
+		if err := s.repo.SaveReviewLabels(batch); err != nil {
+			log.Error().Err(err).Msg("Failed to save review labels (service)")
+			return err
+		}
+		total += len(reviews)
+		log.Info().Msgf("Labeled %d reviews so far (service)", total)
+		job.ReportProgress(ctx, constant.JobKindLabelReviews, total, 0)
+
+		if len(reviews) < s.cfg.Labeler.BatchSize {
+			break
+		}
+	}
+
+	if total == 0 {
+		log.Info().Msg("No reviews to label (service)")
+		return nil
+	}
+	return s.RecalculateDirtyRestaurants(ctx)
+}
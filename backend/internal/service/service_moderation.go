@@ -0,0 +1,52 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"skeleton-internship-backend/internal/constant"
+	"skeleton-internship-backend/internal/dto"
+	"skeleton-internship-backend/internal/model"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ReportReview records a reader's report on a review. Like votes, reporters are
+// identified by a hash of their identity and can report each review once.
+func (s *service) ReportReview(ratingID string, clientID string, report dto.ReviewReport) error {
+	reporterHash := sha256.Sum256([]byte(clientID))
+	err := s.repo.ReportReview(ratingID, hex.EncodeToString(reporterHash[:]), report.Reason, report.Details)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to report review (service)")
+		return err
+	}
+	return nil
+}
+
+func (s *service) GetModerationQueue(status string, page int) (*model.ModerationQueue, error) {
+	log.Info().Msgf("Fetching %s reviews for moderation on page: %d", status, page)
+	queue, err := s.repo.FindModerationQueue(status, page)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to fetch moderation queue (service)")
+		return nil, err
+	}
+	return queue, nil
+}
+
+// ApproveReview makes a review visible again and resolves its reports
+func (s *service) ApproveReview(ratingID string) error {
+	if err := s.repo.ModerateReview(ratingID, constant.ModerationVisible); err != nil {
+		log.Error().Err(err).Msg("Failed to approve review (service)")
+		return err
+	}
+	return nil
+}
+
+// HideReview hides a review from listings, review counts and ratings
+func (s *service) HideReview(ratingID string) error {
+	if err := s.repo.ModerateReview(ratingID, constant.ModerationHidden); err != nil {
+		log.Error().Err(err).Msg("Failed to hide review (service)")
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"math"
+
+	"skeleton-internship-backend/internal/constant"
+	"skeleton-internship-backend/internal/model"
+
+	"github.com/rs/zerolog/log"
+)
+
+// calibratePlatformRatings maps every rating of the platforms normalized by distribution to
+// the rating at the same percentile of our own ratings, so a platform rating nearly every
+// restaurant gets does not look like a great score next to ours
+func (s *service) calibratePlatformRatings(ctx context.Context) error {
+	scales, err := s.repo.FindPlatformScales()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to find platform scales (service)")
+		return err
+	}
+
+	own, err := s.repo.FindRestaurantRatingCounts()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to find restaurant rating counts (service)")
+		return err
+	}
+
+	for _, scale := range scales {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if scale.Normalization != constant.PlatformNormalizationDistribution {
+			continue
+		}
+
+		counts, err := s.repo.FindPlatformRatingCounts(scale.PlatformID)
+		if err != nil {
+			log.Error().Err(err).Msgf("Failed to find rating counts of platform %s (service)", scale.Name)
+			return err
+		}
+		if err := s.repo.SavePlatformCalibration(scale.PlatformID, calibrateRatings(counts, own)); err != nil {
+			log.Error().Err(err).Msgf("Failed to save calibration of platform %s (service)", scale.Name)
+			return err
+		}
+		log.Info().Msgf("Calibrated %d ratings of platform %s (service)", len(counts), scale.Name)
+	}
+
+	return nil
+}
+
+// calibrateRatings maps each platform rating to the rating at the same percentile of the
+// reference distribution. Both distributions must be sorted by rating. Percentiles are taken
+// at the middle of each rating's share and interpolated between reference ratings.
+func calibrateRatings(platform []model.RatingCount, reference []model.RatingCount) map[float64]float64 {
+	calibration := map[float64]float64{}
+	if len(reference) == 0 {
+		return calibration
+	}
+
+	referencePercentiles := midPercentiles(reference)
+	platformPercentiles := midPercentiles(platform)
+	for i, count := range platform {
+		p := platformPercentiles[i]
+		j := 0
+		for j < len(reference) && referencePercentiles[j] < p {
+			j++
+		}
+
+		var rating float64
+		switch {
+		case j == 0:
+			rating = reference[0].Rating
+		case j == len(reference):
+			rating = reference[len(reference)-1].Rating
+		default:
+			low, high := referencePercentiles[j-1], referencePercentiles[j]
+			share := (p - low) / (high - low)
+			rating = reference[j-1].Rating + share*(reference[j].Rating-reference[j-1].Rating)
+		}
+		calibration[count.Rating] = math.Round(rating*100) / 100
+	}
+
+	return calibration
+}
+
+// midPercentiles returns, for each rating of a sorted distribution, the share of
+// restaurants rated lower plus half of those with that rating
+func midPercentiles(counts []model.RatingCount) []float64 {
+	total := 0
+	for _, count := range counts {
+		total += count.Count
+	}
+
+	percentiles := make([]float64, len(counts))
+	below := 0
+	for i, count := range counts {
+		percentiles[i] = (float64(below) + float64(count.Count)/2) / float64(total)
+		below += count.Count
+	}
+	return percentiles
+}
+
+// platformDisagreement returns the range of the normalized platform ratings and our own
+// rating, as a share of the rating scale: 0 when they all agree, 1 when they are at both ends
+func platformDisagreement(restaurant *model.Restaurant, ratings []model.PlatformRating) float64 {
+	var normalized []float64
+	if restaurant.ReviewCount > 0 {
+		normalized = append(normalized, restaurant.Rating)
+	}
+	for _, rating := range ratings {
+		if rating.Normalization != constant.PlatformNormalizationNone {
+			normalized = append(normalized, rating.NormalizedRating)
+		}
+	}
+	if len(normalized) < 2 {
+		return 0
+	}
+
+	lowest, highest := normalized[0], normalized[0]
+	for _, rating := range normalized[1:] {
+		lowest = math.Min(lowest, rating)
+		highest = math.Max(highest, rating)
+	}
+	spread := (highest - lowest) / (constant.ReviewMaxRating - constant.ReviewMinRating)
+	return math.Round(spread*100) / 100
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"skeleton-internship-backend/internal/constant"
+	"skeleton-internship-backend/internal/model"
+)
+
+// counts builds a sorted distribution from rating and count pairs
+func counts(pairs ...float64) []model.RatingCount {
+	var distribution []model.RatingCount
+	for i := 0; i+1 < len(pairs); i += 2 {
+		distribution = append(distribution, model.RatingCount{Rating: pairs[i], Count: int(pairs[i+1])})
+	}
+	return distribution
+}
+
+func TestCalibrateRatings(t *testing.T) {
+	// One restaurant at each of 2, 3, 4 and 5: percentiles 0.125, 0.375, 0.625 and 0.875
+	reference := counts(2, 1, 3, 1, 4, 1, 5, 1)
+
+	tests := []struct {
+		name      string
+		platform  []model.RatingCount
+		reference []model.RatingCount
+		want      map[float64]float64
+	}{
+		{
+			name:      "same distribution maps to itself",
+			platform:  reference,
+			reference: reference,
+			want:      map[float64]float64{2: 2, 3: 3, 4: 4, 5: 5},
+		},
+		{
+			name:      "interpolated between reference ratings",
+			platform:  counts(1, 1, 5, 1),
+			reference: reference,
+			want:      map[float64]float64{1: 2.5, 5: 4.5},
+		},
+		{
+			name: "a rating most restaurants get is only average",
+			// Foody: whole stars, mostly 4
+			platform:  counts(3, 1, 4, 3),
+			reference: reference,
+			want:      map[float64]float64{3: 2, 4: 4},
+		},
+		{
+			name:      "beyond the reference ends",
+			platform:  counts(1, 9, 5, 1),
+			reference: reference,
+			want:      map[float64]float64{1: 3.3, 5: 5},
+		},
+		{
+			name:      "no reference",
+			platform:  counts(4, 1),
+			reference: nil,
+			want:      map[float64]float64{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := calibrateRatings(tt.platform, tt.reference); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("calibrateRatings() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMidPercentiles(t *testing.T) {
+	got := midPercentiles(counts(1, 2, 3, 4, 5, 2))
+	want := []float64{0.125, 0.5, 0.875}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("midPercentiles() = %v, want %v", got, want)
+	}
+}
+
+func TestPlatformDisagreement(t *testing.T) {
+	normalized := func(ratings ...float64) []model.PlatformRating {
+		var platformRatings []model.PlatformRating
+		for _, rating := range ratings {
+			platformRatings = append(platformRatings, model.PlatformRating{NormalizedRating: rating})
+		}
+		return platformRatings
+	}
+
+	tests := []struct {
+		name        string
+		rating      float64
+		reviewCount int
+		platforms   []model.PlatformRating
+		want        float64
+	}{
+		{name: "all agree", rating: 4, reviewCount: 10, platforms: normalized(4, 4), want: 0},
+		{name: "our rating counts", rating: 4, reviewCount: 10, platforms: normalized(2, 3.5), want: 0.5},
+		{name: "opposite ends", rating: 1, reviewCount: 3, platforms: normalized(5), want: 1},
+		{name: "our rating without reviews is left out", rating: 0, reviewCount: 0, platforms: normalized(3, 4), want: 0.25},
+		{name: "a single rating", rating: 0, reviewCount: 0, platforms: normalized(4.5), want: 0},
+		{name: "no platform", rating: 4, reviewCount: 10, want: 0},
+		{
+			name:        "ratings not normalized are left out",
+			rating:      4,
+			reviewCount: 10,
+			platforms: append(normalized(3), model.PlatformRating{
+				Rating:        9,
+				Normalization: constant.PlatformNormalizationNone,
+			}),
+			want: 0.25,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			restaurant := &model.Restaurant{Rating: tt.rating, ReviewCount: tt.reviewCount}
+			if got := platformDisagreement(restaurant, tt.platforms); got != tt.want {
+				t.Errorf("platformDisagreement() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalibratePlatformRatings(t *testing.T) {
+	repo := newFakeRepository()
+	repo.scales = []model.PlatformScale{
+		{PlatformID: 1, Name: "befood", ScaleMin: 1, ScaleMax: 5, Normalization: constant.PlatformNormalizationLinear},
+		{PlatformID: 2, Name: "foody", ScaleMin: 1, ScaleMax: 5, Normalization: constant.PlatformNormalizationDistribution},
+	}
+	repo.ownCounts = counts(2, 1, 3, 1, 4, 1, 5, 1)
+	repo.platformCounts[1] = counts(5, 1)
+	repo.platformCounts[2] = counts(3, 1, 4, 3)
+
+	s := newTestService(repo, nil)
+	if err := s.calibratePlatformRatings(context.Background()); err != nil {
+		t.Fatalf("calibratePlatformRatings() error = %v", err)
+	}
+
+	// Linear platforms are not calibrated
+	want := map[int]map[float64]float64{2: {3: 2, 4: 4}}
+	if !reflect.DeepEqual(repo.calibrations, want) {
+		t.Errorf("calibrations = %v, want %v", repo.calibrations, want)
+	}
+}
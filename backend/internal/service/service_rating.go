@@ -0,0 +1,78 @@
+package service
+
+import (
+	"skeleton-internship-backend/internal/model"
+
+	"github.com/rs/zerolog/log"
+)
+
+func (s *service) GetRatingDistribution(id string) (*model.RatingDistribution, error) {
+	log.Info().Msgf("Fetching rating distribution for restaurant ID: %s", id)
+
+	// Check if restaurant exists
+	_, err := s.GetRestaurantByID(id, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	distribution, err := s.repo.FindRatingDistribution(id)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get rating distribution (service)")
+		return nil, err
+	}
+	return distribution, nil
+}
+
+func (s *service) GetRatingTrend(id string, granularity string) (*model.RatingTrend, error) {
+	log.Info().Msgf("Fetching rating trend for restaurant ID: %s with granularity: %s", id, granularity)
+
+	// Check if restaurant exists
+	_, err := s.GetRestaurantByID(id, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	trend, err := s.repo.FindRatingTrend(id, granularity)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get rating trend (service)")
+		return nil, err
+	}
+	return trend, nil
+}
+
+func (s *service) GetRatingHistory(id string, page int) (*model.RatingHistory, error) {
+	log.Info().Msgf("Fetching rating history for restaurant ID: %s on page: %d", id, page)
+
+	// Check if restaurant exists
+	_, err := s.GetRestaurantByID(id, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	history, err := s.repo.FindRatingHistory(id, page)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get rating history (service)")
+		return nil, err
+	}
+	return history, nil
+}
+
+func (s *service) GetRatingMovers(fromVersion int, toVersion int, limit int) (*model.RatingMovers, error) {
+	log.Info().Msgf("Fetching rating movers from version %d to version %d", fromVersion, toVersion)
+	movers, err := s.repo.FindRatingMovers(fromVersion, toVersion, limit)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get rating movers (service)")
+		return nil, err
+	}
+	return movers, nil
+}
+
+func (s *service) GetRanking(cityID int, districtID int, foodType string, label string, limit int) (*model.Ranking, error) {
+	log.Info().Msgf("Fetching %s ranking of city %d, district %d, food type %q", label, cityID, districtID, foodType)
+	ranking, err := s.repo.FindRanking(cityID, districtID, foodType, label, limit)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get ranking (service)")
+		return nil, err
+	}
+	return ranking, nil
+}
@@ -1,30 +1,126 @@
 package service
 
 import (
+	"context"
 	"os"
 	"strconv"
 
+	"skeleton-internship-backend/internal/constant"
+	"skeleton-internship-backend/internal/job"
+	"skeleton-internship-backend/internal/model"
+
 	"github.com/rs/zerolog/log"
 )
 
-func (s *service) RecalculateRestaurantsRating() error {
-	log.Info().Msg("Recalculating restaurants rating (service) with parallel processing")
-	err := s.repo.RecalculateCountReviews()
+// recalculationSteps is the number of steps of a full recalculation, for job progress
+const recalculationSteps = 4
+
+// RecalculateRestaurantsRating recalculates every restaurant. Review counts, ratings and label
+// stats are computed aside and swapped in together, so readers never see a half-updated table.
+func (s *service) RecalculateRestaurantsRating(ctx context.Context) error {
+	s.recalculating.Lock()
+	defer s.recalculating.Unlock()
+
+	log.Info().Msg("Recalculating restaurants rating (service)")
+	job.ReportProgress(ctx, constant.JobKindRecalculate, 0, recalculationSteps)
+
+	version, err := s.repo.RecalculateRestaurants()
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to recalculate count reviews (service)")
+		log.Error().Err(err).Msg("Failed to recalculate review counts and ratings (service)")
+		return err
+	}
+
+	job.ReportProgress(ctx, constant.JobKindRecalculate, 1, recalculationSteps)
+	if err := s.finishRecalculation(ctx, version); err != nil {
+		return err
+	}
+
+	job.ReportProgress(ctx, constant.JobKindRecalculate, recalculationSteps, recalculationSteps)
+	log.Info().Msgf("Recalculation version %d of restaurant ratings completed successfully (service)", version)
+	return nil
+}
+
+// finishRecalculation refreshes what is derived from the ratings once a recalculation
+// version is swapped in, by a full recalculation or an applied dry run: the rankings,
+// the calibration of the platforms on our ratings and the keywords
+func (s *service) finishRecalculation(ctx context.Context, version int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := s.repo.RefreshRankings(version); err != nil {
+		log.Error().Err(err).Msg("Failed to refresh rankings (service)")
+		return err
+	}
+
+	job.ReportProgress(ctx, constant.JobKindRecalculate, 2, recalculationSteps)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	// Calibration follows our new ratings
+	if err := s.calibratePlatformRatings(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to calibrate platform ratings (service)")
 		return err
 	}
 
-	err = s.repo.RecalculateAverageRating()
+	job.ReportProgress(ctx, constant.JobKindRecalculate, 3, recalculationSteps)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if err := s.refreshRestaurantKeywords(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to refresh restaurant keywords (service)")
+		return err
+	}
+	return nil
+}
+
+// RecalculateRestaurant recalculates the review count, label stats, rating and keywords of one restaurant
+func (s *service) RecalculateRestaurant(id string) (*model.Restaurant, error) {
+	log.Info().Msgf("Recalculating restaurant %s (service)", id)
+	if err := s.repo.RecalculateRestaurant(id); err != nil {
+		log.Error().Err(err).Msgf("Failed to recalculate restaurant %s (service)", id)
+		return nil, err
+	}
+	if err := s.refreshKeywords(id); err != nil {
+		return nil, err
+	}
+	return s.GetRestaurantByID(id, 0, 0)
+}
+
+// RecalculateDirtyRestaurants recalculates only the restaurants whose reviews changed
+// since their last recalculation
+func (s *service) RecalculateDirtyRestaurants(ctx context.Context) error {
+	s.recalculating.Lock()
+	defer s.recalculating.Unlock()
+
+	ids, err := s.repo.FindDirtyRestaurants()
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to recalculate average rating (service)")
+		log.Error().Err(err).Msg("Failed to find dirty restaurants (service)")
 		return err
 	}
-	log.Info().Msg("Recalculation of restaurant ratings completed successfully (service)")
+	log.Info().Msgf("Recalculating %d dirty restaurants (service)", len(ids))
+
+	for i, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		job.ReportProgress(ctx, constant.JobKindRecalculateDirty, i, len(ids))
+
+		if _, err := s.RecalculateRestaurant(id); err != nil {
+			// A restaurant deleted in the meantime has no mark left to clear
+			if err.Error() == "not found" {
+				continue
+			}
+			return err
+		}
+	}
+
+	job.ReportProgress(ctx, constant.JobKindRecalculateDirty, len(ids), len(ids))
 	return nil
 }
 
-func (s *service) ExportRestaurantsToCSV() error {
+func (s *service) ExportRestaurantsToCSV(ctx context.Context) error {
 	log.Info().Msg("Exporting restaurants to CSV (service)")
 	restaurantID, ratings, reviewCounts, err := s.repo.FindAllRestaurants()
 	if err != nil {
@@ -40,6 +136,10 @@ func (s *service) ExportRestaurantsToCSV() error {
 		csvData = append(csvData, row...)
 	}
 
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	// Create a CSV file
 	filename := "/tmp/restaurant_ratings.csv"
 	err = os.WriteFile(filename, csvData, 0644)
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"skeleton-internship-backend/internal/dto"
+	"skeleton-internship-backend/internal/model"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+func (s *service) CreateReview(restaurantID string, review dto.ReviewCreate) (*model.Review, error) {
+	log.Info().Msgf("Creating review for restaurant ID: %s by user ID: %s", restaurantID, review.UserID)
+
+	labels := make([]model.ReviewLabel, 0, len(review.Labels))
+	for _, label := range review.Labels {
+		labels = append(labels, model.ReviewLabel{
+			Label:       strings.ToLower(label.Label),
+			RatingLabel: label.RatingLabel,
+		})
+	}
+
+	created, err := s.repo.CreateReview(restaurantID, review.UserID, review.Rating, strings.TrimSpace(review.Feedback), labels)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to create review (service)")
+		return nil, err
+	}
+
+	return created, nil
+}
+
+// VoteReview records whether a client found a review helpful. Clients are identified
+// by a hash of their identity so raw IPs and client IDs are never stored.
+// A nil helpful removes the client's vote.
+func (s *service) VoteReview(ratingID string, clientID string, helpful *bool) (*model.ReviewVotes, error) {
+	voterHash := sha256.Sum256([]byte(clientID))
+	votes, err := s.repo.SaveReviewVote(ratingID, hex.EncodeToString(voterHash[:]), helpful)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to save review vote (service)")
+		return nil, err
+	}
+	return votes, nil
+}
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"math"
+	"strings"
+	"time"
+
+	"skeleton-internship-backend/internal/constant"
+	"skeleton-internship-backend/internal/job"
+	"skeleton-internship-backend/internal/model"
+
+	"github.com/rs/zerolog/log"
+)
+
+// DetectSuspiciousReviews looks for review bursts on every restaurant, replaces the
+// previously flagged windows and refreshes the ratings of the affected restaurants
+func (s *service) DetectSuspiciousReviews(ctx context.Context) error {
+	log.Info().Msg("Detecting suspicious reviews (service)")
+	restaurantIDs, _, _, err := s.repo.FindAllRestaurants()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to find restaurants to detect suspicious reviews (service)")
+		return err
+	}
+
+	var windows []model.SuspiciousWindow
+	for i, id := range restaurantIDs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		job.ReportProgress(ctx, constant.JobKindDetectSuspicious, i, len(restaurantIDs))
+
+		reviews, err := s.repo.FindReviewActivityByRestaurantID(id)
+		if err != nil {
+			log.Error().Err(err).Msgf("Failed to find reviews of restaurant %s to detect suspicious reviews (service)", id)
+			return err
+		}
+		windows = append(windows, findSuspiciousWindows(id, reviews)...)
+	}
+
+	if err := s.repo.SaveSuspiciousWindows(windows); err != nil {
+		log.Error().Err(err).Msg("Failed to save suspicious windows (service)")
+		return err
+	}
+	log.Info().Msgf("Found %d suspicious windows (service)", len(windows))
+
+	return s.RecalculateDirtyRestaurants(ctx)
+}
+
+// dayActivity holds the reviews of one restaurant on one day
+type dayActivity struct {
+	day     time.Time
+	reviews []model.ReviewActivity
+	sum     float64
+}
+
+// findSuspiciousWindows detects bursts in the reviews of one restaurant, which must be
+// sorted from oldest to newest. A day is suspicious when its volume and average rating
+// are far from the rest of the restaurant's history; consecutive suspicious days in the
+// same direction are merged into one window.
+func findSuspiciousWindows(restaurantID string, reviews []model.ReviewActivity) []model.SuspiciousWindow {
+	if len(reviews) < constant.SuspiciousMinBaselineReviews+constant.SuspiciousMinBurstReviews {
+		return nil
+	}
+
+	var days []*dayActivity
+	var total float64
+	for _, review := range reviews {
+		day := time.Date(review.ReviewTime.Year(), review.ReviewTime.Month(), review.ReviewTime.Day(), 0, 0, 0, 0, time.UTC)
+		if len(days) == 0 || !days[len(days)-1].day.Equal(day) {
+			days = append(days, &dayActivity{day: day})
+		}
+		current := days[len(days)-1]
+		current.reviews = append(current.reviews, review)
+		current.sum += review.Rating
+		total += review.Rating
+	}
+	spanDays := days[len(days)-1].day.Sub(days[0].day).Hours()/24 + 1
+
+	// direction of each day: 1 for a positive burst, -1 for a negative burst, 0 otherwise
+	directions := make([]int, len(days))
+	for i, day := range days {
+		count := len(day.reviews)
+		baselineCount := len(reviews) - count
+		if count < constant.SuspiciousMinBurstReviews || baselineCount < constant.SuspiciousMinBaselineReviews {
+			continue
+		}
+		baselineRating := (total - day.sum) / float64(baselineCount)
+		baselineDaily := float64(baselineCount) / spanDays
+		deviation := day.sum/float64(count) - baselineRating
+		if float64(count) >= constant.SuspiciousVelocityFactor*baselineDaily && math.Abs(deviation) >= constant.SuspiciousRatingDeviation {
+			if deviation > 0 {
+				directions[i] = 1
+			} else {
+				directions[i] = -1
+			}
+		}
+	}
+
+	var windows []model.SuspiciousWindow
+	for start := 0; start < len(days); start++ {
+		if directions[start] == 0 {
+			continue
+		}
+		end := start
+		for end+1 < len(days) && directions[end+1] == directions[start] && days[end+1].day.Sub(days[end].day) == 24*time.Hour {
+			end++
+		}
+		windows = append(windows, suspiciousWindow(restaurantID, days[start:end+1], directions[start], len(reviews), total, spanDays))
+		start = end
+	}
+
+	return windows
+}
+
+// suspiciousWindow builds a window from consecutive burst days and flags its reviews
+// that push the rating in the direction of the burst
+func suspiciousWindow(restaurantID string, days []*dayActivity, direction int, totalCount int, totalSum float64, spanDays float64) model.SuspiciousWindow {
+	var count int
+	var sum float64
+	for _, day := range days {
+		count += len(day.reviews)
+		sum += day.sum
+	}
+	baselineCount := totalCount - count
+	baselineRating := (totalSum - sum) / float64(baselineCount)
+
+	window := model.SuspiciousWindow{
+		RestaurantID:         restaurantID,
+		WindowStart:          days[0].day.Format("2006-01-02"),
+		WindowEnd:            days[len(days)-1].day.Format("2006-01-02"),
+		Direction:            constant.SuspiciousDirectionPositive,
+		ReviewCount:          count,
+		AverageRating:        sum / float64(count),
+		BaselineRating:       baselineRating,
+		BaselineDailyReviews: float64(baselineCount) / spanDays,
+		FlaggedReviews:       []model.ReviewFlag{},
+	}
+	if direction < 0 {
+		window.Direction = constant.SuspiciousDirectionNegative
+	}
+
+	for _, day := range days {
+		for _, review := range day.reviews {
+			if (review.Rating-baselineRating)*float64(direction) < constant.SuspiciousRatingDeviation {
+				continue
+			}
+
+			score := 0.5
+			reasons := []string{constant.SuspiciousReasonBurst}
+			if review.ReviewerReviewCount <= 1 {
+				score += 0.25
+				reasons = append(reasons, constant.SuspiciousReasonNewReviewer)
+			} else if review.ReviewerAverageRating >= constant.ReviewMaxRating-constant.SuspiciousOneSidedMargin ||
+				review.ReviewerAverageRating <= constant.ReviewMinRating+constant.SuspiciousOneSidedMargin {
+				score += 0.25
+				reasons = append(reasons, constant.SuspiciousReasonOneSidedReviewer)
+			}
+
+			window.FlaggedReviews = append(window.FlaggedReviews, model.ReviewFlag{
+				RatingID: review.RatingID,
+				Score:    score,
+				Reasons:  strings.Join(reasons, ","),
+			})
+		}
+	}
+
+	return window
+}
+
+func (s *service) GetSuspiciousWindows(restaurantID string, page int) (*model.SuspiciousReport, error) {
+	log.Info().Msgf("Fetching suspicious windows for restaurant ID: %q on page: %d", restaurantID, page)
+	report, err := s.repo.FindSuspiciousWindows(restaurantID, page)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to fetch suspicious windows (service)")
+		return nil, err
+	}
+	return report, nil
+}
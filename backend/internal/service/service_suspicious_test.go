@@ -0,0 +1,174 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"skeleton-internship-backend/internal/constant"
+	"skeleton-internship-backend/internal/model"
+)
+
+var suspiciousStart = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// activity returns count reviews with the given rating on the given day after suspiciousStart,
+// written by reviewers with a history of 10 reviews averaging 3.5 stars
+func activity(day int, count int, rating float64) []model.ReviewActivity {
+	reviews := make([]model.ReviewActivity, count)
+	for i := range reviews {
+		reviews[i] = model.ReviewActivity{
+			RatingID:              fmt.Sprintf("d%d-%d", day, i),
+			Rating:                rating,
+			ReviewTime:            suspiciousStart.AddDate(0, 0, day).Add(time.Duration(i) * time.Minute),
+			ReviewerReviewCount:   10,
+			ReviewerAverageRating: 3.5,
+		}
+	}
+	return reviews
+}
+
+// baseline returns one 3 star review a day for the given number of days
+func baseline(days int) []model.ReviewActivity {
+	var reviews []model.ReviewActivity
+	for day := 0; day < days; day++ {
+		reviews = append(reviews, activity(day, 1, 3)...)
+	}
+	return reviews
+}
+
+func concat(parts ...[]model.ReviewActivity) []model.ReviewActivity {
+	var reviews []model.ReviewActivity
+	for _, part := range parts {
+		reviews = append(reviews, part...)
+	}
+	return reviews
+}
+
+func TestFindSuspiciousWindows(t *testing.T) {
+	type window struct {
+		start, end, direction string
+		reviews, flagged      int
+	}
+
+	tests := []struct {
+		name    string
+		reviews []model.ReviewActivity
+		want    []window
+	}{
+		{
+			name:    "too few reviews",
+			reviews: activity(0, 5, 5),
+		},
+		{
+			name:    "baseline too small",
+			reviews: concat(baseline(9), activity(30, 6, 5)),
+		},
+		{
+			name:    "positive burst",
+			reviews: concat(baseline(20), activity(30, 6, 5)),
+			want:    []window{{"2024-01-31", "2024-01-31", constant.SuspiciousDirectionPositive, 6, 6}},
+		},
+		{
+			name:    "negative burst",
+			reviews: concat(baseline(20), activity(30, 6, 1)),
+			want:    []window{{"2024-01-31", "2024-01-31", constant.SuspiciousDirectionNegative, 6, 6}},
+		},
+		{
+			name:    "too few reviews in a day",
+			reviews: concat(baseline(20), activity(30, 4, 5)),
+		},
+		{
+			name:    "rating close to the baseline",
+			reviews: concat(baseline(20), activity(30, 6, 4)),
+		},
+		{
+			name: "volume close to the usual",
+			reviews: concat(
+				activity(0, 4, 3), activity(1, 4, 3), activity(2, 4, 3), activity(3, 4, 3), activity(4, 4, 3),
+				activity(5, 4, 3), activity(6, 4, 3), activity(7, 4, 3), activity(8, 4, 3), activity(9, 4, 3),
+				activity(10, 6, 5),
+			),
+		},
+		{
+			name:    "consecutive days are merged",
+			reviews: concat(baseline(20), activity(30, 6, 5), activity(31, 6, 5)),
+			want:    []window{{"2024-01-31", "2024-02-01", constant.SuspiciousDirectionPositive, 12, 12}},
+		},
+		{
+			name:    "days apart are separate windows",
+			reviews: concat(baseline(20), activity(30, 6, 5), activity(32, 6, 5)),
+			want: []window{
+				{"2024-01-31", "2024-01-31", constant.SuspiciousDirectionPositive, 6, 6},
+				{"2024-02-02", "2024-02-02", constant.SuspiciousDirectionPositive, 6, 6},
+			},
+		},
+		{
+			name:    "opposite directions are not merged",
+			reviews: concat(baseline(20), activity(30, 6, 5), activity(31, 6, 1)),
+			want: []window{
+				{"2024-01-31", "2024-01-31", constant.SuspiciousDirectionPositive, 6, 6},
+				{"2024-02-01", "2024-02-01", constant.SuspiciousDirectionNegative, 6, 6},
+			},
+		},
+		{
+			name: "reviews against the burst are not flagged",
+			reviews: concat(baseline(20), activity(30, 5, 5), []model.ReviewActivity{{
+				RatingID: "against", Rating: 3, ReviewTime: suspiciousStart.AddDate(0, 0, 30).Add(time.Hour), ReviewerReviewCount: 10, ReviewerAverageRating: 3.5,
+			}}),
+			want: []window{{"2024-01-31", "2024-01-31", constant.SuspiciousDirectionPositive, 6, 5}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []window
+			for _, w := range findSuspiciousWindows("R", tt.reviews) {
+				if w.RestaurantID != "R" {
+					t.Errorf("window restaurant = %q, want R", w.RestaurantID)
+				}
+				got = append(got, window{w.WindowStart, w.WindowEnd, w.Direction, w.ReviewCount, len(w.FlaggedReviews)})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("findSuspiciousWindows() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindSuspiciousWindowsScoring(t *testing.T) {
+	burst := activity(30, 6, 5)
+	burst[0].ReviewerReviewCount = 1
+	burst[1].ReviewerAverageRating = 4.9
+	burst[2].ReviewerAverageRating = 1.1
+	// A new reviewer is not also counted as one-sided
+	burst[3].ReviewerReviewCount = 1
+	burst[3].ReviewerAverageRating = 5
+
+	windows := findSuspiciousWindows("R", concat(baseline(20), burst))
+	if len(windows) != 1 {
+		t.Fatalf("findSuspiciousWindows() returned %d windows, want 1", len(windows))
+	}
+	w := windows[0]
+
+	if w.BaselineRating != 3 || w.AverageRating != 5 {
+		t.Errorf("ratings = %v in the window against %v, want 5 against 3", w.AverageRating, w.BaselineRating)
+	}
+	// 20 baseline reviews over the 31 days from the first review to the burst
+	if math.Abs(w.BaselineDailyReviews-20.0/31) > 1e-9 {
+		t.Errorf("baseline daily reviews = %v, want %v", w.BaselineDailyReviews, 20.0/31)
+	}
+
+	want := []model.ReviewFlag{
+		{RatingID: "d30-0", Score: 0.75, Reasons: "burst,new_reviewer"},
+		{RatingID: "d30-1", Score: 0.75, Reasons: "burst,one_sided_reviewer"},
+		{RatingID: "d30-2", Score: 0.75, Reasons: "burst,one_sided_reviewer"},
+		{RatingID: "d30-3", Score: 0.75, Reasons: "burst,new_reviewer"},
+		{RatingID: "d30-4", Score: 0.5, Reasons: "burst"},
+		{RatingID: "d30-5", Score: 0.5, Reasons: "burst"},
+	}
+	if !reflect.DeepEqual(w.FlaggedReviews, want) {
+		t.Errorf("flagged reviews = %+v, want %+v", w.FlaggedReviews, want)
+	}
+}
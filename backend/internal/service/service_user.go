@@ -0,0 +1,37 @@
+package service
+
+import (
+	"skeleton-internship-backend/internal/model"
+
+	"github.com/rs/zerolog/log"
+)
+
+func (s *service) GetUserProfile(id string) (*model.UserProfile, error) {
+	user, err := s.repo.FindUserByID(id)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get user by ID (service)")
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *service) GetUserReviews(id string, page int, isCount bool) (*model.UserReviewResponse, error) {
+	log.Info().Msgf("Fetching reviews for user ID: %s on page: %d, isCount: %v", id, page, isCount)
+
+	// Check if user exists
+	_, err := s.repo.FindUserByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	reviews, totalReviews, err := s.repo.FindReviewsByUserID(id, page, isCount)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to fetch reviews by user ID (service)")
+		return nil, err
+	}
+
+	return &model.UserReviewResponse{
+		Reviews:      reviews,
+		TotalReviews: totalReviews,
+	}, nil
+}
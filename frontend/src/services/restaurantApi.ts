@@ -11,6 +11,7 @@ import type {
   RestaurantReview,
   RestaurantReviews,
   MenuItem,
+  MenuResponse,
 } from "../types/restaurant";
 
 const api = axios.create({
@@ -112,9 +113,9 @@ export const restaurantApi = {
   },
 
   getRestaurantMenu: async (id: string): Promise<MenuItem[]> => {
-    const response = await api.get<{ data: MenuItem[] }>(
+    const response = await api.get<{ data: MenuResponse }>(
       `/restaurants/${id}/menu`,
     );
-    return response.data.data;
+    return response.data.data.categories.flatMap((category) => category.dishes);
   },
 };
@@ -125,4 +125,19 @@ export interface RestaurantReviewsParams {
 export interface MenuItem {
   name: string;
   price: number;
+  category_id: number;
+  category_name: string;
+}
+
+export interface MenuCategory {
+  category_id: number;
+  category_name: string;
+  min_price: number;
+  max_price: number;
+  dishes: MenuItem[];
+}
+
+export interface MenuResponse {
+  categories: MenuCategory[];
+  total_dishes: number;
 }